@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/grovetools/core/pkg/paths"
+)
+
+// cachePath is where the named grove-nvim cache file lives; empty disables
+// caching.
+func cachePath(name string) string {
+	dir := paths.CacheDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "nvim", name)
+}
+
+// readCache decodes the JSON cache at path into v. A missing or corrupt
+// cache is not an error — it just reports false.
+func readCache(path string, v any) bool {
+	if path == "" {
+		return false
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path is derived from the cache dir
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// writeCache replaces the JSON cache at path, so a reader never sees a
+// half-written file.
+func writeCache(path string, v any) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// fetchedCache is a cache that records when its content was fetched; a
+// zero time means there is none.
+type fetchedCache interface {
+	fetched() time.Time
+}
+
+// loadFetchedCache serves the cache at path while it is younger than ttl,
+// and otherwise calls fetch and caches what it returns. When fetch fails a
+// stale cache is better than none.
+func loadFetchedCache[T fetchedCache](path string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+	var cached T
+	ok := readCache(path, &cached) && !cached.fetched().IsZero()
+	if ok && time.Since(cached.fetched()) < ttl {
+		return cached, nil
+	}
+	fresh, err := fetch()
+	if err != nil {
+		if ok {
+			ulog.Debug("Serving stale cache").Field("path", path).Err(err).Emit()
+			return cached, nil
+		}
+		return fresh, err
+	}
+	if err := writeCache(path, fresh); err != nil {
+		ulog.Debug("Failed to write cache").Field("path", path).Err(err).Emit()
+	}
+	return fresh, nil
+}
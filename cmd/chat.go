@@ -18,69 +18,100 @@ import (
 var chatLog = logging.NewUnifiedLogger("grove-nvim.chat")
 
 func newChatCmd() *cobra.Command {
+	var force bool
+
 	cmd := &cobra.Command{
 		Use:   "chat [file_path]",
 		Short: "Run 'flow run' on the specified file",
-		Long:  "A helper command for the Neovim plugin to execute 'flow run' on the currently open note.",
-		Args:  cobra.ExactArgs(1),
+		Long: `A helper command for the Neovim plugin to execute 'flow run' on the currently open note.
+
+Before submitting, the prompt (the job file plus the files its rules put in
+context) is estimated against the model's context window. An oversized
+prompt is refused with a JSON breakdown of the biggest contributors on
+stdout; --force submits it anyway with a warning.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
-			filePath := args[0]
-
-			chatLog.Debug("Starting chat run").
-				Field("file_path", filePath).
-				Log(ctx)
-
-			// Try to submit via daemon first. The daemon path is fire-and-forget.
-			// This is ideal for the Neovim silent mode.
-			if err := submitViaDaemon(ctx, filePath); err != nil {
-				chatLog.Debug("Daemon submission failed, falling back to flow run").
-					Err(err).
-					Log(ctx)
-			} else {
-				return nil
-			}
-
-			// Fallback: run via flow CLI subprocess
-			if _, err := exec.LookPath("flow"); err != nil {
-				chatLog.Error("'flow' command not found in PATH").
-					Err(err).
-					Log(ctx)
-				return fmt.Errorf("'flow' command not found in PATH. Please ensure the grove-flow binary is installed and accessible")
-			}
-
-			// #nosec G204 -- filePath comes from validated user input
-			flowCmd := delegation.Command("flow", "run", filePath)
-
-			chatLog.Debug("Executing flow run").
-				Field("command", "flow").
-				Field("file_path", filePath).
-				Log(ctx)
-
-			flowCmd.Stdout = os.Stdout
-			flowCmd.Stderr = os.Stderr
-			flowCmd.Stdin = os.Stdin
-
-			err := flowCmd.Run()
-			if err != nil {
-				chatLog.Error("grove flow run command failed").
-					Err(err).
-					Field("file_path", filePath).
-					Log(ctx)
-				return fmt.Errorf("flow command failed: %w", err)
-			}
-
-			chatLog.Debug("Chat run completed successfully").
-				Field("file_path", filePath).
-				Log(ctx)
-
-			return nil
+			return runChat(context.Background(), args[0], force)
 		},
 	}
 
+	cmd.Flags().BoolVar(&force, "force", false, "Submit even when the prompt is estimated to exceed the model's context window")
+
+	cmd.AddCommand(newChatUsageCmd())
+	cmd.AddCommand(newChatForkCmd())
+	cmd.AddCommand(newChatRegenerateCmd())
+	cmd.AddCommand(newChatExportCmd())
+	cmd.AddCommand(newChatCompactCmd())
+	cmd.AddCommand(newChatExtractCmd())
+
 	return cmd
 }
 
+// runChat submits a chat job: the token budget check, then the daemon, then
+// `flow run` as a fallback when the daemon cannot take it.
+func runChat(ctx context.Context, filePath string, force bool) error {
+	return runChatInPlan(ctx, "", filePath, force)
+}
+
+// runChatInPlan is runChat for a job file that does not sit directly in its
+// plan directory; the daemon is told planDir explicitly. The flow run
+// fallback cannot be, and runs the file on its own. An empty planDir is the
+// job file's directory.
+func runChatInPlan(ctx context.Context, planDir, filePath string, force bool) error {
+	chatLog.Debug("Starting chat run").
+		Field("file_path", filePath).
+		Log(ctx)
+
+	if err := checkChatBudget(filePath, force); err != nil {
+		return err
+	}
+
+	// Try to submit via daemon first. The daemon path is fire-and-forget.
+	// This is ideal for the Neovim silent mode.
+	if err := submitViaDaemon(ctx, planDir, filePath); err != nil {
+		chatLog.Debug("Daemon submission failed, falling back to flow run").
+			Err(err).
+			Log(ctx)
+	} else {
+		return nil
+	}
+
+	// Fallback: run via flow CLI subprocess
+	if _, err := exec.LookPath("flow"); err != nil {
+		chatLog.Error("'flow' command not found in PATH").
+			Err(err).
+			Log(ctx)
+		return fmt.Errorf("'flow' command not found in PATH. Please ensure the grove-flow binary is installed and accessible")
+	}
+
+	// #nosec G204 -- filePath comes from validated user input
+	flowCmd := delegation.Command("flow", "run", filePath)
+
+	chatLog.Debug("Executing flow run").
+		Field("command", "flow").
+		Field("file_path", filePath).
+		Log(ctx)
+
+	flowCmd.Stdout = os.Stdout
+	flowCmd.Stderr = os.Stderr
+	flowCmd.Stdin = os.Stdin
+
+	err := flowCmd.Run()
+	if err != nil {
+		chatLog.Error("grove flow run command failed").
+			Err(err).
+			Field("file_path", filePath).
+			Log(ctx)
+		return fmt.Errorf("flow command failed: %w", err)
+	}
+
+	chatLog.Debug("Chat run completed successfully").
+		Field("file_path", filePath).
+		Log(ctx)
+
+	return nil
+}
+
 // chatSubmitRequest builds the daemon submission for a chat run. It is split out
 // from submitViaDaemon so the request's routing is unit-testable without a live
 // daemon.
@@ -90,7 +121,7 @@ func newChatCmd() *cobra.Command {
 // is failed outright by the executor rather than guessed at. `chat` takes
 // whatever note the Neovim plugin has open — nothing restricts that to chat
 // jobs, so an interactive_agent job reaches this path too. The `flow run`
-// fallback in newChatCmd does not cover it either: that only fires when the
+// fallback in runChat does not cover it either: that only fires when the
 // submission itself errors, and a job that queues successfully and then dies in
 // the executor never gets there.
 func chatSubmitRequest(planDir, jobFile string) models.JobSubmitRequest {
@@ -101,9 +132,10 @@ func chatSubmitRequest(planDir, jobFile string) models.JobSubmitRequest {
 	}
 }
 
-// submitViaDaemon submits a job to the grove daemon's job runner.
+// submitViaDaemon submits a job to the grove daemon's job runner, the job
+// file given relative to planDir (the file's directory when empty).
 // The daemon handles execution in the background — this returns immediately.
-func submitViaDaemon(ctx context.Context, filePath string) error {
+func submitViaDaemon(ctx context.Context, planDir, filePath string) error {
 	client := daemon.New()
 	defer func() { _ = client.Close() }()
 
@@ -116,8 +148,13 @@ func submitViaDaemon(ctx context.Context, filePath string) error {
 		return fmt.Errorf("resolve path: %w", err)
 	}
 
-	planDir := filepath.Dir(absPath)
-	jobFile := filepath.Base(absPath)
+	if planDir == "" {
+		planDir = filepath.Dir(absPath)
+	}
+	jobFile, err := filepath.Rel(planDir, absPath)
+	if err != nil {
+		return fmt.Errorf("resolve job file: %w", err)
+	}
 
 	info, err := client.SubmitJob(ctx, chatSubmitRequest(planDir, jobFile))
 	if err != nil {
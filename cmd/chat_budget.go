@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/grovetools/core/config"
+	"gopkg.in/yaml.v3"
+)
+
+// chatFrontmatter is the slice of a job file's frontmatter the budget guard
+// reads.
+type chatFrontmatter struct {
+	Model     string `yaml:"model"`
+	RulesFile string `yaml:"rules_file"`
+}
+
+// planConfig is the slice of a plan's .grove-plan.yml the guard reads.
+type planConfig struct {
+	Model string `yaml:"model"`
+}
+
+// flowConfig is the slice of the `flow` config section the guard reads.
+type flowConfig struct {
+	OneshotModel string `yaml:"oneshot_model"`
+}
+
+// splitFrontmatter separates a leading "---" YAML block from the body. ok is
+// false when the file has no frontmatter.
+func splitFrontmatter(content []byte) (front, body []byte, ok bool) {
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+	if !bytes.HasPrefix(content, []byte("---\n")) && !bytes.HasPrefix(content, []byte("---\r\n")) {
+		return nil, content, false
+	}
+	rest := content[bytes.IndexByte(content, '\n')+1:]
+	for offset := 0; offset < len(rest); {
+		end := bytes.IndexByte(rest[offset:], '\n')
+		line := rest[offset:]
+		if end >= 0 {
+			line = rest[offset : offset+end]
+		}
+		if string(bytes.TrimRight(line, "\r")) == "---" {
+			if end < 0 {
+				return rest[:offset], nil, true
+			}
+			return rest[:offset], rest[offset+end+1:], true
+		}
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+	return nil, content, false
+}
+
+// contextWindow returns the context window for a model, and false when the
+// model is unknown.
+func contextWindow(model string) (int, bool) {
+	info, ok := lookupModel(model)
+	if !ok || info.ContextWindow == 0 {
+		return 0, false
+	}
+	return info.ContextWindow, true
+}
+
+// resolveChatModel picks the model a job will run with: its own frontmatter,
+// then the plan's .grove-plan.yml, then flow.oneshot_model in the grove
+// config. source names where the model came from.
+func resolveChatModel(jobFile string, front chatFrontmatter) (model, source string) {
+	if front.Model != "" {
+		return front.Model, "frontmatter"
+	}
+	planDir := filepath.Dir(jobFile)
+	if data, err := os.ReadFile(filepath.Join(planDir, ".grove-plan.yml")); err == nil { //nolint:gosec // plan config next to the job
+		var pc planConfig
+		if yaml.Unmarshal(data, &pc) == nil && pc.Model != "" {
+			return pc.Model, "plan"
+		}
+	}
+	if cfg, err := config.LoadFrom(planDir); err == nil && cfg != nil {
+		var fc flowConfig
+		if cfg.UnmarshalExtension("flow", &fc) == nil && fc.OneshotModel != "" {
+			return fc.OneshotModel, "config"
+		}
+	}
+	return "", ""
+}
+
+// promptContributor is one source of prompt tokens.
+type promptContributor struct {
+	Kind   string `json:"kind"` // "chat" or "context"
+	Path   string `json:"path"`
+	Tokens int    `json:"tokens"`
+}
+
+// promptEstimate is the expected size of a chat submission.
+type promptEstimate struct {
+	Model         string
+	ModelSource   string
+	ContextWindow int
+	RulesFile     string
+	ChatTokens    int
+	ContextTokens int
+	Contributors  []promptContributor // largest first
+}
+
+// Total is the estimated prompt size in tokens.
+func (e *promptEstimate) Total() int {
+	return e.ChatTokens + e.ContextTokens
+}
+
+// Exceeded reports whether the estimate is over a known context window.
+func (e *promptEstimate) Exceeded() bool {
+	return e.ContextWindow > 0 && e.Total() > e.ContextWindow
+}
+
+// estimatePrompt sizes a chat submission: the job file itself plus every
+// file its rules put in context. The rules file is the job's rules_file, or
+// the nearest .grove/rules above the job file when it has none — never
+// relative to cwd, which for Neovim is wherever the editor was started.
+func estimatePrompt(jobFile string, cache *tokenCache) (*promptEstimate, error) {
+	content, err := os.ReadFile(jobFile) //nolint:gosec // user-specified job file
+	if err != nil {
+		return nil, fmt.Errorf("read job file: %w", err)
+	}
+
+	var front chatFrontmatter
+	if raw, _, ok := splitFrontmatter(content); ok {
+		if err := yaml.Unmarshal(raw, &front); err != nil {
+			return nil, fmt.Errorf("parse frontmatter: %w", err)
+		}
+	}
+
+	est := &promptEstimate{ChatTokens: estimateTokens(content)}
+	est.Model, est.ModelSource = resolveChatModel(jobFile, front)
+	est.ContextWindow, _ = contextWindow(est.Model)
+	est.Contributors = append(est.Contributors, promptContributor{Kind: "chat", Path: jobFile, Tokens: est.ChatTokens})
+
+	est.RulesFile = jobRulesFile(jobFile, front)
+	if est.RulesFile != "" {
+		contributors, err := rulesContext(est.RulesFile, cache)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range contributors {
+			est.ContextTokens += c.Tokens
+		}
+		est.Contributors = append(est.Contributors, contributors...)
+	}
+
+	sort.SliceStable(est.Contributors, func(i, j int) bool {
+		return est.Contributors[i].Tokens > est.Contributors[j].Tokens
+	})
+	return est, nil
+}
+
+// jobRulesFile is the rules file a job's context comes from: the one its
+// frontmatter names, else the nearest .grove/rules above the job. Empty when
+// there is none.
+func jobRulesFile(jobFile string, front chatFrontmatter) string {
+	if front.RulesFile != "" {
+		if filepath.IsAbs(front.RulesFile) {
+			return front.RulesFile
+		}
+		return filepath.Join(filepath.Dir(jobFile), front.RulesFile)
+	}
+	if found, err := findRulesFile(filepath.Dir(jobFile)); err == nil {
+		return found
+	}
+	return ""
+}
+
+// rulesContext sizes every file a rules file brings into context.
+func rulesContext(rulesFile string, cache *tokenCache) ([]promptContributor, error) {
+	rules, err := os.ReadFile(rulesFile) //nolint:gosec // rules file named by the job
+	if err != nil {
+		return nil, fmt.Errorf("read rules file: %w", err)
+	}
+	baseDir, err := rulesBaseDir(rulesFile)
+	if err != nil {
+		return nil, fmt.Errorf("resolve rules base directory: %w", err)
+	}
+	var contributors []promptContributor
+	eval := evaluateRules(parseRules(string(rules)), newRuleResolver(baseDir))
+	for _, f := range eval.Files() {
+		tokens, ok := cache.Tokens(f)
+		if !ok {
+			continue
+		}
+		contributors = append(contributors, promptContributor{Kind: "context", Path: f, Tokens: tokens})
+	}
+	return contributors, nil
+}
+
+// maxBudgetContributors caps the breakdown in a budget error.
+const maxBudgetContributors = 10
+
+// budgetReport is the structured error printed when a submission would not
+// fit the model's context window.
+type budgetReport struct {
+	Error           string              `json:"error"`
+	Message         string              `json:"message"`
+	Model           string              `json:"model"`
+	ModelSource     string              `json:"modelSource"`
+	ContextWindow   int                 `json:"contextWindow"`
+	EstimatedTokens int                 `json:"estimatedTokens"`
+	ChatTokens      int                 `json:"chatTokens"`
+	ContextTokens   int                 `json:"contextTokens"`
+	RulesFile       string              `json:"rulesFile,omitempty"`
+	Contributors    []promptContributor `json:"contributors"`
+}
+
+func newBudgetReport(est *promptEstimate) budgetReport {
+	top := est.Contributors
+	if len(top) > maxBudgetContributors {
+		top = top[:maxBudgetContributors]
+	}
+	return budgetReport{
+		Error: "context_window_exceeded",
+		Message: fmt.Sprintf("estimated prompt of ~%d tokens exceeds the %d-token context window of %s",
+			est.Total(), est.ContextWindow, est.Model),
+		Model:           est.Model,
+		ModelSource:     est.ModelSource,
+		ContextWindow:   est.ContextWindow,
+		EstimatedTokens: est.Total(),
+		ChatTokens:      est.ChatTokens,
+		ContextTokens:   est.ContextTokens,
+		RulesFile:       est.RulesFile,
+		Contributors:    top,
+	}
+}
+
+// checkChatBudget estimates the prompt for jobFile and refuses submission
+// when it exceeds the model's context window, printing a budgetReport as JSON
+// on stdout. With force the overrun is only reported on stderr. An estimate
+// that cannot be made never blocks a submission, but is warned about on
+// stderr so a broken rules file does not silently disable the guard.
+func checkChatBudget(jobFile string, force bool) error {
+	cache := loadTokenCache()
+	defer cache.Save()
+
+	est, err := estimatePrompt(jobFile, cache)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not estimate the prompt size, skipping the context window check: %v\n", err)
+		return nil
+	}
+	if est.ContextWindow == 0 {
+		chatLog.Debug("Unknown context window; skipping token budget check").Field("model", est.Model).Emit()
+		return nil
+	}
+	if !est.Exceeded() {
+		return nil
+	}
+
+	report := newBudgetReport(est)
+	if force {
+		fmt.Fprintf(os.Stderr, "Warning: %s; submitting anyway (--force)\n", report.Message)
+		return nil
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+		return err
+	}
+	return fmt.Errorf("%s; rerun with --force to submit anyway", report.Message)
+}
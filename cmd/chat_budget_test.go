@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitFrontmatter(t *testing.T) {
+	front, body, ok := splitFrontmatter([]byte("---\nmodel: x\n---\nhello\n"))
+	require.True(t, ok)
+	assert.Equal(t, "model: x\n", string(front))
+	assert.Equal(t, "hello\n", string(body))
+
+	_, body, ok = splitFrontmatter([]byte("no frontmatter\n---\n"))
+	assert.False(t, ok)
+	assert.Equal(t, "no frontmatter\n---\n", string(body))
+
+	_, _, ok = splitFrontmatter([]byte("---\nnever closed\n"))
+	assert.False(t, ok)
+}
+
+func TestContextWindow(t *testing.T) {
+	w, ok := contextWindow("gemini-1.5-pro-latest")
+	require.True(t, ok)
+	assert.Equal(t, 2097152, w, "the longer prefix wins")
+
+	w, ok = contextWindow("anthropic/claude-sonnet-4")
+	require.True(t, ok)
+	assert.Equal(t, 200000, w)
+
+	_, ok = contextWindow("local-llama")
+	assert.False(t, ok)
+}
+
+func TestEstimatePrompt(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"plan/.grove-plan.yml": "model: gpt-4o\n",
+		"plan/job.md":          "---\nrules_file: ../ctx.rules\n---\nSummarize this.\n",
+		"ctx.rules":            "src/**\n!src/skip.txt\n",
+		"src/big.txt":          strings.Repeat("x", 400000),
+		"src/small.txt":        strings.Repeat("y", 400),
+		"src/skip.txt":         strings.Repeat("z", 4000),
+	})
+	cache := &tokenCache{entries: make(map[string]tokenCacheEntry)}
+
+	est, err := estimatePrompt(filepath.Join(root, "plan", "job.md"), cache)
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o", est.Model)
+	assert.Equal(t, "plan", est.ModelSource)
+	assert.Equal(t, 128000, est.ContextWindow)
+	assert.Equal(t, 100100, est.ContextTokens, "excluded files do not count")
+	assert.False(t, est.Exceeded())
+
+	require.Len(t, est.Contributors, 3)
+	assert.Equal(t, filepath.Join(root, "src", "big.txt"), est.Contributors[0].Path, "largest first")
+	assert.Equal(t, "context", est.Contributors[0].Kind)
+	assert.Equal(t, "chat", est.Contributors[2].Kind)
+
+	est.ContextWindow = 50000
+	require.True(t, est.Exceeded())
+	report := newBudgetReport(est)
+	assert.Equal(t, "context_window_exceeded", report.Error)
+	assert.Equal(t, est.Total(), report.EstimatedTokens)
+	assert.Len(t, report.Contributors, 3)
+}
+
+func TestEstimatePromptFindsRulesAboveJob(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".grove/rules":   "src/**\n",
+		"plans/p/job.md": "Hello.\n",
+		"src/a.txt":      strings.Repeat("a", 40),
+	})
+	cache := &tokenCache{entries: make(map[string]tokenCacheEntry)}
+
+	est, err := estimatePrompt(filepath.Join(root, "plans", "p", "job.md"), cache)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".grove", "rules"), est.RulesFile, "found from the job's directory, not cwd")
+	assert.Equal(t, 10, est.ContextTokens)
+}
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultKeepLast      = 4
+	defaultSummaryTokens = 1000
+)
+
+// compactPlan is what compacting a chat would do. Token counts are
+// estimates; TokensAfter assumes the summary comes in at SummaryTokens.
+type compactPlan struct {
+	Turns         int `json:"turns"`
+	Summarized    int `json:"summarized"` // turns 1..Summarized are replaced
+	Kept          int `json:"kept"`       // at least the requested turns, from a user turn on
+	TokensBefore  int `json:"tokensBefore"`
+	SummaryTokens int `json:"summaryTokens"`
+	TokensAfter   int `json:"tokensAfter"`
+	Savings       int `json:"savings"`
+
+	doc *chatDocument
+}
+
+// planCompaction splits a chat into the turns to summarize and the last
+// keep turns to carry over verbatim. The split moves back to the start of a
+// user turn, so the kept tail never opens with a response to a prompt that
+// was summarized away. The summary is expected to take a quarter of what it
+// replaces, at most maxSummary tokens.
+func planCompaction(content []byte, keep, maxSummary int) (*compactPlan, error) {
+	doc := parseChatDocument(content)
+	for _, t := range doc.Turns {
+		if t.Running() {
+			return nil, fmt.Errorf("turn %d is still running", t.Index)
+		}
+	}
+	if keep < 0 {
+		return nil, fmt.Errorf("--keep-last must not be negative")
+	}
+	split := len(doc.Turns) - keep
+	for split > 0 && split < len(doc.Turns) && doc.Turns[split].Role != "user" {
+		split--
+	}
+	p := &compactPlan{Turns: len(doc.Turns), Summarized: split, Kept: len(doc.Turns) - split, doc: doc}
+	if p.Summarized < 2 {
+		return nil, fmt.Errorf("nothing to compact: %d turns, keeping the last %d", p.Turns, keep)
+	}
+
+	body := doc.text(doc.bodyStart, len(doc.lines))
+	summarized := doc.text(doc.bodyStart, doc.Turns[p.Summarized-1].end)
+	p.TokensBefore = estimateTokens([]byte(body))
+	p.SummaryTokens = min(estimateTokens([]byte(summarized))/4, maxSummary)
+	p.TokensAfter = p.TokensBefore - estimateTokens([]byte(summarized)) + p.SummaryTokens
+	p.Savings = p.TokensBefore - p.TokensAfter
+	return p, nil
+}
+
+// summaryOmittedFields are the frontmatter fields a summary job drops: links
+// to other jobs in the plan, which would hold it back or pull their output
+// into the summary, and bookkeeping that belongs to the original chat.
+var summaryOmittedFields = []string{
+	"depends_on", "prepend_dependencies", "prompt_source", "summary",
+	"created_at", "updated_at", "completed_at",
+	"forked_from", "forked_from_id", "forked_at_turn", "compacted_from",
+}
+
+// summaryJob is the chat job that asks for the summary: the original
+// frontmatter under a new id, the turns being summarized, and a final user
+// turn with the instructions. Its file lives in another directory, so the
+// model and rules file jobFile resolves to are pinned in its frontmatter.
+func (p *compactPlan) summaryJob(jobFile string) ([]byte, error) {
+	front, err := parseFrontmatterNode(p.doc.Front)
+	if err != nil {
+		return nil, err
+	}
+	var fm chatFrontmatter
+	if err := front.Decode(&fm); err != nil {
+		return nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+	if model, _ := resolveChatModel(jobFile, fm); model != "" {
+		setFrontmatterField(front, "model", model)
+	}
+	if fm.RulesFile != "" && !filepath.IsAbs(fm.RulesFile) {
+		setFrontmatterField(front, "rules_file", filepath.Join(filepath.Dir(jobFile), fm.RulesFile))
+	}
+	id := frontmatterField(front, "id")
+	if id == "" {
+		id = "chat"
+	}
+	for _, key := range summaryOmittedFields {
+		removeFrontmatterField(front, key)
+	}
+	setFrontmatterField(front, "id", id+"-compact-"+newDirectiveID())
+	setFrontmatterField(front, "status", "pending")
+	header, err := renderFrontmatter(front)
+	if err != nil {
+		return nil, err
+	}
+
+	words := max(p.SummaryTokens*3/4, 50)
+	prompt := fmt.Sprintf(`Summarize the conversation above so it can replace it as the start of this chat.
+Keep every decision, requirement, file path, identifier and open question; drop pleasantries and superseded drafts.
+Answer with the summary only, in Markdown, in no more than about %d words.`, words)
+
+	var b strings.Builder
+	b.Write(header)
+	b.WriteString(strings.TrimRight(p.doc.text(p.doc.bodyStart, p.doc.Turns[p.Summarized-1].end), "\n \t"))
+	b.WriteString("\n\n<!-- grove: {\"template\": \"chat\"} -->\n")
+	b.WriteString(prompt)
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
+
+// compacted is the rewritten chat: the original frontmatter noting the
+// backup, the summary as the opening turn, then the kept turns verbatim.
+func (p *compactPlan) compacted(summary, backup string) ([]byte, error) {
+	front, err := parseFrontmatterNode(p.doc.Front)
+	if err != nil {
+		return nil, err
+	}
+	setFrontmatterField(front, "compacted_from", backup)
+	header, err := renderFrontmatter(front)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.Write(header)
+	fmt.Fprintf(&b, "## Summary of turns 1–%d\n\n%s\n", p.Summarized, strings.TrimSpace(summary))
+	if p.Kept > 0 {
+		kept := p.doc.text(p.doc.Turns[p.Summarized].start, len(p.doc.lines))
+		b.WriteString("\n")
+		b.WriteString(strings.TrimRight(kept, "\n \t"))
+		b.WriteString("\n")
+	}
+	return []byte(b.String()), nil
+}
+
+// waitForResponse polls a chat job until its last turn is a finished
+// response and returns that response. A job flow marks failed or blocked
+// will never answer, so that ends the wait early.
+func waitForResponse(ctx context.Context, jobFile string, interval time.Duration) (string, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if content, err := os.ReadFile(jobFile); err == nil { //nolint:gosec // job file this command wrote
+			doc := parseChatDocument(content)
+			if status := jobStatus(doc); status == "failed" || status == "blocked" {
+				return "", fmt.Errorf("summary job %s is %s", jobFile, status)
+			}
+			if n := len(doc.Turns); n > 0 {
+				last := doc.Turns[n-1]
+				if last.Role == "llm" && !last.Running() && last.Content != "" {
+					return last.Content, nil
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("no summary in %s: %w", jobFile, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// jobStatus is the status field of a chat's frontmatter.
+func jobStatus(doc *chatDocument) string {
+	var front struct {
+		Status string `yaml:"status"`
+	}
+	if doc.Front != nil {
+		_ = yaml.Unmarshal(doc.Front, &front)
+	}
+	return front.Status
+}
+
+func newChatCompactCmd() *cobra.Command {
+	var (
+		keepLast      int
+		summaryTokens int
+		dryRun        bool
+		jsonOutput    bool
+		timeout       time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "compact <file>",
+		Short: "Replace older chat turns with a summary",
+		Long: `Summarizes all but the last --keep-last turns and rewrites the chat as that
+summary followed by the kept turns verbatim, so a long conversation fits the
+model's window again. The kept turns always start at a user turn, so one more
+turn than asked is kept when the split would fall on a response.
+
+The summary is produced by a chat job written to .compact/ in the plan and
+submitted like 'chat'. Through the daemon it is submitted as a job of the
+chat's plan; the 'flow run' fallback runs the file on its own, outside the
+plan. It drops depends_on and the other fields that tie it to the plan's
+jobs, so it runs the same either way.
+The command waits up to --timeout for it. The original is backed up to
+.archive/ first and recorded as compacted_from in the frontmatter.
+
+--dry-run only reports the estimated token savings.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobFile, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			original, err := os.ReadFile(jobFile) //nolint:gosec // user-specified job file
+			if err != nil {
+				return fmt.Errorf("read job file: %w", err)
+			}
+			plan, err := planCompaction(original, keepLast, summaryTokens)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				if jsonOutput {
+					return json.NewEncoder(os.Stdout).Encode(plan)
+				}
+				fmt.Printf("Would summarize turns 1-%d and keep %d: ~%d → ~%d tokens (saves ~%d)\n",
+					plan.Summarized, plan.Kept, plan.TokensBefore, plan.TokensAfter, plan.Savings)
+				return nil
+			}
+
+			job, err := plan.summaryJob(jobFile)
+			if err != nil {
+				return err
+			}
+			dir := filepath.Dir(jobFile)
+			stem := strings.TrimSuffix(filepath.Base(jobFile), filepath.Ext(jobFile))
+			scratch := filepath.Join(dir, ".compact")
+			if err := os.MkdirAll(scratch, 0o750); err != nil {
+				return err
+			}
+			summaryFile := filepath.Join(scratch, stem+".md")
+			if err := os.WriteFile(summaryFile, job, 0o600); err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if err := runChatInPlan(ctx, dir, summaryFile, false); err != nil {
+				return fmt.Errorf("submit summary job: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Waiting for the summary in %s\n", summaryFile)
+			summary, err := waitForResponse(ctx, summaryFile, 2*time.Second)
+			if err != nil {
+				return err
+			}
+
+			if current, err := os.ReadFile(jobFile); err != nil || !bytes.Equal(current, original) { //nolint:gosec // user-specified job file
+				return fmt.Errorf("%s changed while summarizing; the summary is in %s", jobFile, summaryFile)
+			}
+			backup := filepath.Join(".archive", fmt.Sprintf("%s.%s.md", stem, time.Now().UTC().Format("20060102T150405Z")))
+			if err := os.MkdirAll(filepath.Join(dir, ".archive"), 0o750); err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(dir, backup), original, 0o600); err != nil {
+				return fmt.Errorf("back up chat: %w", err)
+			}
+			updated, err := plan.compacted(summary, backup)
+			if err != nil {
+				return err
+			}
+			if err := writeFileAtomic(jobFile, updated); err != nil {
+				return err
+			}
+			_ = os.Remove(summaryFile)
+
+			_, body, _ := splitFrontmatter(updated)
+			after := estimateTokens(body)
+			if jsonOutput {
+				plan.SummaryTokens = estimateTokens([]byte(summary))
+				plan.TokensAfter = after
+				plan.Savings = plan.TokensBefore - after
+				return json.NewEncoder(os.Stdout).Encode(plan)
+			}
+			fmt.Printf("Compacted turns 1-%d: ~%d → ~%d tokens. Original saved to %s\n",
+				plan.Summarized, plan.TokensBefore, after, filepath.Join(dir, backup))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&keepLast, "keep-last", defaultKeepLast, "Turns to keep verbatim (at least; the kept tail starts at a user turn)")
+	cmd.Flags().IntVar(&summaryTokens, "summary-tokens", defaultSummaryTokens, "Upper bound on the summary's size in tokens")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only report the estimated token savings")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the compaction report as JSON")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "How long to wait for the summary")
+
+	return cmd
+}
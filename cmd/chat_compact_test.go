@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanCompaction(t *testing.T) {
+	chat := strings.TrimSuffix(exportFixture, "<!-- grove: {\"id\": \"c3\", \"state\": \"running\"} -->\n")
+
+	_, err := planCompaction([]byte(exportFixture), 1, defaultSummaryTokens)
+	assert.ErrorContains(t, err, "still running")
+	_, err = planCompaction([]byte(chat), 3, defaultSummaryTokens)
+	assert.ErrorContains(t, err, "nothing to compact")
+
+	plan, err := planCompaction([]byte(chat), 2, defaultSummaryTokens)
+	require.NoError(t, err)
+	assert.Equal(t, 4, plan.Turns)
+	assert.Equal(t, 2, plan.Summarized)
+	assert.Positive(t, plan.Savings)
+	assert.Equal(t, plan.TokensBefore-plan.TokensAfter, plan.Savings)
+
+	rounded, err := planCompaction([]byte(chat), 1, defaultSummaryTokens)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rounded.Summarized, "the kept tail starts at the user turn")
+	assert.Equal(t, 2, rounded.Kept)
+
+	job, err := plan.summaryJob(filepath.Join(t.TempDir(), "01-parser.md"))
+	require.NoError(t, err)
+	jobDoc := parseChatDocument(job)
+	require.Len(t, jobDoc.Turns, 3, "the summarized turns plus the request")
+	assert.Contains(t, jobDoc.Turns[2].Content, "Summarize the conversation above")
+	assert.NotContains(t, string(job), "Shorter", "kept turns are not summarized")
+	assert.Contains(t, string(jobDoc.Front), "id: chat-compact-")
+
+	out, err := plan.compacted("They asked for a parser and got one.", ".archive/01-parser.bak.md")
+	require.NoError(t, err)
+	doc := parseChatDocument(out)
+	require.Len(t, doc.Turns, 3)
+	assert.Equal(t, "## Summary of turns 1–2\n\nThey asked for a parser and got one.", doc.Turns[0].Content)
+	assert.Equal(t, "claude-sonnet-4-5", doc.Turns[1].Directive["model"], "kept turns are verbatim")
+	assert.Equal(t, "b2", doc.Turns[2].ID)
+	assert.Contains(t, string(doc.Front), "compacted_from: .archive/01-parser.bak.md")
+	assert.Contains(t, string(doc.Front), "title: Parser")
+}
+
+func TestSummaryJobDropsPlanLinks(t *testing.T) {
+	chat := strings.Replace(strings.TrimSuffix(exportFixture, "<!-- grove: {\"id\": \"c3\", \"state\": \"running\"} -->\n"),
+		"title: Parser\n", "id: parser\ntitle: Parser\ndepends_on:\n  - 01-spec.md\nprompt_source:\n  - notes.md\n", 1)
+	plan, err := planCompaction([]byte(chat), 2, defaultSummaryTokens)
+	require.NoError(t, err)
+
+	job, err := plan.summaryJob(filepath.Join(t.TempDir(), "02-parser.md"))
+	require.NoError(t, err)
+	front := string(parseChatDocument(job).Front)
+	assert.NotContains(t, front, "depends_on")
+	assert.NotContains(t, front, "prompt_source")
+	assert.Contains(t, front, "id: parser-compact-")
+	assert.Contains(t, front, "status: pending")
+}
+
+func TestWaitForResponseStopsOnFailure(t *testing.T) {
+	jobFile := filepath.Join(t.TempDir(), "summary.md")
+	require.NoError(t, os.WriteFile(jobFile, []byte("---\nstatus: failed\n---\nSummarize.\n"), 0o600))
+
+	_, err := waitForResponse(context.Background(), jobFile, time.Millisecond)
+	assert.ErrorContains(t, err, "is failed")
+}
@@ -0,0 +1,401 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultCollapseLines is the length above which exported code blocks are
+// folded away.
+const defaultCollapseLines = 15
+
+// exportTurn is one turn of an exported transcript.
+type exportTurn struct {
+	Turn      int    `json:"turn"`
+	Role      string `json:"role"` // "user" or "llm"
+	Model     string `json:"model,omitempty"`
+	Template  string `json:"template,omitempty"`
+	ID        string `json:"id,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Content   string `json:"content"` // directives that do not open a turn removed
+}
+
+// exportTranscript is the JSON export format.
+type exportTranscript struct {
+	Title   string       `json:"title"`
+	File    string       `json:"file"`
+	Model   string       `json:"model,omitempty"`
+	Created string       `json:"created,omitempty"`
+	Turns   []exportTurn `json:"turns"`
+}
+
+// stringField returns the first non-empty string among keys.
+func stringField(m map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := m[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// buildTranscript collects a chat's turns for export. An LLM turn's model is
+// its directive's, else the one its user turn asked for, else the chat's.
+// Running placeholders are left out.
+func buildTranscript(jobFile string, content []byte) (*exportTranscript, error) {
+	doc := parseChatDocument(content)
+	var front chatFrontmatter
+	meta := map[string]any{}
+	if doc.Front != nil {
+		if err := yaml.Unmarshal(doc.Front, &front); err != nil {
+			return nil, fmt.Errorf("parse frontmatter: %w", err)
+		}
+		_ = yaml.Unmarshal(doc.Front, &meta)
+	}
+	chatModel, _ := resolveChatModel(jobFile, front)
+
+	t := &exportTranscript{
+		Title: stringField(meta, "title"),
+		File:  filepath.Base(jobFile),
+		Model: chatModel,
+		Turns: []exportTurn{},
+	}
+	if v := firstValue(meta, "created_at", "created"); v != nil {
+		t.Created = fmt.Sprint(v)
+	}
+	if t.Title == "" {
+		t.Title = strings.TrimSuffix(t.File, filepath.Ext(t.File))
+	}
+
+	askedModel := ""
+	for _, turn := range doc.Turns {
+		if turn.Running() {
+			continue
+		}
+		e := exportTurn{
+			Turn:      turn.Index,
+			Role:      turn.Role,
+			ID:        turn.ID,
+			Template:  stringField(turn.Directive, "template"),
+			Timestamp: stringField(turn.Directive, "timestamp", "created_at", "time"),
+			Content:   stripDirectives(turn.Content),
+		}
+		if turn.Role == "user" {
+			askedModel = stringField(turn.Directive, "model")
+		} else {
+			e.Model = firstNonEmpty(stringField(turn.Directive, "model"), askedModel, chatModel)
+		}
+		t.Turns = append(t.Turns, e)
+	}
+	return t, nil
+}
+
+func firstValue(m map[string]any, keys ...string) any {
+	for _, k := range keys {
+		if v, ok := m[k]; ok && v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// stripDirectives drops grove directive lines left inside a turn.
+func stripDirectives(content string) string {
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	for _, l := range lines {
+		if !chatDirectiveRe.MatchString(l) {
+			kept = append(kept, l)
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// turnHeading is the label an exported turn is introduced with.
+func turnHeading(t exportTurn) string {
+	label := "User"
+	var details []string
+	if t.Role == "llm" {
+		label = "Assistant"
+		details = append(details, t.Model)
+	} else if t.Template != "" && t.Template != "chat" {
+		details = append(details, t.Template)
+	}
+	details = append(details, t.Timestamp)
+	var parts []string
+	for _, d := range details {
+		if d != "" {
+			parts = append(parts, d)
+		}
+	}
+	if len(parts) == 0 {
+		return label
+	}
+	return label + " · " + strings.Join(parts, " · ")
+}
+
+// mdBlock is a run of markdown: prose, or a fenced code block.
+type mdBlock struct {
+	code  bool
+	fence string // the opening fence, e.g. "````"
+	info  string // fence info string
+	text  string // code without its fences, or the prose
+}
+
+// splitFences separates fenced code blocks from the prose around them. As in
+// CommonMark, a block opened by a run of N backticks or tildes is closed only
+// by a bare run of at least N of the same character, so a shorter fence
+// inside it (a ``` example within a ```` block) is code. An unclosed fence
+// runs to the end.
+func splitFences(content string) []mdBlock {
+	var blocks []mdBlock
+	var prose, code []string
+	var fence, info string
+	flushProse := func() {
+		if text := strings.TrimSpace(strings.Join(prose, "\n")); text != "" {
+			blocks = append(blocks, mdBlock{text: text})
+		}
+		prose = nil
+	}
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		opening, openingInfo, isOpening := openFence(trimmed)
+		switch {
+		case fence == "" && isOpening:
+			flushProse()
+			fence, info = opening, openingInfo
+			code = nil
+		case fence != "" && strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "":
+			blocks = append(blocks, mdBlock{code: true, fence: fence, info: info, text: strings.Join(code, "\n")})
+			fence = ""
+		case fence != "":
+			code = append(code, line)
+		default:
+			prose = append(prose, line)
+		}
+	}
+	if fence != "" {
+		blocks = append(blocks, mdBlock{code: true, fence: fence, info: info, text: strings.Join(code, "\n")})
+	}
+	flushProse()
+	return blocks
+}
+
+// openFence reports whether line opens a fenced code block, returning the
+// fence (the whole run of backticks or tildes) and the info string after it.
+// A backtick fence's info string may not contain backticks; such a line is
+// inline code.
+func openFence(line string) (fence, info string, ok bool) {
+	if !strings.HasPrefix(line, "```") && !strings.HasPrefix(line, "~~~") {
+		return "", "", false
+	}
+	rest := strings.TrimLeft(line, line[:1])
+	if line[0] == '`' && strings.Contains(rest, "`") {
+		return "", "", false
+	}
+	return line[:len(line)-len(rest)], strings.TrimSpace(rest), true
+}
+
+// codeSummary describes a collapsed code block.
+func codeSummary(b mdBlock) string {
+	lines := strings.Count(b.text, "\n") + 1
+	if b.info != "" {
+		return fmt.Sprintf("%s · %d lines", b.info, lines)
+	}
+	return fmt.Sprintf("%d lines", lines)
+}
+
+func shouldCollapse(b mdBlock, collapse int) bool {
+	return b.code && collapse > 0 && strings.Count(b.text, "\n")+1 > collapse
+}
+
+// renderMarkdownTranscript writes clean Markdown: a heading per turn, no
+// directives or frontmatter, long code blocks folded into <details>.
+func renderMarkdownTranscript(t *exportTranscript, collapse int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", t.Title)
+	for _, turn := range t.Turns {
+		fmt.Fprintf(&b, "\n## %s\n\n", turnHeading(turn))
+		for i, block := range splitFences(turn.Content) {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			switch {
+			case shouldCollapse(block, collapse):
+				fmt.Fprintf(&b, "<details>\n<summary>%s</summary>\n\n%s%s\n%s\n%s\n\n</details>\n", html.EscapeString(codeSummary(block)), block.fence, block.info, block.text, block.fence)
+			case block.code:
+				fmt.Fprintf(&b, "%s%s\n%s\n%s\n", block.fence, block.info, block.text, block.fence)
+			default:
+				fmt.Fprintf(&b, "%s\n", block.text)
+			}
+		}
+	}
+	return b.String()
+}
+
+var (
+	mdHeadingRe    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdInlineCodeRe = regexp.MustCompile("`([^`]+)`")
+)
+
+// defaultExportPalette is used when no grove theme palette can be loaded.
+var defaultExportPalette = &themePalette{
+	Bg: "#1a1b26", BgDark: "#16161e", BgHighlight: "#292e42", Fg: "#c0caf5",
+	Comment: "#565f89", Border: "#3b4261", Blue: "#7aa2f7", Green: "#9ece6a", Yellow: "#e0af68",
+}
+
+// exportPalette is the current theme's palette, resolved like the editor's,
+// or the default when the theme has none.
+func exportPalette(appearance string) *themePalette {
+	payload, err := loadThemePayload(resolveThemeName())
+	if err != nil || payload.Mode == "ansi" {
+		return defaultExportPalette.resolved()
+	}
+	palettes, err := payload.appearances(appearance)
+	if err != nil {
+		return defaultExportPalette.resolved()
+	}
+	return palettes[0].resolved()
+}
+
+// renderHTMLTranscript writes a standalone page styled with the palette.
+// Turn borders use the same colors as the chat UI's dividers.
+func renderHTMLTranscript(t *exportTranscript, collapse int, p *themePalette) string {
+	esc := html.EscapeString
+	var b strings.Builder
+	fmt.Fprintf(&b, `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+:root { --bg: %s; --bg-dark: %s; --bg-hl: %s; --fg: %s; --muted: %s; --border: %s; --accent: %s; --user: %s; --llm: %s; }
+body { background: var(--bg); color: var(--fg); font: 15px/1.55 system-ui, sans-serif; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; }
+h1 { font-size: 1.4rem; border-bottom: 1px solid var(--border); padding-bottom: .5rem; }
+.turn { border-left: 3px solid var(--border); margin: 1.5rem 0; padding: .25rem 1rem; }
+.turn.user { border-color: var(--user); }
+.turn.llm { border-color: var(--llm); }
+.role { color: var(--muted); font-size: .85rem; margin-bottom: .5rem; }
+.user .role strong { color: var(--user); }
+.llm .role strong { color: var(--llm); }
+p { white-space: pre-wrap; }
+pre { background: var(--bg-dark); border: 1px solid var(--border); border-radius: 4px; padding: .75rem; overflow-x: auto; }
+code { font: 13px/1.45 ui-monospace, monospace; }
+p code { background: var(--bg-hl); padding: 0 .25rem; border-radius: 3px; }
+summary { color: var(--accent); cursor: pointer; font-size: .85rem; }
+</style>
+</head>
+<body>
+<h1>%s</h1>
+`, esc(t.Title), p.Bg, p.BgDark, p.BgHighlight, p.Fg, p.Comment, p.Border, p.Blue, p.Diagnostics.Warning, p.Git.Add, esc(t.Title))
+
+	for _, turn := range t.Turns {
+		label, details, _ := strings.Cut(turnHeading(turn), " · ")
+		fmt.Fprintf(&b, "<section class=\"turn %s\">\n<div class=\"role\"><strong>%s</strong>", turn.Role, esc(label))
+		if details != "" {
+			fmt.Fprintf(&b, " · %s", esc(details))
+		}
+		b.WriteString("</div>\n")
+		for _, block := range splitFences(turn.Content) {
+			switch {
+			case block.code:
+				class := ""
+				if lang, _, _ := strings.Cut(block.info, " "); lang != "" {
+					class = fmt.Sprintf(" class=\"language-%s\"", esc(lang))
+				}
+				pre := fmt.Sprintf("<pre><code%s>%s</code></pre>\n", class, esc(block.text))
+				if shouldCollapse(block, collapse) {
+					pre = fmt.Sprintf("<details><summary>%s</summary>\n%s</details>\n", esc(codeSummary(block)), pre)
+				}
+				b.WriteString(pre)
+			default:
+				for _, para := range strings.Split(block.text, "\n\n") {
+					para = strings.TrimSpace(para)
+					if m := mdHeadingRe.FindStringSubmatch(para); m != nil && !strings.Contains(para, "\n") {
+						level := min(len(m[1])+2, 6)
+						fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, esc(m[2]), level)
+						continue
+					}
+					fmt.Fprintf(&b, "<p>%s</p>\n", mdInlineCodeRe.ReplaceAllString(esc(para), "<code>$1</code>"))
+				}
+			}
+		}
+		b.WriteString("</section>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+func newChatExportCmd() *cobra.Command {
+	var (
+		format     string
+		out        string
+		collapse   int
+		appearance string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export <file>",
+		Short: "Export a chat transcript as HTML, JSON or clean Markdown",
+		Long: `Renders a chat's turns without its frontmatter and grove directives.
+
+  md    a heading per turn with its role, model and timestamp
+  json  the title, chat model and turns as structured data
+  html  a standalone page styled with the current grove theme's palette
+
+In md and html, code blocks longer than --collapse lines (0 disables) are
+folded into a <details> element. Running placeholders are left out.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobFile, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(jobFile) //nolint:gosec // user-specified job file
+			if err != nil {
+				return fmt.Errorf("read job file: %w", err)
+			}
+			transcript, err := buildTranscript(jobFile, content)
+			if err != nil {
+				return err
+			}
+
+			var rendered string
+			switch format {
+			case "md", "markdown":
+				rendered = renderMarkdownTranscript(transcript, collapse)
+			case "html":
+				rendered = renderHTMLTranscript(transcript, collapse, exportPalette(appearance))
+			case "json":
+				data, err := json.MarshalIndent(transcript, "", "  ")
+				if err != nil {
+					return err
+				}
+				rendered = string(data) + "\n"
+			default:
+				return fmt.Errorf("unknown format %q (want html, json or md)", format)
+			}
+
+			if out == "" {
+				_, err = fmt.Fprint(os.Stdout, rendered)
+				return err
+			}
+			return os.WriteFile(out, []byte(rendered), 0o600)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "md", "Output format: html, json or md")
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Write to a file instead of stdout")
+	cmd.Flags().IntVar(&collapse, "collapse", defaultCollapseLines, "Fold code blocks longer than this many lines (0 disables)")
+	cmd.Flags().StringVar(&appearance, "appearance", "", "Theme appearance for html: dark or light")
+
+	return cmd
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exportFixture = `---
+title: Parser
+model: gpt-4o
+---
+Write a parser.
+
+<!-- grove: {"id": "a1", "timestamp": "2025-06-01T10:00:00Z"} -->
+Here it is:
+
+` + "```go\nfunc parse() {}\n```" + `
+
+<!-- grove: {"template": "chat", "model": "claude-sonnet-4-5"} -->
+<!-- grove: {"note": "not a turn"} -->
+Shorter, <please>.
+
+<!-- grove: {"id": "b2"} -->
+` + "```go\n" + `a
+b
+c
+` + "```" + `
+
+<!-- grove: {"id": "c3", "state": "running"} -->
+`
+
+func TestBuildTranscript(t *testing.T) {
+	tr, err := buildTranscript(filepath.Join(t.TempDir(), "01-parser.md"), []byte(exportFixture))
+	require.NoError(t, err)
+	assert.Equal(t, "Parser", tr.Title)
+	require.Len(t, tr.Turns, 4, "the running placeholder is left out")
+
+	assert.Equal(t, "gpt-4o", tr.Turns[1].Model)
+	assert.Equal(t, "2025-06-01T10:00:00Z", tr.Turns[1].Timestamp)
+	assert.Equal(t, "Shorter, <please>.", tr.Turns[2].Content, "stray directives are stripped")
+	assert.Equal(t, "claude-sonnet-4-5", tr.Turns[3].Model, "the model the user turn asked for")
+}
+
+func TestRenderTranscripts(t *testing.T) {
+	tr, err := buildTranscript(filepath.Join(t.TempDir(), "01-parser.md"), []byte(exportFixture))
+	require.NoError(t, err)
+
+	md := renderMarkdownTranscript(tr, 2)
+	assert.NotContains(t, md, "grove:")
+	assert.Contains(t, md, "## Assistant · gpt-4o · 2025-06-01T10:00:00Z\n\nHere it is:\n\n```go\nfunc parse() {}\n```\n")
+	assert.Contains(t, md, "<details>\n<summary>go · 3 lines</summary>", "long blocks fold")
+
+	p := (&themePalette{Bg: "#101010", Fg: "#eeeeee", Green: "#00ff00", Yellow: "#ffff00"}).resolved()
+	page := renderHTMLTranscript(tr, 0, p)
+	assert.Contains(t, page, "--bg: #101010")
+	assert.Contains(t, page, "--llm: #00ff00", "responses use the theme's git add color")
+	assert.Contains(t, page, "Shorter, &lt;please&gt;.")
+	assert.Contains(t, page, `<pre><code class="language-go">func parse() {}</code></pre>`)
+	assert.NotContains(t, page, "<details>", "collapse 0 keeps every block open")
+	assert.Equal(t, 4, strings.Count(page, "<section"))
+}
+
+func TestSplitFencesNested(t *testing.T) {
+	content := "Use a longer fence:\n\n````markdown\nExample:\n```go\nfunc f() {}\n```\n````\n\n~~~\n```\n~~~~\nafter"
+	blocks := splitFences(content)
+	require.Len(t, blocks, 4)
+	assert.Equal(t, "Use a longer fence:", blocks[0].text)
+	assert.True(t, blocks[1].code)
+	assert.Equal(t, "````", blocks[1].fence)
+	assert.Equal(t, "markdown", blocks[1].info)
+	assert.Equal(t, "Example:\n```go\nfunc f() {}\n```", blocks[1].text, "a shorter fence inside is code")
+	assert.Equal(t, "```", blocks[2].text, "backticks do not close a tilde fence")
+	assert.Equal(t, "after", blocks[3].text, "a longer run of the same character closes")
+
+	inline := splitFences("```not a fence``` here")
+	require.Len(t, inline, 1)
+	assert.False(t, inline[0].code, "backticks in the info string make it inline code")
+
+	tr := &exportTranscript{Title: "t", Turns: []exportTurn{{Role: "llm", Content: content}}}
+	assert.Contains(t, renderMarkdownTranscript(tr, 0), "````markdown\nExample:\n```go\nfunc f() {}\n```\n````\n", "the original fence is kept")
+}
@@ -0,0 +1,419 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// extractBlock is a fenced code block from an LLM turn with the file it
+// targets. Kind is "file" for full contents and "diff" for a patch; Path is
+// empty when no target could be detected.
+type extractBlock struct {
+	Turn   int    `json:"turn"`
+	Index  int    `json:"index"` // 1-based within the turn
+	Lang   string `json:"lang,omitempty"`
+	Kind   string `json:"kind"`
+	Path   string `json:"path,omitempty"`   // as written in the chat
+	Target string `json:"target,omitempty"` // resolved absolute path
+	// OutsideRoot marks an alias target in another workspace; writing it
+	// always asks, even with --yes.
+	OutsideRoot bool   `json:"outsideRoot,omitempty"`
+	Error       string `json:"error,omitempty"` // why Target could not be resolved
+	Content     string `json:"content"`
+}
+
+var (
+	// fenceAttrRe matches path-like fence attributes: title="a.go", file=a.go.
+	fenceAttrRe = regexp.MustCompile(`(?:title|file|path|filename)=["']?([^"'\s]+)`)
+	// pathLineRe matches a line naming the file below it: "cmd/a.go",
+	// "**`cmd/a.go`**", "### File: cmd/a.go:" and the like.
+	pathLineRe = regexp.MustCompile("^(?:#+\\s*)?[*_]*(?:(?:File|Path|Filename)\\s*:\\s*)?[*_]*`?([^`*\\s]+?)`?[*_]*:?[*_]*$")
+	// diffTargetRe reads the new-file path of a unified diff.
+	diffTargetRe = regexp.MustCompile(`(?m)^\+\+\+ (?:b/)?(\S+)`)
+)
+
+// looksLikePath reports whether s could name a file: an alias, or a token
+// with a directory separator or an extension.
+func looksLikePath(s string) bool {
+	if s == "" || strings.ContainsAny(s, " \t=") || strings.Contains(s, "://") {
+		return false
+	}
+	if strings.HasPrefix(s, "@a:") || strings.HasPrefix(s, "@alias:") {
+		return true
+	}
+	base := s[strings.LastIndex(s, "/")+1:]
+	return (strings.Contains(s, "/") && base != "") || strings.Contains(strings.TrimPrefix(base, "."), ".")
+}
+
+// fencePath finds a target in a fence info string: a path attribute, a
+// "lang:path" pair, or a path-like word after the language.
+func fencePath(info string) (lang, path string) {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	lang = fields[0]
+	if m := fenceAttrRe.FindStringSubmatch(info); m != nil {
+		return lang, m[1]
+	}
+	if l, p, ok := strings.Cut(lang, ":"); ok && !strings.HasPrefix(lang, "@") && looksLikePath(p) {
+		return l, p
+	}
+	for _, f := range fields {
+		if looksLikePath(f) {
+			if f == lang {
+				lang = ""
+			}
+			return lang, f
+		}
+	}
+	return lang, ""
+}
+
+// precedingPath finds a target on the last non-blank prose line above a
+// block.
+func precedingPath(prose string) string {
+	lines := strings.Split(strings.TrimRight(prose, "\n \t"), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if m := pathLineRe.FindStringSubmatch(last); m != nil && looksLikePath(m[1]) {
+		return m[1]
+	}
+	return ""
+}
+
+func isDiff(lang, content string) bool {
+	return lang == "diff" || lang == "patch" ||
+		strings.HasPrefix(content, "diff --git ") || strings.HasPrefix(content, "--- ")
+}
+
+// extractBlocks collects the fenced blocks of a turn's content.
+func extractBlocks(turn int, content string) []extractBlock {
+	var out []extractBlock
+	prose := ""
+	for _, b := range splitFences(content) {
+		if !b.code {
+			prose = b.text
+			continue
+		}
+		lang, path := fencePath(b.info)
+		if path == "" {
+			path = precedingPath(prose)
+		}
+		prose = ""
+		e := extractBlock{Turn: turn, Index: len(out) + 1, Lang: lang, Kind: "file", Path: path, Content: b.text}
+		if isDiff(lang, b.text) {
+			e.Kind = "diff"
+			if m := diffTargetRe.FindStringSubmatch(b.text); m != nil && path == "" {
+				e.Path = m[1]
+			}
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// blockResolver turns the paths written in a chat into files under root.
+type blockResolver struct {
+	root  string
+	rules *ruleResolver
+}
+
+// resolve maps an alias through the workspace aliases and anything else
+// onto root. No path may climb out of its base: an alias target stays under
+// the alias's directory, and relative and absolute paths under root.
+func (r *blockResolver) resolve(p string) (string, error) {
+	if name, rest, ok := cutAliasDirective(p); ok {
+		base, err := r.rules.resolveAlias(name)
+		if err != nil {
+			return "", err
+		}
+		target := filepath.Join(base, filepath.FromSlash(rest))
+		if !within(base, target) {
+			return "", fmt.Errorf("%s is outside %s", p, base)
+		}
+		return target, nil
+	}
+	target := filepath.Clean(p)
+	if !filepath.IsAbs(p) {
+		target = filepath.Join(r.root, filepath.FromSlash(p))
+	}
+	if !within(r.root, target) {
+		return "", fmt.Errorf("%s is outside %s", p, r.root)
+	}
+	return target, nil
+}
+
+// within reports whether target is base or lies beneath it.
+func within(base, target string) bool {
+	rel, err := filepath.Rel(base, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// selectTurns returns the LLM turns to extract from: turn n, or the last
+// finished response when n is 0.
+func selectTurns(doc *chatDocument, n int) ([]chatTurn, error) {
+	if n > 0 {
+		if n > len(doc.Turns) {
+			return nil, fmt.Errorf("turn %d out of range: the chat has %d turns", n, len(doc.Turns))
+		}
+		if t := doc.Turns[n-1]; t.Role != "llm" {
+			return nil, fmt.Errorf("turn %d is not an LLM response", n)
+		}
+		return []chatTurn{doc.Turns[n-1]}, nil
+	}
+	for i := len(doc.Turns) - 1; i >= 0; i-- {
+		if t := doc.Turns[i]; t.Role == "llm" && !t.Running() {
+			return []chatTurn{t}, nil
+		}
+	}
+	return nil, fmt.Errorf("the chat has no LLM responses")
+}
+
+// filePatch is the unified diff that turns target's current content (or
+// nothing, for a new file) into content, with paths relative to root as
+// git apply expects them.
+func filePatch(root, target, content string) (string, error) {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+
+	tmp, err := os.MkdirTemp("", "grove-extract-")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	newFile := filepath.Join(tmp, "new")
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if err := os.WriteFile(newFile, []byte(content), 0o600); err != nil {
+		return "", err
+	}
+	oldFile := os.DevNull
+	if _, err := os.Stat(target); err == nil {
+		oldFile = target
+	}
+
+	// #nosec G204 -- fixed git subcommand over files this command resolved
+	out, err := exec.Command("git", "diff", "--no-index", "--no-color", "--no-prefix", "--", oldFile, newFile).Output()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return "", fmt.Errorf("git diff: %w", err)
+	}
+	if len(out) == 0 {
+		return "", nil
+	}
+
+	// Re-head the diff with the repository-relative path.
+	lines := strings.SplitAfter(string(out), "\n")
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "diff --git "):
+			lines[i] = fmt.Sprintf("diff --git a/%s b/%s\n", rel, rel)
+		case strings.HasPrefix(l, "--- ") && oldFile != os.DevNull:
+			lines[i] = "--- a/" + rel + "\n"
+		case strings.HasPrefix(l, "+++ "):
+			lines[i] = "+++ b/" + rel + "\n"
+		case strings.HasPrefix(l, "@@"):
+			return strings.Join(lines, ""), nil
+		}
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// buildPatch concatenates the patches of every resolved block. Diff blocks
+// are passed through as written. Targets outside root cannot be expressed
+// in a patch applied there, so they are left out.
+func buildPatch(root string, blocks []extractBlock) (string, error) {
+	var b strings.Builder
+	for _, blk := range blocks {
+		if blk.Kind == "diff" {
+			b.WriteString(strings.TrimRight(blk.Content, "\n") + "\n")
+			continue
+		}
+		if blk.Target == "" || blk.OutsideRoot {
+			continue
+		}
+		p, err := filePatch(root, blk.Target, blk.Content)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(p)
+	}
+	return b.String(), nil
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from in.
+func confirm(in *bufio.Reader, question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := in.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// applyBlocks writes file blocks and applies diff blocks with git apply,
+// asking first unless yes is set. Files outside root are always asked
+// about.
+func applyBlocks(root string, blocks []extractBlock, yes bool) error {
+	in := bufio.NewReader(os.Stdin)
+	for _, blk := range blocks {
+		switch {
+		case blk.Kind == "diff":
+			if !yes && !confirm(in, fmt.Sprintf("Apply the patch in block %d (%s)?", blk.Index, firstNonEmpty(blk.Path, "no path"))) {
+				continue
+			}
+			// #nosec G204 -- fixed git subcommand; the patch arrives on stdin
+			c := exec.Command("git", "-C", root, "apply", "--verbose", "-")
+			c.Stdin = strings.NewReader(strings.TrimRight(blk.Content, "\n") + "\n")
+			c.Stdout, c.Stderr = os.Stderr, os.Stderr
+			if err := c.Run(); err != nil {
+				return fmt.Errorf("git apply block %d: %w", blk.Index, err)
+			}
+		case blk.Target != "":
+			verb := "Create"
+			if _, err := os.Stat(blk.Target); err == nil {
+				verb = "Overwrite"
+			}
+			question := fmt.Sprintf("%s %s?", verb, blk.Target)
+			if blk.OutsideRoot {
+				question = fmt.Sprintf("%s %s, outside %s?", verb, blk.Target, root)
+			}
+			if (!yes || blk.OutsideRoot) && !confirm(in, question) {
+				if blk.OutsideRoot {
+					fmt.Fprintf(os.Stderr, "\nSkipped %s\n", blk.Target)
+				}
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(blk.Target), 0o750); err != nil {
+				return err
+			}
+			content := blk.Content
+			if !strings.HasSuffix(content, "\n") {
+				content += "\n"
+			}
+			if err := writeFileAtomic(blk.Target, []byte(content)); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Wrote %s\n", blk.Target)
+		}
+	}
+	return nil
+}
+
+func newChatExtractCmd() *cobra.Command {
+	var (
+		turn       int
+		root       string
+		patch      bool
+		write      bool
+		yes        bool
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "extract <file>",
+		Short: "Extract code blocks from an LLM response as files or a patch",
+		Long: `Finds the fenced code blocks of an LLM turn (--turn, default the last
+response) and the file each one targets. The target comes from the fence
+info ("go cmd/a.go", "go:cmd/a.go", title="cmd/a.go") or from a line naming
+the file just above the block ("cmd/a.go", "**` + "`cmd/a.go`" + `**", "File: cmd/a.go").
+Diff blocks are kept as patches. @a: aliases resolve through the workspace
+aliases, and other paths against --root (default: the current directory).
+No target may climb out of its alias directory or, for other paths, out of
+--root.
+
+By default the blocks are listed. --patch prints a unified patch for
+'git apply' in --root, leaving out alias targets in other workspaces;
+--write writes the files and applies the diffs, asking for each one unless
+--yes is given. Files outside --root are asked about even with --yes.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if patch && write {
+				return fmt.Errorf("--patch and --write are mutually exclusive")
+			}
+			content, err := os.ReadFile(args[0]) //nolint:gosec // user-specified job file
+			if err != nil {
+				return fmt.Errorf("read job file: %w", err)
+			}
+			if root == "" {
+				if root, err = os.Getwd(); err != nil {
+					return err
+				}
+			}
+			if root, err = filepath.Abs(root); err != nil {
+				return err
+			}
+
+			turns, err := selectTurns(parseChatDocument(content), turn)
+			if err != nil {
+				return err
+			}
+			resolver := &blockResolver{root: root, rules: newRuleResolver(root)}
+			blocks := []extractBlock{}
+			for _, t := range turns {
+				for _, blk := range extractBlocks(t.Index, t.Content) {
+					if blk.Path != "" && blk.Kind == "file" {
+						if blk.Target, err = resolver.resolve(blk.Path); err != nil {
+							blk.Error = err.Error()
+						} else {
+							blk.OutsideRoot = !within(root, blk.Target)
+						}
+					}
+					blocks = append(blocks, blk)
+				}
+			}
+
+			switch {
+			case patch:
+				out, err := buildPatch(root, blocks)
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprint(os.Stdout, out)
+				return err
+			case write:
+				return applyBlocks(root, blocks, yes)
+			case jsonOutput:
+				return json.NewEncoder(os.Stdout).Encode(blocks)
+			}
+			var b bytes.Buffer
+			for _, blk := range blocks {
+				target := blk.Target
+				switch {
+				case blk.Kind == "diff":
+					target = "patch " + firstNonEmpty(blk.Path, "(no path)")
+				case blk.Error != "":
+					target = blk.Path + " (" + blk.Error + ")"
+				case target == "":
+					target = "(no target)"
+				case blk.OutsideRoot:
+					target += " (outside root)"
+				}
+				fmt.Fprintf(&b, "turn %d block %d  %-6s %s\n", blk.Turn, blk.Index, firstNonEmpty(blk.Lang, "-"), target)
+			}
+			_, err = os.Stdout.Write(b.Bytes())
+			return err
+		},
+	}
+
+	cmd.Flags().IntVar(&turn, "turn", 0, "LLM turn to extract from (default: the last response)")
+	cmd.Flags().StringVar(&root, "root", "", "Directory relative paths resolve against (default: cwd)")
+	cmd.Flags().BoolVar(&patch, "patch", false, "Print a unified patch for git apply")
+	cmd.Flags().BoolVar(&write, "write", false, "Write files and apply diffs")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask before writing")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "List the blocks as JSON")
+
+	return cmd
+}
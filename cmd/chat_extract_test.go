@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractBlocksDetectsTargets(t *testing.T) {
+	content := "Update the entry point:\n\n**`cmd/main.go`**\n```go\npackage main\n```\n\n" +
+		"```go title=\"pkg/a.go\"\npackage a\n```\n\n" +
+		"```yaml:config/app.yml\nkey: v\n```\n\n" +
+		"```diff\n--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-old\n+new\n```\n\n" +
+		"Run it like this:\n```sh\ngo run .\n```\n"
+
+	blocks := extractBlocks(2, content)
+	require.Len(t, blocks, 5)
+	assert.Equal(t, "cmd/main.go", blocks[0].Path, "path line above the block")
+	assert.Equal(t, "pkg/a.go", blocks[1].Path, "fence attribute")
+	assert.Equal(t, "config/app.yml", blocks[2].Path, "lang:path fence")
+	assert.Equal(t, "yaml", blocks[2].Lang)
+	assert.Equal(t, "diff", blocks[3].Kind)
+	assert.Equal(t, "README.md", blocks[3].Path, "diffs name their target")
+	assert.Empty(t, blocks[4].Path, "prose is not a path")
+}
+
+func TestExtractBlocksNestedFences(t *testing.T) {
+	readme := "# Usage\n\n```sh\nmake build\n```\n"
+	content := "**`docs/usage.md`**\n````markdown\n" + readme + "````\n\nThen:\n```sh\nmake docs\n```\n"
+
+	blocks := extractBlocks(2, content)
+	require.Len(t, blocks, 2, "the inner ```sh fence does not end the markdown block")
+	assert.Equal(t, "docs/usage.md", blocks[0].Path)
+	assert.Equal(t, "markdown", blocks[0].Lang)
+	assert.Equal(t, strings.TrimSuffix(readme, "\n"), blocks[0].Content)
+	assert.Equal(t, "sh", blocks[1].Lang)
+	assert.Equal(t, "make docs", blocks[1].Content)
+}
+
+func TestBlockResolverStaysInRoot(t *testing.T) {
+	root := t.TempDir()
+	r := &blockResolver{root: root, rules: newRuleResolver(root)}
+
+	target, err := r.resolve("cmd/main.go")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "cmd", "main.go"), target)
+
+	_, err = r.resolve("../escape.go")
+	assert.ErrorContains(t, err, "outside")
+
+	target, err = r.resolve(filepath.Join(root, "pkg", "a.go"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "pkg", "a.go"), target)
+	_, err = r.resolve(filepath.Join(filepath.Dir(root), "escape.go"))
+	assert.ErrorContains(t, err, "outside", "absolute paths are held to root too")
+}
+
+func TestWithin(t *testing.T) {
+	assert.True(t, within("/ws/core", "/ws/core"))
+	assert.True(t, within("/ws/core", "/ws/core/pkg/a.go"))
+	assert.False(t, within("/ws/core", filepath.Join("/ws/core", "x/../../..")), "the shape of an escaping alias target")
+	assert.False(t, within("/ws/core", "/ws/core-other/a.go"))
+	assert.True(t, within("/ws/core", "/ws/core/..hidden"))
+}
+
+func TestBuildPatch(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "old.txt"), []byte("one\ntwo\n"), 0o600))
+
+	patch, err := buildPatch(root, []extractBlock{
+		{Kind: "file", Target: filepath.Join(root, "old.txt"), Content: "one\nthree"},
+		{Kind: "file", Target: filepath.Join(root, "dir", "new.txt"), Content: "fresh\n"},
+		{Kind: "file", Content: "no target"},
+		{Kind: "file", Target: filepath.Join(t.TempDir(), "other.txt"), OutsideRoot: true, Content: "elsewhere\n"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, patch, "diff --git a/old.txt b/old.txt\n")
+	assert.Contains(t, patch, "--- a/old.txt\n+++ b/old.txt\n")
+	assert.Contains(t, patch, "-two\n+three\n")
+	assert.Contains(t, patch, "diff --git a/dir/new.txt b/dir/new.txt\n")
+	assert.Contains(t, patch, "--- /dev/null\n+++ b/dir/new.txt\n")
+	assert.NotContains(t, patch, "no target")
+	assert.NotContains(t, patch, "elsewhere", "targets outside root are left out")
+	assert.False(t, strings.Contains(patch, os.TempDir()+"/grove-extract-"), "temporary paths are rewritten")
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// jobNumberRe matches the numeric prefix flow gives job files ("03-chat.md").
+var jobNumberRe = regexp.MustCompile(`^(\d+)-(.*)$`)
+
+// forkResult describes a forked chat.
+type forkResult struct {
+	File   string `json:"file"`
+	ID     string `json:"id"`
+	Parent string `json:"parent"`
+	AtTurn int    `json:"atTurn"`
+	// Listed is whether flow's plan status shows the fork; nil when flow
+	// could not be asked.
+	Listed *bool `json:"listed,omitempty"`
+}
+
+// newDirectiveID returns a random id for a response directive or job.
+func newDirectiveID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand does not fail on supported platforms
+	}
+	return hex.EncodeToString(b)
+}
+
+// slugify lowercases s and collapses everything but letters and digits to
+// single dashes.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// nextJobFile picks a file name for a new job in dir: the next number after
+// the plan's highest job number, at the same width, or the bare slug when
+// the plan's jobs are not numbered. Existing names are never reused.
+func nextJobFile(dir, slug string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	highest, width := -1, 2
+	for _, e := range entries {
+		m := jobNumberRe.FindStringSubmatch(e.Name())
+		if m == nil || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		if n, err := strconv.Atoi(m[1]); err == nil && n > highest {
+			highest, width = n, max(width, len(m[1]))
+		}
+	}
+	base := slug
+	if highest >= 0 {
+		base = fmt.Sprintf("%0*d-%s", width, highest+1, slug)
+	}
+	name := base + ".md"
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(dir, name)); os.IsNotExist(err) {
+			return filepath.Join(dir, name), nil
+		}
+		name = fmt.Sprintf("%s-%d.md", base, i)
+	}
+}
+
+// setFrontmatterField sets key on a YAML mapping, appending it when absent,
+// so hand-written frontmatter keeps its order and comments.
+func setFrontmatterField(m *yaml.Node, key, value string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+			return
+		}
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: value})
+}
+
+// removeFrontmatterField deletes key from a YAML mapping.
+func removeFrontmatterField(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// frontmatterField returns a scalar field of a YAML mapping.
+func frontmatterField(m *yaml.Node, key string) string {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+// parseFrontmatterNode decodes raw frontmatter into its mapping node. Empty
+// frontmatter yields an empty mapping.
+func parseFrontmatterNode(raw []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("frontmatter is not a mapping")
+	}
+	return doc.Content[0], nil
+}
+
+// renderFrontmatter encodes a mapping back into a "---" block.
+func renderFrontmatter(m *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if len(m.Content) > 0 {
+		if err := enc.Encode(m); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteString("---\n")
+	return buf.Bytes(), nil
+}
+
+// planStatusListsJob reports whether a `flow plan status --json` report
+// includes the job with the given id.
+func planStatusListsJob(status []byte, id string) bool {
+	var raw any
+	if err := json.Unmarshal(status, &raw); err != nil {
+		return false
+	}
+	found := false
+	walkIDObjects(raw, func(jobID string, _ map[string]any) {
+		found = found || jobID == id
+	})
+	return found
+}
+
+// checkForkListed asks flow whether it picked up the fork. Flow has no
+// registration step for jobs: a plan is the job files in its directory,
+// discovered by their frontmatter, so this only confirms the fork's file
+// was found.
+func checkForkListed(result *forkResult) {
+	status, err := flowPlanStatus(filepath.Dir(result.File))
+	if err != nil {
+		chatLog.Debug("Could not confirm the fork with flow").Err(err).Field("file", result.File).Emit()
+		return
+	}
+	listed := planStatusListsJob(status, result.ID)
+	result.Listed = &listed
+}
+
+// forkChat writes a copy of jobFile truncated after turn atTurn to a new job
+// in the same plan. Response directives get fresh ids, and the frontmatter
+// gets a new job id plus a link back to the parent.
+func forkChat(jobFile string, atTurn int, name string) (*forkResult, error) {
+	content, err := os.ReadFile(jobFile) //nolint:gosec // user-specified job file
+	if err != nil {
+		return nil, fmt.Errorf("read job file: %w", err)
+	}
+	doc := parseChatDocument(content)
+	if atTurn < 1 || atTurn > len(doc.Turns) {
+		return nil, fmt.Errorf("turn %d out of range: %s has %d turns", atTurn, filepath.Base(jobFile), len(doc.Turns))
+	}
+	last := doc.Turns[atTurn-1]
+	if last.Running() {
+		return nil, fmt.Errorf("turn %d is still running", atTurn)
+	}
+
+	front, err := parseFrontmatterNode(doc.Front)
+	if err != nil {
+		return nil, err
+	}
+	parentName := filepath.Base(jobFile)
+	parentStem := strings.TrimSuffix(parentName, filepath.Ext(parentName))
+	if m := jobNumberRe.FindStringSubmatch(parentStem); m != nil {
+		parentStem = m[2]
+	}
+	if name == "" {
+		name = parentStem + "-fork"
+	}
+	slug := slugify(name)
+	if slug == "" {
+		return nil, fmt.Errorf("fork name %q has no usable characters", name)
+	}
+
+	id := slug + "-" + newDirectiveID()
+	parentID := frontmatterField(front, "id")
+	title := frontmatterField(front, "title")
+	if title == "" {
+		title = parentStem
+	}
+	status := "pending_user"
+	if last.Role == "user" {
+		status = "pending"
+	}
+	setFrontmatterField(front, "id", id)
+	setFrontmatterField(front, "title", title+" (fork)")
+	setFrontmatterField(front, "status", status)
+	setFrontmatterField(front, "forked_from", parentName)
+	if parentID != "" {
+		setFrontmatterField(front, "forked_from_id", parentID)
+	}
+	setFrontmatterField(front, "forked_at_turn", strconv.Itoa(atTurn))
+
+	body := append([]string{}, doc.lines[doc.bodyStart:last.end]...)
+	for i, line := range body {
+		directive, ok := parseChatDirective(line)
+		if !ok {
+			continue
+		}
+		if _, isUser := directive["template"]; isUser {
+			continue
+		}
+		if _, isLLM := directive["id"].(string); isLLM {
+			directive["id"] = newDirectiveID()
+			encoded, err := json.Marshal(directive)
+			if err != nil {
+				return nil, err
+			}
+			body[i] = "<!-- grove: " + string(encoded) + " -->"
+		}
+	}
+	header, err := renderFrontmatter(front)
+	if err != nil {
+		return nil, err
+	}
+	data := append(header, strings.TrimRight(strings.Join(body, "\n"), "\n")+"\n"...)
+
+	path, err := nextJobFile(filepath.Dir(jobFile), slug)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, err
+	}
+	return &forkResult{File: path, ID: id, Parent: jobFile, AtTurn: atTurn}, nil
+}
+
+func newChatForkCmd() *cobra.Command {
+	var (
+		atTurn     int
+		name       string
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fork <file>",
+		Short: "Fork a chat into a new job, truncated after a turn",
+		Long: `Copies a chat job into a new job file in the same plan, keeping turns 1..N
+(--at-turn; turns are numbered in file order, the opening prompt being 1).
+
+Response directives get fresh ids so the fork's answers are its own, and the
+frontmatter gets a new job id, status, and forked_from/forked_from_id/
+forked_at_turn fields linking back to the parent. The file takes the plan's
+next job number. Flow has no separate registration for jobs — it discovers a
+plan's jobs from the job files in its directory — so the fork shows in
+'plan status' as soon as it is written. When flow is installed the fork is
+checked against 'flow plan status --json', with a warning if it is missing.
+
+Prints the new file's path, or a JSON description with --json.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobFile, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			result, err := forkChat(jobFile, atTurn, name)
+			if err != nil {
+				return err
+			}
+			checkForkListed(result)
+			if result.Listed != nil && !*result.Listed {
+				fmt.Fprintf(os.Stderr, "Warning: flow plan status does not list %s\n", filepath.Base(result.File))
+			}
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(result)
+			}
+			fmt.Println(result.File)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&atTurn, "at-turn", 0, "Last turn to keep (1-based)")
+	cmd.Flags().StringVar(&name, "name", "", "Name for the fork (default: <parent>-fork)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the fork as JSON")
+	_ = cmd.MarkFlagRequired("at-turn")
+
+	return cmd
+}
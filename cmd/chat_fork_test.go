@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestForkChat(t *testing.T) {
+	planDir := t.TempDir()
+	parent := filepath.Join(planDir, "02-refactor.md")
+	require.NoError(t, os.WriteFile(parent, []byte(strings.Replace(usageFixture, "title: Refactor", "id: refactor-1\ntitle: Refactor", 1)), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(planDir, "01-spec.md"), []byte("---\nid: spec\n---\n"), 0o600))
+
+	result, err := forkChat(parent, 3, "")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(planDir, "03-refactor-fork.md"), result.File, "next job number in the plan")
+
+	data, err := os.ReadFile(result.File)
+	require.NoError(t, err)
+	doc := parseChatDocument(data)
+	require.Len(t, doc.Turns, 3)
+	assert.Equal(t, "And the tests?", doc.Turns[2].Content)
+	assert.NotEqual(t, "a1", doc.Turns[1].ID, "response ids are fresh")
+	assert.Equal(t, 1000.0, doc.Turns[1].Directive["usage"].(map[string]any)["input_tokens"], "the rest of the directive is kept")
+
+	var front map[string]any
+	require.NoError(t, yaml.Unmarshal(doc.Front, &front))
+	assert.Equal(t, result.ID, front["id"])
+	assert.Equal(t, "Refactor (fork)", front["title"])
+	assert.Equal(t, "gpt-4o", front["model"])
+	assert.Equal(t, "pending", front["status"], "the fork ends on a user turn")
+	assert.Equal(t, "02-refactor.md", front["forked_from"])
+	assert.Equal(t, "refactor-1", front["forked_from_id"])
+	assert.Equal(t, 3, front["forked_at_turn"])
+
+	second, err := forkChat(parent, 2, "Other Idea")
+	require.NoError(t, err)
+	assert.Equal(t, "04-other-idea.md", filepath.Base(second.File))
+
+	_, err = forkChat(parent, 6, "")
+	assert.ErrorContains(t, err, "still running")
+	_, err = forkChat(parent, 7, "")
+	assert.ErrorContains(t, err, "out of range")
+}
+
+func TestCheckForkListed(t *testing.T) {
+	status := []byte(`{"plan": "refactor", "jobs": [{"id": "spec", "status": "completed"}, {"id": "refactor-fork-1a2b3c4d", "status": "pending"}]}`)
+	assert.True(t, planStatusListsJob(status, "refactor-fork-1a2b3c4d"))
+	assert.False(t, planStatusListsJob(status, "other"))
+
+	t.Setenv("PATH", t.TempDir()) // no flow: nothing to confirm against
+	result := &forkResult{File: filepath.Join(t.TempDir(), "03-refactor-fork.md"), ID: "refactor-fork-1a2b3c4d"}
+	checkForkListed(result)
+	assert.Nil(t, result.Listed)
+}
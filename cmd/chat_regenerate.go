@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// regenerateArchivePath is the side file a chat's replaced responses are
+// appended to. It lives in a hidden directory so flow does not take it for
+// a job of the plan.
+func regenerateArchivePath(jobFile string) string {
+	stem := strings.TrimSuffix(filepath.Base(jobFile), filepath.Ext(jobFile))
+	return filepath.Join(filepath.Dir(jobFile), ".archive", stem+".responses.md")
+}
+
+// dropLastResponse removes the chat's final LLM turn and queues a running
+// placeholder in its place, as :GroveChatRun does. With model set, the user
+// turn being answered gets a model key on its directive, so only this turn
+// switches. The opening prompt has no directive of its own — its model is
+// the whole chat's — so a model for it is refused. It returns the new file
+// content and the removed turn.
+func dropLastResponse(content []byte, model string, now time.Time) ([]byte, chatTurn, error) {
+	doc := parseChatDocument(content)
+	n := len(doc.Turns)
+	if n == 0 || doc.Turns[n-1].Role != "llm" {
+		return nil, chatTurn{}, fmt.Errorf("the last turn is not an LLM response; nothing to regenerate")
+	}
+	last := doc.Turns[n-1]
+	if last.Running() {
+		return nil, chatTurn{}, fmt.Errorf("the last response is still running")
+	}
+
+	lines := append([]string{}, doc.lines[:last.start]...)
+	if model != "" {
+		prompt := -1
+		for i := n - 2; i >= 0; i-- {
+			if doc.Turns[i].Role == "user" {
+				prompt = i
+				break
+			}
+		}
+		if prompt < 0 || doc.Turns[prompt].Directive == nil {
+			return nil, chatTurn{}, fmt.Errorf("the opening prompt uses the chat's model; change the frontmatter model instead of passing --model")
+		}
+		t := doc.Turns[prompt]
+		t.Directive["model"] = model
+		encoded, err := json.Marshal(t.Directive)
+		if err != nil {
+			return nil, chatTurn{}, err
+		}
+		lines[t.start] = "<!-- grove: " + string(encoded) + " -->"
+	}
+
+	text := strings.TrimRight(strings.Join(lines, "\n"), "\n \t")
+	text += fmt.Sprintf("\n\n<!-- grove: {\"id\": \"pending-%d\", \"state\": \"running\"} -->\n", now.Unix())
+	return []byte(text), last, nil
+}
+
+// archiveResponse appends a replaced response to the chat's archive file.
+func archiveResponse(jobFile string, turn chatTurn, model string, now time.Time) (string, error) {
+	path := regenerateArchivePath(jobFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // path is derived from the job file
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	heading := fmt.Sprintf("## Turn %d · %s", turn.Index, now.UTC().Format(time.RFC3339))
+	if model != "" {
+		heading += " · " + model
+	}
+	if _, err := fmt.Fprintf(f, "%s\n\n<!-- grove: %s -->\n%s\n\n", heading, mustJSON(turn.Directive), turn.Content); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func mustJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// writeFileAtomic replaces path through a temporary file in its directory,
+// so a failed write never leaves a half-written chat.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func newChatRegenerateCmd() *cobra.Command {
+	var (
+		model string
+		force bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "regenerate <file>",
+		Short: "Replace the last LLM response and resubmit the chat",
+		Long: `Removes the chat's final LLM response (the block from its directive to the
+end of the file), appends it to .archive/<name>.responses.md next to the job
+for comparison, and resubmits the chat the same way 'chat' does: through the
+daemon, falling back to 'flow run'.
+
+--model answers the turn with a different model by setting it on the user
+turn's directive. It is refused when the turn answered is the opening
+prompt, whose model is the frontmatter's and so the whole chat's. If the
+submission fails, the original file is restored and nothing is archived.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobFile, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			original, err := os.ReadFile(jobFile) //nolint:gosec // user-specified job file
+			if err != nil {
+				return fmt.Errorf("read job file: %w", err)
+			}
+			now := time.Now()
+			updated, removed, err := dropLastResponse(original, model, now)
+			if err != nil {
+				return err
+			}
+
+			responseModel, _ := removed.Directive["model"].(string)
+			if responseModel == "" {
+				var front chatFrontmatter
+				if raw, _, ok := splitFrontmatter(original); ok {
+					_ = yaml.Unmarshal(raw, &front)
+				}
+				responseModel, _ = resolveChatModel(jobFile, front)
+			}
+
+			if err := writeFileAtomic(jobFile, updated); err != nil {
+				return err
+			}
+			if err := runChat(context.Background(), jobFile, force); err != nil {
+				if restoreErr := writeFileAtomic(jobFile, original); restoreErr != nil {
+					return fmt.Errorf("%w (restoring %s also failed: %v)", err, jobFile, restoreErr)
+				}
+				return err
+			}
+
+			archive, err := archiveResponse(jobFile, removed, responseModel, now)
+			if err != nil {
+				return fmt.Errorf("chat resubmitted, but archiving the previous response failed: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Previous response archived to %s\n", archive)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&model, "model", "", "Model to answer the turn with")
+	cmd.Flags().BoolVar(&force, "force", false, "Submit even when the prompt is estimated to exceed the model's context window")
+
+	return cmd
+}
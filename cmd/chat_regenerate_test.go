@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDropLastResponse(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	chat := strings.TrimSuffix(usageFixture, "\n<!-- grove: {\"template\": \"chat\"} -->\nThanks.\n\n<!-- grove: {\"id\": \"c3\", \"state\": \"running\"} -->\n")
+
+	updated, removed, err := dropLastResponse([]byte(chat), "", now)
+	require.NoError(t, err)
+	assert.Equal(t, "b2", removed.ID)
+	assert.Equal(t, "Keep them next to the code.", removed.Content)
+	assert.True(t, strings.HasSuffix(string(updated), "And the tests?\n\n<!-- grove: {\"id\": \"pending-1700000000\", \"state\": \"running\"} -->\n"))
+
+	updated, _, err = dropLastResponse([]byte(chat), "gemini-2.5-pro", now)
+	require.NoError(t, err)
+	doc := parseChatDocument(updated)
+	assert.Equal(t, "gemini-2.5-pro", doc.Turns[2].Directive["model"], "the answered user turn switches model")
+	assert.Contains(t, string(doc.Front), "model: gpt-4o", "the rest of the chat keeps its model")
+
+	opening := "---\nmodel: gpt-4o\n---\nHello\n\n<!-- grove: {\"id\": \"a1\"} -->\nHi.\n"
+	_, _, err = dropLastResponse([]byte(opening), "gpt-5", now)
+	assert.ErrorContains(t, err, "opening prompt", "--model never rewrites the whole chat's model")
+
+	_, _, err = dropLastResponse([]byte(opening+"\n<!-- grove: {\"template\": \"chat\"} -->\nMore\n"), "", now)
+	assert.ErrorContains(t, err, "not an LLM response")
+	_, _, err = dropLastResponse([]byte(usageFixture), "", now)
+	assert.Error(t, err, "a running placeholder is not replaced")
+}
+
+func TestArchiveResponse(t *testing.T) {
+	jobFile := filepath.Join(t.TempDir(), "01-chat.md")
+	turn := chatTurn{Index: 2, ID: "a1", Directive: map[string]any{"id": "a1"}, Content: "First answer."}
+
+	path, err := archiveResponse(jobFile, turn, "gpt-4o", time.Unix(0, 0))
+	require.NoError(t, err)
+	_, err = archiveResponse(jobFile, turn, "gpt-4o", time.Unix(60, 0))
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(filepath.Dir(jobFile), ".archive", "01-chat.responses.md"), path)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "First answer."), "responses are appended")
+	assert.Contains(t, string(data), "## Turn 2 · 1970-01-01T00:00:00Z · gpt-4o")
+}
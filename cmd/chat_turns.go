@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// chatDirectiveRe matches a turn marker such as
+// <!-- grove: {"id": "a1b2c3"} --> on a line of its own.
+var chatDirectiveRe = regexp.MustCompile(`^\s*<!-- grove: (.*) -->\s*$`)
+
+// chatTurn is one turn of a chat job file. A directive with a template opens
+// a user turn and one with an id an LLM response; text before the first
+// directive is the opening user turn, which has no directive.
+type chatTurn struct {
+	Index     int            `json:"turn"` // 1-based
+	Role      string         `json:"role"` // "user" or "llm"
+	ID        string         `json:"id,omitempty"`
+	Directive map[string]any `json:"directive,omitempty"`
+	Line      int            `json:"line"` // 1-based line of the directive or first line
+	Content   string         `json:"content"`
+
+	start, end int // lines [start, end) of the file, directive included
+}
+
+// Running reports whether the turn is a placeholder for a response in
+// progress.
+func (t *chatTurn) Running() bool {
+	state, _ := t.Directive["state"].(string)
+	return state == "running"
+}
+
+// chatDocument is a parsed chat job file.
+type chatDocument struct {
+	Front []byte // raw frontmatter, nil when the file has none
+	Turns []chatTurn
+
+	lines     []string
+	bodyStart int // index of the first line after the frontmatter
+}
+
+// parseChatDocument splits a chat file into its frontmatter and turns.
+// Directives that neither open a user turn nor carry an id stay part of the
+// turn they appear in.
+func parseChatDocument(content []byte) *chatDocument {
+	doc := &chatDocument{}
+	front, body, ok := splitFrontmatter(content)
+	if ok {
+		doc.Front = front
+	}
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	doc.lines = strings.Split(text, "\n")
+	doc.bodyStart = len(doc.lines) - len(strings.Split(string(body), "\n"))
+
+	var current *chatTurn
+	closeTurn := func(end int) {
+		if current == nil {
+			return
+		}
+		current.end = end
+		first := current.start
+		if current.Directive != nil {
+			first++
+		}
+		current.Content = strings.TrimSpace(strings.Join(doc.lines[first:end], "\n"))
+		if current.Directive != nil || current.Content != "" {
+			current.Index = len(doc.Turns) + 1
+			doc.Turns = append(doc.Turns, *current)
+		}
+		current = nil
+	}
+
+	current = &chatTurn{Role: "user", start: doc.bodyStart, Line: doc.bodyStart + 1}
+	for i := doc.bodyStart; i < len(doc.lines); i++ {
+		directive, ok := parseChatDirective(doc.lines[i])
+		if !ok {
+			continue
+		}
+		var role, id string
+		if _, ok := directive["template"]; ok {
+			role = "user"
+		} else if id, _ = directive["id"].(string); id != "" {
+			role = "llm"
+		} else {
+			continue
+		}
+		closeTurn(i)
+		current = &chatTurn{Role: role, ID: id, Directive: directive, Line: i + 1, start: i}
+	}
+	closeTurn(len(doc.lines))
+	return doc
+}
+
+func parseChatDirective(line string) (map[string]any, bool) {
+	m := chatDirectiveRe.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false
+	}
+	var directive map[string]any
+	if err := json.Unmarshal([]byte(m[1]), &directive); err != nil {
+		return nil, false
+	}
+	return directive, true
+}
+
+// text returns lines [start, end) of the file.
+func (d *chatDocument) text(start, end int) string {
+	return strings.Join(d.lines[start:end], "\n")
+}
@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// turnUsage is the token usage and cost of one LLM turn.
+type turnUsage struct {
+	Turn         int         `json:"turn"`
+	ID           string      `json:"id"`
+	Model        string      `json:"model,omitempty"`
+	InputTokens  int         `json:"inputTokens"`
+	OutputTokens int         `json:"outputTokens"`
+	Source       string      `json:"source"`     // usageDirective, usageJobRecord or usageEstimate
+	Estimated    bool        `json:"estimated"`  // counts were estimated, not recorded
+	Cost         float64     `json:"cost"`       // USD; 0 when Priced is false
+	Priced       bool        `json:"priced"`     // the model has known pricing
+	Cumulative   usageTotals `json:"cumulative"` // totals up to and including this turn
+}
+
+// usageTotals sums token usage and cost.
+type usageTotals struct {
+	InputTokens  int     `json:"inputTokens"`
+	OutputTokens int     `json:"outputTokens"`
+	Cost         float64 `json:"cost"`
+	Estimated    bool    `json:"estimated"` // some turn was estimated
+	Unpriced     int     `json:"unpriced"`  // turns whose model has no pricing
+}
+
+func (t *usageTotals) add(u turnUsage) {
+	t.InputTokens += u.InputTokens
+	t.OutputTokens += u.OutputTokens
+	t.Cost += u.Cost
+	t.Estimated = t.Estimated || u.Estimated
+	if !u.Priced {
+		t.Unpriced++
+	}
+}
+
+func (t *usageTotals) merge(o usageTotals) {
+	t.InputTokens += o.InputTokens
+	t.OutputTokens += o.OutputTokens
+	t.Cost += o.Cost
+	t.Estimated = t.Estimated || o.Estimated
+	t.Unpriced += o.Unpriced
+}
+
+// chatUsage is the usage report for one chat file.
+type chatUsage struct {
+	File  string      `json:"file"`
+	Turns []turnUsage `json:"turns"`
+	Total usageTotals `json:"total"`
+}
+
+// planUsage is the combined usage of the chats in a plan directory.
+type planUsage struct {
+	Dir   string      `json:"dir"`
+	Jobs  int         `json:"jobs"`
+	Total usageTotals `json:"total"`
+}
+
+// usageReport is the output of `chat usage`.
+type usageReport struct {
+	chatUsage
+	Plan *planUsage `json:"plan,omitempty"`
+}
+
+// Where a turn's token counts came from.
+const (
+	usageDirective = "directive" // recorded on the response directive
+	usageJobRecord = "job"       // flow's record of the job that wrote the response
+	usageEstimate  = "estimate"  // estimated from the chat text
+)
+
+// tokenCounts is the recorded usage of one response.
+type tokenCounts struct {
+	Input, Output int
+}
+
+// recordedUsage reads token counts flow recorded on a response directive,
+// either under "usage" or at the top level, in Anthropic or OpenAI naming.
+func recordedUsage(directive map[string]any) (input, output int, ok bool) {
+	src := directive
+	if u, isMap := directive["usage"].(map[string]any); isMap {
+		src = u
+	}
+	in, inOK := numberField(src, "input_tokens", "prompt_tokens", "inputTokens")
+	out, outOK := numberField(src, "output_tokens", "completion_tokens", "outputTokens")
+	if !inOK && !outOK {
+		return 0, 0, false
+	}
+	return int(in), int(out), true
+}
+
+// jobUsageRecords asks flow for the plan's job records and collects the
+// token usage recorded for each response, keyed by the response id. Flow's
+// report nests responses differently across versions, so every object with
+// an "id" and usage fields counts. Without flow there are no records and
+// turns fall back to estimates.
+func jobUsageRecords(planDir string) map[string]tokenCounts {
+	out, err := flowPlanStatus(planDir)
+	if err != nil {
+		chatLog.Debug("Failed to read flow job records").Err(err).Field("plan_dir", planDir).Emit()
+		return nil
+	}
+	return parseJobUsageRecords(out)
+}
+
+func parseJobUsageRecords(data []byte) map[string]tokenCounts {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	records := make(map[string]tokenCounts)
+	walkIDObjects(raw, func(id string, obj map[string]any) {
+		if in, out, ok := recordedUsage(obj); ok {
+			records[id] = tokenCounts{Input: in, Output: out}
+		}
+	})
+	return records
+}
+
+// walkIDObjects calls fn for every JSON object with a string "id", at any
+// depth of a decoded flow report.
+func walkIDObjects(v any, fn func(id string, obj map[string]any)) {
+	switch v := v.(type) {
+	case map[string]any:
+		if id, _ := v["id"].(string); id != "" {
+			fn(id, v)
+		}
+		for _, child := range v {
+			walkIDObjects(child, fn)
+		}
+	case []any:
+		for _, child := range v {
+			walkIDObjects(child, fn)
+		}
+	}
+}
+
+// computeChatUsage walks the LLM turns of a chat file. Counts come from the
+// response directive, then from records (flow's job records by response
+// id). Turns with neither are estimated: the input is everything above the
+// turn plus contextTokens from the job's rules, the output is the turn
+// itself. Responses still running are skipped.
+func computeChatUsage(jobFile string, content []byte, records map[string]tokenCounts, contextTokens int) *chatUsage {
+	var front chatFrontmatter
+	doc := parseChatDocument(content)
+	if doc.Front != nil {
+		_ = yaml.Unmarshal(doc.Front, &front)
+	}
+	defaultModel, _ := resolveChatModel(jobFile, front)
+
+	usage := &chatUsage{File: jobFile, Turns: []turnUsage{}}
+	for _, t := range doc.Turns {
+		if t.Role != "llm" || t.Running() {
+			continue
+		}
+		u := turnUsage{Turn: t.Index, ID: t.ID, Model: defaultModel}
+		if m, _ := t.Directive["model"].(string); m != "" {
+			u.Model = m
+		}
+		if in, out, ok := recordedUsage(t.Directive); ok {
+			u.Source = usageDirective
+			u.InputTokens, u.OutputTokens = in, out
+		} else if rec, ok := records[t.ID]; ok {
+			u.Source = usageJobRecord
+			u.InputTokens, u.OutputTokens = rec.Input, rec.Output
+		} else {
+			u.Source = usageEstimate
+			u.Estimated = true
+			u.InputTokens = contextTokens + estimateTokens([]byte(doc.text(doc.bodyStart, t.start)))
+			u.OutputTokens = estimateTokens([]byte(t.Content))
+		}
+		if info, _ := lookupModel(u.Model); info.Pricing != nil {
+			u.Priced = true
+			u.Cost = info.Pricing.Cost(u.InputTokens, u.OutputTokens)
+		}
+		usage.Total.add(u)
+		u.Cumulative = usage.Total
+		usage.Turns = append(usage.Turns, u)
+	}
+	return usage
+}
+
+// usageSources memoizes what pricing a chat needs beyond the file itself:
+// the context size of each rules file and flow's job records for each plan.
+// A plan's chats usually share one rules file, so --plan sizes it once.
+type usageSources struct {
+	cache   *tokenCache
+	context map[string]int                    // rules file -> context tokens
+	records map[string]map[string]tokenCounts // plan dir -> response id -> counts
+}
+
+func newUsageSources(cache *tokenCache) *usageSources {
+	return &usageSources{
+		cache:   cache,
+		context: make(map[string]int),
+		records: make(map[string]map[string]tokenCounts),
+	}
+}
+
+// contextTokens sizes the rules context of a job. A rules file that cannot
+// be evaluated counts as empty.
+func (s *usageSources) contextTokens(jobFile string, front chatFrontmatter) int {
+	rulesFile := jobRulesFile(jobFile, front)
+	if rulesFile == "" {
+		return 0
+	}
+	if tokens, ok := s.context[rulesFile]; ok {
+		return tokens
+	}
+	tokens := 0
+	if contributors, err := rulesContext(rulesFile, s.cache); err == nil {
+		for _, c := range contributors {
+			tokens += c.Tokens
+		}
+	}
+	s.context[rulesFile] = tokens
+	return tokens
+}
+
+// planRecords returns flow's job records for a plan, asking flow once.
+func (s *usageSources) planRecords(planDir string) map[string]tokenCounts {
+	if records, ok := s.records[planDir]; ok {
+		return records
+	}
+	records := jobUsageRecords(planDir)
+	s.records[planDir] = records
+	return records
+}
+
+// chatFileUsage computes the usage of a chat file. Flow and the rules
+// context are only consulted when some turn has no counts on its directive.
+func chatFileUsage(jobFile string, src *usageSources) (*chatUsage, error) {
+	content, err := os.ReadFile(jobFile) //nolint:gosec // user-specified job file
+	if err != nil {
+		return nil, fmt.Errorf("read job file: %w", err)
+	}
+	var records map[string]tokenCounts
+	contextTokens := 0
+	if needsUsageLookup(content) {
+		var front chatFrontmatter
+		if raw, _, ok := splitFrontmatter(content); ok {
+			_ = yaml.Unmarshal(raw, &front)
+		}
+		records = src.planRecords(filepath.Dir(jobFile))
+		contextTokens = src.contextTokens(jobFile, front)
+	}
+	return computeChatUsage(jobFile, content, records, contextTokens), nil
+}
+
+// needsUsageLookup reports whether a chat has a finished response without
+// counts on its directive.
+func needsUsageLookup(content []byte) bool {
+	for _, t := range parseChatDocument(content).Turns {
+		if t.Role != "llm" || t.Running() {
+			continue
+		}
+		if _, _, ok := recordedUsage(t.Directive); !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// computePlanUsage sums the usage of every markdown job in a plan directory.
+func computePlanUsage(dir string, src *usageSources) (*planUsage, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	plan := &planUsage{Dir: dir}
+	for _, f := range files {
+		u, err := chatFileUsage(f, src)
+		if err != nil || len(u.Turns) == 0 {
+			continue
+		}
+		plan.Jobs++
+		plan.Total.merge(u.Total)
+	}
+	return plan, nil
+}
+
+func newChatUsageCmd() *cobra.Command {
+	var (
+		jsonOutput bool
+		plan       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "usage <file>",
+		Short: "Report token usage and cost of a chat's LLM turns",
+		Long: `Walks the LLM turns of a chat job (the directives with an id) and reports
+per-turn and cumulative input/output tokens, priced with the model catalog.
+
+Counts come from the usage recorded on each response directive, then from
+flow's job records for the plan ('flow plan status --json'), matched by
+response id. Turns with neither are estimated from the text above the turn
+and the job's rules context; their "source" is "estimate" and "estimated" is
+true in the JSON. --plan adds totals across every chat in the
+job's plan directory.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache := loadTokenCache()
+			defer cache.Save()
+
+			jobFile, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			src := newUsageSources(cache)
+			usage, err := chatFileUsage(jobFile, src)
+			if err != nil {
+				return err
+			}
+			report := usageReport{chatUsage: *usage}
+			if plan {
+				if report.Plan, err = computePlanUsage(filepath.Dir(jobFile), src); err != nil {
+					return err
+				}
+			}
+
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(report)
+			}
+			printChatUsage(report)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output usage as JSON")
+	cmd.Flags().BoolVar(&plan, "plan", false, "Include totals for every chat in the plan")
+
+	return cmd
+}
+
+func printChatUsage(r usageReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TURN\tMODEL\tINPUT\tOUTPUT\tCOST")
+	for _, t := range r.Turns {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", t.Turn, t.Model,
+			usageTokens(t.InputTokens, t.Estimated), usageTokens(t.OutputTokens, t.Estimated),
+			usageCost(t.Cost, t.Priced))
+	}
+	_ = w.Flush()
+	fmt.Println(usageSummary("Total", r.Total))
+	if r.Plan != nil {
+		fmt.Println(usageSummary(fmt.Sprintf("Plan (%d jobs)", r.Plan.Jobs), r.Plan.Total))
+	}
+}
+
+func usageTokens(n int, estimated bool) string {
+	if estimated {
+		return "~" + fmt.Sprint(n)
+	}
+	return fmt.Sprint(n)
+}
+
+func usageCost(cost float64, priced bool) string {
+	if !priced {
+		return "-"
+	}
+	return fmt.Sprintf("$%.4f", cost)
+}
+
+func usageSummary(label string, t usageTotals) string {
+	var notes []string
+	if t.Estimated {
+		notes = append(notes, "includes estimates")
+	}
+	if t.Unpriced > 0 {
+		notes = append(notes, fmt.Sprintf("%d unpriced", t.Unpriced))
+	}
+	s := fmt.Sprintf("%s: %d in, %d out, $%.4f", label, t.InputTokens, t.OutputTokens, t.Cost)
+	if len(notes) > 0 {
+		s += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return s
+}
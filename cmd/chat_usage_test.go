@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const usageFixture = `---
+title: Refactor
+model: gpt-4o
+---
+How should I split this package?
+
+<!-- grove: {"id": "a1", "usage": {"input_tokens": 1000, "output_tokens": 200}} -->
+Split it by layer.
+
+<!-- grove: {"template": "chat"} -->
+And the tests?
+
+<!-- grove: {"id": "b2", "model": "claude-sonnet-4-5"} -->
+Keep them next to the code.
+
+<!-- grove: {"template": "chat"} -->
+Thanks.
+
+<!-- grove: {"id": "c3", "state": "running"} -->
+`
+
+func TestParseChatDocument(t *testing.T) {
+	doc := parseChatDocument([]byte(usageFixture))
+	require.NotNil(t, doc.Front)
+	require.Len(t, doc.Turns, 6)
+
+	assert.Equal(t, "user", doc.Turns[0].Role)
+	assert.Nil(t, doc.Turns[0].Directive, "the opening prompt has no directive")
+	assert.Equal(t, "How should I split this package?", doc.Turns[0].Content)
+	assert.Equal(t, 5, doc.Turns[0].Line)
+
+	assert.Equal(t, "llm", doc.Turns[1].Role)
+	assert.Equal(t, "a1", doc.Turns[1].ID)
+	assert.Equal(t, "Split it by layer.", doc.Turns[1].Content)
+	assert.Equal(t, 7, doc.Turns[1].Line)
+
+	assert.Equal(t, "user", doc.Turns[2].Role)
+	assert.True(t, doc.Turns[5].Running())
+	assert.Equal(t, 6, doc.Turns[5].Index)
+}
+
+func TestComputeChatUsage(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	jobFile := filepath.Join(t.TempDir(), "01-chat.md")
+
+	usage := computeChatUsage(jobFile, []byte(usageFixture), nil, 500)
+	require.Len(t, usage.Turns, 2, "running responses are skipped")
+
+	first := usage.Turns[0]
+	assert.False(t, first.Estimated)
+	assert.Equal(t, usageDirective, first.Source)
+	assert.Equal(t, "gpt-4o", first.Model, "frontmatter model by default")
+	assert.Equal(t, 1000, first.InputTokens)
+	assert.True(t, first.Priced)
+	assert.InDelta(t, 0.0045, first.Cost, 1e-9)
+
+	second := usage.Turns[1]
+	assert.True(t, second.Estimated)
+	assert.Equal(t, usageEstimate, second.Source)
+	assert.Equal(t, "claude-sonnet-4-5", second.Model, "directive model wins")
+	assert.Greater(t, second.InputTokens, 500, "context plus the conversation so far")
+	assert.Equal(t, estimateTokens([]byte("Keep them next to the code.")), second.OutputTokens)
+
+	assert.Equal(t, first.InputTokens+second.InputTokens, usage.Total.InputTokens)
+	assert.InDelta(t, first.Cost+second.Cost, usage.Total.Cost, 1e-9)
+	assert.Equal(t, usage.Total, second.Cumulative)
+	assert.True(t, usage.Total.Estimated)
+}
+
+func TestComputeChatUsageFromJobRecords(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	jobFile := filepath.Join(t.TempDir(), "01-chat.md")
+
+	records := parseJobUsageRecords([]byte(`{"jobs": [{"id": "job-1", "responses": [
+		{"id": "a1", "usage": {"input_tokens": 1, "output_tokens": 1}},
+		{"id": "b2", "usage": {"prompt_tokens": 3000, "completion_tokens": 400}}
+	]}]}`))
+	require.Len(t, records, 2)
+
+	usage := computeChatUsage(jobFile, []byte(usageFixture), records, 500)
+	require.Len(t, usage.Turns, 2)
+	assert.Equal(t, 1000, usage.Turns[0].InputTokens, "the directive wins over the job record")
+
+	second := usage.Turns[1]
+	assert.Equal(t, usageJobRecord, second.Source)
+	assert.False(t, second.Estimated)
+	assert.Equal(t, 3000, second.InputTokens)
+	assert.Equal(t, 400, second.OutputTokens)
+	assert.False(t, usage.Total.Estimated)
+}
+
+func TestUsageSourcesSizeEachRulesFileOnce(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".grove/rules":      "*.go\n",
+		"main.go":           "package main // 24 chars",
+		"plan/01-chat.md":   "hello",
+		"plan/02-review.md": "hello",
+	})
+	src := newUsageSources(&tokenCache{entries: make(map[string]tokenCacheEntry)})
+
+	first := src.contextTokens(filepath.Join(root, "plan", "01-chat.md"), chatFrontmatter{})
+	assert.Equal(t, 6, first)
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".grove", "rules"), []byte("missing/**\n"), 0o600))
+	assert.Equal(t, first, src.contextTokens(filepath.Join(root, "plan", "02-review.md"), chatFrontmatter{}),
+		"the second job reuses the first sizing")
+}
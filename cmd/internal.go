@@ -5,22 +5,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
-	"path/filepath"
 	"runtime"
-	"sort"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/grovetools/core/config"
 	grovelogging "github.com/grovetools/core/logging"
 	"github.com/grovetools/core/pkg/daemon"
 	"github.com/grovetools/core/pkg/models"
 	"github.com/grovetools/core/pkg/workspace"
 	"github.com/grovetools/core/util/pathutil"
-	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -84,7 +79,7 @@ func newInternalCmd() *cobra.Command {
 	}
 	cmd.AddCommand(newResolveAliasesCmd())
 	cmd.AddCommand(newStreamStateCmd())
-	cmd.AddCommand(newThemeCmd())
+	cmd.AddCommand(newInternalThemeCmd())
 	return cmd
 }
 
@@ -310,126 +305,21 @@ func newResolveAliasesCmd() *cobra.Command {
 		Short: "Converts a list of absolute file paths to workspace-relative aliases",
 		Long:  `Reads absolute file paths from stdin (one per line) and outputs a JSON map of original paths to their aliased versions.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Initialize workspace provider for fast lookups.
-			// Suppress noisy discovery logs by redirecting logger output.
-			logger := logrus.New()
-			logger.SetOutput(io.Discard)
-			discoveryService := workspace.NewDiscoveryService(logger)
-			discoveryResult, err := discoveryService.DiscoverAll()
+			aliaser, err := newPathAliaser()
 			if err != nil {
-				return fmt.Errorf("failed to discover workspaces: %w", err)
+				return err
 			}
-			provider := workspace.NewProvider(discoveryResult)
 
 			// Read paths from stdin
 			scanner := bufio.NewScanner(os.Stdin)
 			results := make(map[string]string)
 
-			// --- Start Notebook Alias Generation Logic ---
-			coreCfg, err := config.LoadDefault()
-			if err != nil {
-				ulog.Warn("Could not load grove config for notebook aliases").
-					Err(err).
-					Emit()
-			}
-
-			type processedNotebook struct {
-				Name    string
-				RootDir string
-			}
-			var processedNotebooks []processedNotebook
-			if coreCfg != nil && coreCfg.Notebooks != nil && coreCfg.Notebooks.Definitions != nil {
-				for name, nbConfig := range coreCfg.Notebooks.Definitions {
-					if nbConfig.RootDir != "" {
-						expandedRoot, err := pathutil.Expand(nbConfig.RootDir)
-						if err == nil {
-							processedNotebooks = append(processedNotebooks, processedNotebook{Name: name, RootDir: expandedRoot})
-						}
-					}
-				}
-				// Sort by root dir length descending to match the most specific (longest) path first.
-				sort.Slice(processedNotebooks, func(i, j int) bool {
-					return len(processedNotebooks[i].RootDir) > len(processedNotebooks[j].RootDir)
-				})
-			}
-			// --- End Notebook Alias Generation Logic ---
-
 			for scanner.Scan() {
 				path := scanner.Text()
 				if path == "" {
 					continue
 				}
-
-				// Check if this path is inside a notebook root
-				aliasGenerated := false
-				if len(processedNotebooks) > 0 {
-					for _, nb := range processedNotebooks {
-						// Check if the file path is within this notebook's root dir.
-						if strings.HasPrefix(path, nb.RootDir) {
-							// Ensure it's a directory boundary to prevent partial matches (e.g., /path/to/nb vs /path/to/nb-plus).
-							if len(path) == len(nb.RootDir) || (len(path) > len(nb.RootDir) && path[len(nb.RootDir)] == os.PathSeparator) {
-								relPath, err := filepath.Rel(nb.RootDir, path)
-								if err == nil {
-									var alias string
-									// For the "default" notebook, omit the name for a cleaner alias and backward compatibility.
-									if nb.Name == "default" {
-										alias = fmt.Sprintf("@a:nb:%s", filepath.ToSlash(relPath))
-									} else {
-										alias = fmt.Sprintf("@a:nb:%s:%s", nb.Name, filepath.ToSlash(relPath))
-									}
-									results[path] = alias
-									aliasGenerated = true
-									break // Found the best match, stop iterating notebooks.
-								}
-							}
-						}
-					}
-				}
-				if aliasGenerated {
-					continue // Go to the next file path.
-				}
-
-				// Find the most specific workspace containing this path
-				// Use case-insensitive matching on macOS/Windows
-				node := findWorkspaceByPath(provider, path, discoveryResult)
-
-				if node != nil {
-					// Found a containing workspace, create the alias
-					// On case-insensitive filesystems, normalize paths for consistent comparison
-					basePathNormalized, err := pathutil.NormalizeForLookup(node.Path)
-					if err != nil {
-						basePathNormalized = node.Path
-					}
-					filePathNormalized, err := pathutil.NormalizeForLookup(path)
-					if err != nil {
-						filePathNormalized = path
-					}
-
-					// If paths match on the normalized prefix, use the workspace's actual case
-					// to maintain consistency
-					if strings.HasPrefix(filePathNormalized, basePathNormalized) {
-						// Replace the matching prefix with the workspace's case
-						filePathNormalized = node.Path + path[len(node.Path):]
-					}
-
-					relativePath, err := filepath.Rel(node.Path, filePathNormalized)
-					if err != nil {
-						// Fallback to absolute path on error
-						results[path] = path
-						continue
-					}
-
-					// Use the node's canonical identifier, replacing underscores with colons
-					// to create a resolvable, namespaced alias.
-					// e.g., "my-ecosystem_feature_sub-project" -> "my-ecosystem:feature:sub-project"
-					aliasPart := node.Identifier(":")
-
-					alias := fmt.Sprintf("@a:%s/%s", aliasPart, filepath.ToSlash(relativePath))
-					results[path] = alias
-				} else {
-					// No containing workspace found, use the original absolute path
-					results[path] = path
-				}
+				results[path] = aliaser.Alias(path)
 			}
 
 			if err := scanner.Err(); err != nil {
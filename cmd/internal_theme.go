@@ -17,6 +17,12 @@ func resolveThemeName() string {
 	return theme.CurrentName()
 }
 
+// themeRegistryNames lists every theme registered with core's theme
+// registry, so `theme audit --all` follows the installed core.
+func themeRegistryNames() []string {
+	return theme.Names()
+}
+
 // buildThemePayload assembles the same wire shape the daemon broadcasts for
 // theme_changed events (and stamps on initial snapshots), so the Lua side
 // parses ONE payload shape for both the initial synchronous load and live
@@ -26,27 +32,89 @@ func buildThemePayload(name string) (*coredaemon.ThemeChangedPayload, bool) {
 	return coredaemon.BuildThemePayload(name)
 }
 
-func newThemeCmd() *cobra.Command {
-	return &cobra.Command{
+// resolvedThemePayload builds the payload for name, falling back to the
+// default theme for an unknown/renamed theme in config, matching core's
+// resolveThemeColors behavior.
+func resolvedThemePayload(name string) (*coredaemon.ThemeChangedPayload, error) {
+	payload, ok := buildThemePayload(name)
+	if !ok {
+		payload, ok = buildThemePayload(theme.DefaultThemeName)
+	}
+	if !ok {
+		return nil, fmt.Errorf("theme registry has no palette for %q", name)
+	}
+	return payload, nil
+}
+
+// loadThemePayload is resolvedThemePayload decoded into the local mirror of
+// the wire shape, for the commands that read individual palette roles.
+func loadThemePayload(name string) (*themePayload, error) {
+	if p, ok := lookupThemePayload(name); ok {
+		return p, nil
+	}
+	if p, ok := lookupThemePayload(theme.DefaultThemeName); ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("theme registry has no palette for %q", name)
+}
+
+// lookupThemePayload decodes buildThemePayload's result without the default
+// fallback, for callers that must not mistake one theme for another.
+func lookupThemePayload(name string) (*themePayload, bool) {
+	payload, ok := buildThemePayload(name)
+	if !ok {
+		return nil, false
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, false
+	}
+	var p themePayload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, false
+	}
+	return &p, true
+}
+
+func newInternalThemeCmd() *cobra.Command {
+	var watch bool
+
+	cmd := &cobra.Command{
 		Use:   "theme",
 		Short: "Print the resolved current theme as JSON (both appearances)",
 		Long: `Resolves the current grove theme (GROVE_THEME env var, then config
 tui.theme, then the default) and prints its fully derived palettes for both
 appearances as a single JSON object. The shape is identical to the daemon's
-theme_changed SSE payload so consumers parse one shape everywhere.`,
+theme_changed SSE payload so consumers parse one shape everywhere.
+
+With --watch the command keeps running, watching the grove config files and
+printing a new payload line whenever the effective theme changes. This is
+the daemon-less path for live updates; GROVE_THEME is fixed for the life of
+the process, so it pins the theme here as everywhere else.`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			name := resolveThemeName()
-			payload, ok := buildThemePayload(name)
-			if !ok {
-				// Unknown/renamed theme in config: fall back to the default,
-				// matching core's resolveThemeColors behavior.
-				payload, ok = buildThemePayload(theme.DefaultThemeName)
+			encoder := json.NewEncoder(os.Stdout)
+			emit := func(name string) error {
+				payload, err := resolvedThemePayload(name)
+				if err != nil {
+					return err
+				}
+				return encoder.Encode(payload)
+			}
+			if !watch {
+				return emit(resolveThemeName())
 			}
-			if !ok {
-				return fmt.Errorf("theme registry has no palette for %q", name)
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
 			}
-			return json.NewEncoder(os.Stdout).Encode(payload)
+			listFiles := func() []string { return themeConfigFiles(cwd) }
+			return watchTheme(cmd.Context(), themeWatchInterval, listFiles, resolveThemeName, emit)
 		},
 	}
+
+	cmd.Flags().BoolVar(&watch, "watch", false, "Keep running and print a payload line whenever the theme changes")
+
+	return cmd
 }
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grovetools/core/config"
+	"github.com/grovetools/core/pkg/paths"
+)
+
+// themeWatchInterval is how often --watch polls the config files. Stat
+// polling keeps the watcher dependency-free and notices files that do not
+// exist yet, such as a global override created after startup.
+const themeWatchInterval = time.Second
+
+// themeConfigFiles lists the config files whose edits can change the
+// effective theme for dir: every layer core loads, plus the global
+// candidates that may not exist yet.
+func themeConfigFiles(dir string) []string {
+	set := make(map[string]bool)
+	if layered, err := config.LoadLayered(dir); err == nil && layered != nil {
+		for _, p := range layered.FilePaths {
+			set[p] = true
+		}
+		for _, o := range layered.Overrides {
+			set[o.Path] = true
+		}
+	}
+	if configDir := paths.ConfigDir(); configDir != "" {
+		for _, name := range []string{
+			"grove.yml", "grove.yaml", "grove.toml",
+			"grove.override.yml", "grove.override.yaml", "grove.override.toml",
+		} {
+			set[filepath.Join(configDir, name)] = true
+		}
+	}
+	if overlay := os.Getenv("GROVE_CONFIG_OVERLAY"); overlay != "" {
+		set[overlay] = true
+	}
+	if project, err := config.FindConfigFile(dir); err == nil && project != "" {
+		set[project] = true
+	}
+
+	files := make([]string, 0, len(set))
+	for p := range set {
+		if p != "" {
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// fileSignature fingerprints files by size and mtime; a missing file
+// contributes its absence, so creating or deleting one changes it.
+func fileSignature(files []string) string {
+	var b strings.Builder
+	for _, f := range files {
+		b.WriteString(f)
+		if info, err := os.Stat(f); err == nil {
+			fmt.Fprintf(&b, ":%d:%d", info.Size(), info.ModTime().UnixNano())
+		} else {
+			b.WriteString(":-")
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// watchTheme emits the theme once, then again whenever a config edit
+// changes the name resolve returns. listFiles is re-run after every edit
+// because a new layer (a project file, an override) can join the set.
+func watchTheme(ctx context.Context, interval time.Duration, listFiles func() []string, resolve func() string, emit func(name string) error) error {
+	current := resolve()
+	if err := emit(current); err != nil {
+		return err
+	}
+
+	files := listFiles()
+	sig := fileSignature(files)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		next := fileSignature(files)
+		if next == sig {
+			continue
+		}
+		files = listFiles()
+		sig = fileSignature(files)
+
+		name := resolve()
+		if name == current {
+			continue // an edit elsewhere in the config
+		}
+		ulog.Debug("Theme changed").Field("from", current).Field("to", name).Emit()
+		current = name
+		if err := emit(name); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSignature(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "grove.yml")
+
+	missing := fileSignature([]string{f})
+	require.NoError(t, os.WriteFile(f, []byte("tui:\n  theme: kanagawa\n"), 0o600))
+	created := fileSignature([]string{f})
+	assert.NotEqual(t, missing, created, "creating a watched file is a change")
+
+	require.NoError(t, os.WriteFile(f, []byte("tui:\n  theme: gruvbox-dark\n"), 0o600))
+	assert.NotEqual(t, created, fileSignature([]string{f}))
+}
+
+func TestWatchThemeEmitsOnlyWhenTheThemeChanges(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "grove.yml")
+	write := func(theme string) {
+		require.NoError(t, os.WriteFile(f, []byte(theme), 0o600))
+	}
+	write("kanagawa")
+
+	// The resolved name is whatever the file says.
+	resolve := func() string {
+		data, _ := os.ReadFile(f) //nolint:gosec // test fixture
+		return string(data)
+	}
+
+	var mu sync.Mutex
+	var emitted []string
+	emit := func(name string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		emitted = append(emitted, name)
+		return nil
+	}
+	got := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), emitted...)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- watchTheme(ctx, 5*time.Millisecond, func() []string { return []string{f} }, resolve, emit)
+	}()
+
+	require.Eventually(t, func() bool { return len(got()) == 1 }, time.Second, time.Millisecond, "the current theme is emitted on start")
+
+	// Same theme, different bytes on disk: mtime/size change, name does not.
+	time.Sleep(20 * time.Millisecond)
+	write("kanagawa")
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, []string{"kanagawa"}, got())
+
+	write("gruvbox-dark")
+	require.Eventually(t, func() bool { return len(got()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"kanagawa", "gruvbox-dark"}, got())
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newJobCmd groups the commands that work on a single job file.
+func newJobCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "job",
+		Short: "Inspect grove-flow job files",
+		Long:  "Checks job files in-process for the Neovim plugin, before they reach flow.",
+	}
+	cmd.AddCommand(newJobValidateCmd())
+	return cmd
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlErrLineRe reads the line yaml.v3 reports a syntax error on.
+var yamlErrLineRe = regexp.MustCompile(`line (\d+)`)
+
+// nameLister returns the known names of a dynamic value source ("templates",
+// "skills", "models"), and false when they cannot be listed — then values
+// from that source are not checked.
+type nameLister func(source string) (map[string]bool, bool)
+
+// jobValidator checks a job's frontmatter against the chat schema.
+type jobValidator struct {
+	schema *chatSchema
+	dir    string // the job's directory, where depends_on files live
+	names  nameLister
+}
+
+// frontmatterLine maps a line of the frontmatter block to a line of the
+// file: the block starts after the opening "---".
+func frontmatterLine(n int) int { return n + 1 }
+
+// nodeDiagnostic spans a YAML node's text.
+func nodeDiagnostic(n *yaml.Node, severity, code, message string) diagnostic {
+	width := len(n.Value)
+	if width == 0 {
+		width = 1
+	}
+	return diagnostic{
+		Line:      frontmatterLine(n.Line),
+		Column:    n.Column,
+		EndLine:   frontmatterLine(n.Line),
+		EndColumn: n.Column + width,
+		Severity:  severity,
+		Code:      code,
+		Message:   message,
+	}
+}
+
+// validate returns the problems in a job file's frontmatter, sorted by
+// position. A file without frontmatter has nothing to check.
+func (v *jobValidator) validate(content []byte) []diagnostic {
+	diags := []diagnostic{}
+	front, _, ok := splitFrontmatter(content)
+	if !ok {
+		return diags
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(front, &doc); err != nil {
+		line := 1
+		if m := yamlErrLineRe.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		msg := strings.TrimPrefix(err.Error(), "yaml: ")
+		return append(diags, diagnostic{
+			Line: frontmatterLine(line), Column: 1, EndLine: frontmatterLine(line), EndColumn: 1,
+			Severity: severityError, Code: "yaml", Message: msg,
+		})
+	}
+	if len(doc.Content) == 0 {
+		return diags
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return append(diags, nodeDiagnostic(root, severityError, "yaml", "frontmatter is not a mapping"))
+	}
+
+	fields := make(map[string]schemaField, len(v.schema.Frontmatter))
+	for _, f := range v.schema.Frontmatter {
+		fields[f.Name] = f
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		field, known := fields[key.Value]
+		if !known {
+			msg := fmt.Sprintf("unknown field %q", key.Value)
+			if s := closestField(key.Value, v.schema.Frontmatter); s != "" {
+				msg += fmt.Sprintf("; did you mean %q?", s)
+			}
+			diags = append(diags, nodeDiagnostic(key, severityWarning, "unknown-field", msg))
+			continue
+		}
+		diags = append(diags, v.checkValue(field, value)...)
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Line != diags[j].Line {
+			return diags[i].Line < diags[j].Line
+		}
+		return diags[i].Column < diags[j].Column
+	})
+	return diags
+}
+
+// checkValue checks a field's value against its type, its enum and its
+// value source.
+func (v *jobValidator) checkValue(field schemaField, value *yaml.Node) []diagnostic {
+	if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+		return nil
+	}
+	var items []*yaml.Node
+	switch field.Type {
+	case "list":
+		if value.Kind != yaml.SequenceNode {
+			return []diagnostic{nodeDiagnostic(value, severityError, "type", fmt.Sprintf("%s must be a list", field.Name))}
+		}
+		items = value.Content
+	case "map":
+		if value.Kind != yaml.MappingNode {
+			return []diagnostic{nodeDiagnostic(value, severityError, "type", fmt.Sprintf("%s must be a mapping", field.Name))}
+		}
+		return nil
+	case "bool":
+		if value.Kind != yaml.ScalarNode || value.Tag != "!!bool" {
+			return []diagnostic{nodeDiagnostic(value, severityError, "type", fmt.Sprintf("%s must be true or false", field.Name))}
+		}
+		return nil
+	case "int":
+		if value.Kind != yaml.ScalarNode || value.Tag != "!!int" {
+			return []diagnostic{nodeDiagnostic(value, severityError, "type", fmt.Sprintf("%s must be a whole number", field.Name))}
+		}
+		return nil
+	default:
+		if value.Kind != yaml.ScalarNode {
+			return []diagnostic{nodeDiagnostic(value, severityError, "type", fmt.Sprintf("%s must be a single value", field.Name))}
+		}
+		items = []*yaml.Node{value}
+	}
+
+	var diags []diagnostic
+	for _, item := range items {
+		if item.Kind != yaml.ScalarNode {
+			diags = append(diags, nodeDiagnostic(item, severityError, "type", fmt.Sprintf("%s entries must be single values", field.Name)))
+			continue
+		}
+		if d, bad := v.checkScalar(field, item); bad {
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+func (v *jobValidator) checkScalar(field schemaField, item *yaml.Node) (diagnostic, bool) {
+	if len(field.Enum) > 0 {
+		allowed := make([]string, len(field.Enum))
+		for i, e := range field.Enum {
+			if e.Value == item.Value {
+				return diagnostic{}, false
+			}
+			allowed[i] = e.Value
+		}
+		return nodeDiagnostic(item, severityError, "bad-value",
+			fmt.Sprintf("%s %q is not one of %s", field.Name, item.Value, strings.Join(allowed, ", "))), true
+	}
+
+	switch field.Values {
+	case "":
+		return diagnostic{}, false
+	case "jobs":
+		if _, err := os.Stat(filepath.Join(v.dir, item.Value)); err != nil {
+			return nodeDiagnostic(item, severityError, "missing-dependency",
+				fmt.Sprintf("dependency %s does not exist in %s", item.Value, v.dir)), true
+		}
+		return diagnostic{}, false
+	}
+
+	known, ok := v.names(field.Values)
+	if !ok || known[item.Value] {
+		return diagnostic{}, false
+	}
+	// The lists lag what the tools accept: the model catalog misses new
+	// releases, and templates and skills can be added after the listing.
+	return nodeDiagnostic(item, severityWarning, "unknown-"+strings.TrimSuffix(field.Values, "s"),
+		fmt.Sprintf("%s %q not found", strings.TrimSuffix(field.Values, "s"), item.Value)), true
+}
+
+// closestField suggests the schema field a misspelled key was meant to be,
+// when one is within two edits.
+func closestField(key string, fields []schemaField) string {
+	best, bestDist := "", 3
+	for _, f := range fields {
+		if d := editDistance(strings.ToLower(key), f.Name); d < bestDist {
+			best, bestDist = f.Name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// toolNames lists names from `<tool> <args...>` JSON output, an array of
+// objects with a name. Tools that are missing or fail list nothing, and
+// their values go unchecked.
+func toolNames(tool string, args ...string) (map[string]bool, bool) {
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, false
+	}
+	// #nosec G204 -- fixed grove tool and arguments
+	out, err := exec.Command(tool, args...).Output()
+	if err != nil {
+		return nil, false
+	}
+	var entries []map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(out), &entries); err != nil {
+		return nil, false
+	}
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if name, _ := firstValue(e, "name", "Name").(string); name != "" {
+			names[name] = true
+		}
+	}
+	return names, true
+}
+
+// defaultNameLister lists templates through flow, skills through the skills
+// tool and models from the cached catalog, without refreshing it.
+func defaultNameLister(source string) (map[string]bool, bool) {
+	switch source {
+	case "templates":
+		return toolNames("flow", "plan", "templates", "list", "--json")
+	case "skills":
+		return toolNames("skills", "list", "--json")
+	case "models":
+		c, ok := readModelCatalog()
+		if !ok {
+			return nil, false
+		}
+		names := make(map[string]bool, len(c.Models))
+		for _, m := range c.Models {
+			names[m.ID] = true
+		}
+		return names, true
+	}
+	return nil, false
+}
+
+func newJobValidateCmd() *cobra.Command {
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "validate <file>",
+		Short: "Check job frontmatter and report JSON diagnostics",
+		Long: `Checks a job file's frontmatter against the schema of 'schema chat' (flow's
+own job schema when flow can report it, else the built-in table):
+unknown fields (with a suggestion for typos), values of the wrong type, job
+types and statuses flow does not accept, templates, skills and models that
+do not exist, and depends_on entries with no job file next to this one.
+
+Prints a JSON array of diagnostics with file line numbers. Templates are
+listed through flow and skills through the skills tool; when either is
+unavailable those values are not checked. Models are checked against the
+cached catalog (see 'models'). Unknown templates, skills and models are
+warnings, since those lists can lag what the tools accept.
+
+--strict exits non-zero when there are errors.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobFile, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(jobFile) //nolint:gosec // user-specified job file
+			if err != nil {
+				return fmt.Errorf("read job file: %w", err)
+			}
+			v := &jobValidator{schema: loadChatSchema(), dir: filepath.Dir(jobFile), names: defaultNameLister}
+			diags := v.validate(content)
+			if err := json.NewEncoder(os.Stdout).Encode(diags); err != nil {
+				return err
+			}
+			if strict {
+				for _, d := range diags {
+					if d.Severity == severityError {
+						return errors.New("job frontmatter has errors")
+					}
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&strict, "strict", false, "Exit non-zero when there are errors")
+
+	return cmd
+}
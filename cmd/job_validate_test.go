@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateJob(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "01-spec.md"), []byte("---\nid: spec\n---\n"), 0o600))
+
+	names := func(source string) (map[string]bool, bool) {
+		switch source {
+		case "templates":
+			return map[string]bool{"chat": true}, true
+		case "models":
+			return map[string]bool{"gpt-4o": true}, true
+		}
+		return nil, false // skills cannot be listed
+	}
+	v := &jobValidator{schema: newChatSchema(), dir: dir, names: names}
+
+	content := "---\n" +
+		"id: impl\n" +
+		"type: chatt\n" +
+		"status: pending\n" +
+		"dependson:\n" +
+		"  - 01-spec.md\n" +
+		"depends_on:\n" +
+		"  - 01-spec.md\n" +
+		"  - 02-missing.md\n" +
+		"template: nope\n" +
+		"model: gpt-9\n" +
+		"skill_sequence: review\n" +
+		"skill: anything\n" +
+		"prepend_dependencies: yes please\n" +
+		"---\n" +
+		"Body\n"
+
+	diags := v.validate([]byte(content))
+	require.Len(t, diags, 7, "%+v", diags)
+
+	assert.Equal(t, diagnostic{Line: 3, Column: 7, EndLine: 3, EndColumn: 12, Severity: severityError, Code: "bad-value",
+		Message: `type "chatt" is not one of chat, oneshot, interactive_agent, headless_agent, shell`}, diags[0])
+	assert.Equal(t, diagnostic{Line: 5, Column: 1, EndLine: 5, EndColumn: 10, Severity: severityWarning, Code: "unknown-field",
+		Message: `unknown field "dependson"; did you mean "depends_on"?`}, diags[1])
+	assert.Equal(t, "missing-dependency", diags[2].Code)
+	assert.Equal(t, 9, diags[2].Line, "the list entry, not the key")
+	assert.Equal(t, 5, diags[2].Column)
+	assert.Equal(t, "unknown-template", diags[3].Code)
+	assert.Equal(t, severityWarning, diags[3].Severity, "unknown names only warn")
+	assert.Equal(t, "unknown-model", diags[4].Code)
+	assert.Equal(t, severityWarning, diags[4].Severity, "the catalog may lag new models")
+	assert.Equal(t, diagnostic{Line: 12, Column: 17, EndLine: 12, EndColumn: 23, Severity: severityError, Code: "type",
+		Message: "skill_sequence must be a list"}, diags[5])
+	assert.Equal(t, "prepend_dependencies must be true or false", diags[6].Message)
+}
+
+func TestValidateJobSyntax(t *testing.T) {
+	v := &jobValidator{schema: newChatSchema(), dir: t.TempDir(), names: func(string) (map[string]bool, bool) { return nil, false }}
+
+	assert.Empty(t, v.validate([]byte("No frontmatter here.\n")))
+	assert.Empty(t, v.validate([]byte("---\nid: a\ntitle: A\n---\n")))
+
+	diags := v.validate([]byte("---\nid: a\ntitle: b\n  bad: x\n---\n"))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "yaml", diags[0].Code)
+	assert.Equal(t, severityError, diags[0].Severity)
+	assert.Equal(t, 4, diags[0].Line, "the line yaml reports, in file terms")
+}
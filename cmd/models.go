@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -10,30 +15,76 @@ func newModelsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "models",
 		Short: "Interact with AI models",
-		Long:  "A wrapper for 'flow models' commands.",
+		Long:  "Works with the models 'flow models' knows about.",
 	}
 	cmd.AddCommand(newModelsListCmd())
 	return cmd
 }
 
 func newModelsListCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		jsonOutput bool
+		refresh    bool
+		ttl        time.Duration
+	)
+
+	cmd := &cobra.Command{
 		Use:   "list",
-		Short: "List available models",
+		Short: "List available models with their limits and pricing",
+		Long: `Lists the models 'flow models' reports, normalized to one schema: id,
+provider, context window, max output tokens, tool and vision support, and
+list pricing in dollars per million tokens. Metadata flow does not report is
+filled in from a built-in table of model families, which can go stale; in
+the table such prices are marked with "*", and in JSON limitsSource and
+pricingSource say "builtin". Unknown values are zero.
+
+The catalog is cached on disk for --ttl; --refresh fetches it again.`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Pass through any flags to `flow models`
-			flowArgs := []string{"models"}
-			found := false
-			for _, arg := range os.Args {
-				// Pass through flags like --json
-				if found {
-					flowArgs = append(flowArgs, arg)
-				}
-				if arg == "list" {
-					found = true
+			catalog, err := loadModelCatalog(ttl, refresh)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(catalog)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tPROVIDER\tCONTEXT\tMAX OUTPUT\tTOOLS\tVISION\t$/MTOK IN\t$/MTOK OUT")
+			for _, m := range catalog.Models {
+				in, out := "-", "-"
+				if m.Pricing != nil {
+					in = strconv.FormatFloat(m.Pricing.Input, 'f', -1, 64)
+					out = strconv.FormatFloat(m.Pricing.Output, 'f', -1, 64)
+					if m.PricingSource == "builtin" {
+						in, out = in+"*", out+"*"
+					}
 				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					m.ID, m.Provider, tokenCount(m.ContextWindow), tokenCount(m.MaxOutput),
+					yesNo(m.SupportsTools), yesNo(m.SupportsVision), in, out)
 			}
-			return runFlowCommand(flowArgs...)
+			return w.Flush()
 		},
 	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the catalog as JSON")
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Ignore the cached catalog")
+	cmd.Flags().DurationVar(&ttl, "ttl", defaultModelsTTL, "How long a cached catalog stays fresh")
+
+	return cmd
+}
+
+func tokenCount(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.Itoa(n)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
 }
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultModelsTTL is how long a fetched catalog is served from disk.
+const defaultModelsTTL = 24 * time.Hour
+
+// modelInfo is one catalog entry. Zero numbers mean unknown.
+type modelInfo struct {
+	ID             string        `json:"id"`
+	Provider       string        `json:"provider"`
+	ContextWindow  int           `json:"contextWindow"`
+	MaxOutput      int           `json:"maxOutput"`
+	SupportsTools  bool          `json:"supportsTools"`
+	SupportsVision bool          `json:"supportsVision"`
+	Pricing        *modelPricing `json:"pricing,omitempty"`
+	// LimitsSource and PricingSource say where the numbers came from:
+	// "flow" when flow reported them, "builtin" when modelFamilies filled
+	// them in.
+	LimitsSource  string `json:"limitsSource,omitempty"`
+	PricingSource string `json:"pricingSource,omitempty"`
+}
+
+// modelPricing is in US dollars per million tokens.
+type modelPricing struct {
+	Input  float64 `json:"inputPerMTok"`
+	Output float64 `json:"outputPerMTok"`
+}
+
+// Cost prices a token count pair in dollars.
+func (p *modelPricing) Cost(input, output int) float64 {
+	if p == nil {
+		return 0
+	}
+	return (float64(input)*p.Input + float64(output)*p.Output) / 1e6
+}
+
+// modelFamily is known metadata for model IDs sharing a prefix.
+type modelFamily struct {
+	prefix   string
+	provider string
+	context  int
+	output   int
+	tools    bool
+	vision   bool
+	pricing  *modelPricing
+}
+
+// modelFamilies fills in what flow does not report, from the providers'
+// published limits and list prices. It is only a fallback and goes stale;
+// numbers flow reports always win. Longer prefixes are listed first so they
+// win.
+var modelFamilies = []modelFamily{
+	{"claude-opus-4-5", "anthropic", 200000, 64000, true, true, &modelPricing{5, 25}},
+	{"claude-opus-4", "anthropic", 200000, 32000, true, true, &modelPricing{15, 75}},
+	{"claude-sonnet-4", "anthropic", 200000, 64000, true, true, &modelPricing{3, 15}},
+	{"claude-haiku-4", "anthropic", 200000, 64000, true, true, &modelPricing{1, 5}},
+	{"claude-3-7-sonnet", "anthropic", 200000, 64000, true, true, &modelPricing{3, 15}},
+	{"claude-3-5-haiku", "anthropic", 200000, 8192, true, false, &modelPricing{0.8, 4}},
+	{"claude-", "anthropic", 200000, 8192, true, true, nil},
+	{"gemini-2.5-pro", "google", 1048576, 65536, true, true, &modelPricing{1.25, 10}},
+	{"gemini-2.5-flash-lite", "google", 1048576, 65536, true, true, &modelPricing{0.1, 0.4}},
+	{"gemini-2.5-flash", "google", 1048576, 65536, true, true, &modelPricing{0.3, 2.5}},
+	{"gemini-2.0-flash", "google", 1048576, 8192, true, true, &modelPricing{0.1, 0.4}},
+	{"gemini-1.5-pro", "google", 2097152, 8192, true, true, &modelPricing{1.25, 5}},
+	{"gemini-", "google", 1048576, 8192, true, true, nil},
+	{"gpt-5-nano", "openai", 400000, 128000, true, true, &modelPricing{0.05, 0.4}},
+	{"gpt-5-mini", "openai", 400000, 128000, true, true, &modelPricing{0.25, 2}},
+	{"gpt-5", "openai", 400000, 128000, true, true, &modelPricing{1.25, 10}},
+	{"gpt-4.1-nano", "openai", 1047576, 32768, true, true, &modelPricing{0.1, 0.4}},
+	{"gpt-4.1-mini", "openai", 1047576, 32768, true, true, &modelPricing{0.4, 1.6}},
+	{"gpt-4.1", "openai", 1047576, 32768, true, true, &modelPricing{2, 8}},
+	{"gpt-4o-mini", "openai", 128000, 16384, true, true, &modelPricing{0.15, 0.6}},
+	{"gpt-4o", "openai", 128000, 16384, true, true, &modelPricing{2.5, 10}},
+	{"o1", "openai", 200000, 100000, true, true, &modelPricing{15, 60}},
+	{"o3-mini", "openai", 200000, 100000, true, false, &modelPricing{1.1, 4.4}},
+	{"o3", "openai", 200000, 100000, true, true, &modelPricing{2, 8}},
+	{"o4-mini", "openai", 200000, 100000, true, true, &modelPricing{1.1, 4.4}},
+}
+
+// knownModel builds an entry from modelFamilies, and false when no family
+// matches. Provider-qualified IDs ("anthropic/claude-…") match on the bare ID.
+func knownModel(id string) (modelInfo, bool) {
+	bare := strings.ToLower(id)
+	if i := strings.LastIndex(bare, "/"); i >= 0 {
+		bare = bare[i+1:]
+	}
+	for _, f := range modelFamilies {
+		if strings.HasPrefix(bare, f.prefix) {
+			info := modelInfo{
+				ID:             id,
+				Provider:       f.provider,
+				ContextWindow:  f.context,
+				MaxOutput:      f.output,
+				SupportsTools:  f.tools,
+				SupportsVision: f.vision,
+				Pricing:        f.pricing,
+				LimitsSource:   "builtin",
+			}
+			if f.pricing != nil {
+				info.PricingSource = "builtin"
+			}
+			return info, true
+		}
+	}
+	return modelInfo{ID: id}, false
+}
+
+// modelCatalog is the on-disk cache of the normalized catalog.
+type modelCatalog struct {
+	FetchedAt time.Time   `json:"fetchedAt"`
+	Models    []modelInfo `json:"models"`
+}
+
+func (c *modelCatalog) fetched() time.Time {
+	if c == nil {
+		return time.Time{}
+	}
+	return c.FetchedAt
+}
+
+// readModelCatalog returns the cached catalog regardless of age.
+func readModelCatalog() (*modelCatalog, bool) {
+	var c *modelCatalog
+	if !readCache(cachePath("models.json"), &c) || c == nil {
+		return nil, false
+	}
+	return c, true
+}
+
+func writeModelCatalog(c *modelCatalog) error {
+	return writeCache(cachePath("models.json"), c)
+}
+
+// loadModelCatalog serves the cache while it is younger than ttl, and
+// otherwise asks flow. When flow fails a stale cache is better than none.
+func loadModelCatalog(ttl time.Duration, refresh bool) (*modelCatalog, error) {
+	if refresh {
+		ttl = 0
+	}
+	return loadFetchedCache(cachePath("models.json"), ttl, func() (*modelCatalog, error) {
+		models, err := fetchFlowModels()
+		if err != nil {
+			return nil, err
+		}
+		return &modelCatalog{FetchedAt: time.Now().UTC(), Models: models}, nil
+	})
+}
+
+// fetchFlowModels runs `flow models --json` and normalizes its entries.
+func fetchFlowModels() ([]modelInfo, error) {
+	if _, err := exec.LookPath("flow"); err != nil {
+		return nil, fmt.Errorf("'flow' command not found in PATH. Please ensure the grove-flow binary is installed and accessible")
+	}
+	var stderr bytes.Buffer
+	c := exec.Command("flow", "models", "--json")
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("flow models failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return normalizeFlowModels(out)
+}
+
+// normalizeFlowModels maps flow's model entries onto modelInfo. Whatever
+// flow reports wins; modelFamilies fills the rest.
+func normalizeFlowModels(data []byte) ([]modelInfo, error) {
+	var raw struct {
+		Models []map[string]any `json:"models"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decode flow models: %w", err)
+	}
+
+	models := make([]modelInfo, 0, len(raw.Models))
+	for _, m := range raw.Models {
+		id, _ := m["id"].(string)
+		if id == "" {
+			continue
+		}
+		info, _ := knownModel(id)
+		if p, _ := m["provider"].(string); p != "" {
+			info.Provider = p
+		}
+		if n, ok := numberField(m, "context_window", "contextWindow", "input_token_limit"); ok {
+			info.ContextWindow = int(n)
+			info.LimitsSource = "flow"
+		}
+		if n, ok := numberField(m, "max_output", "max_output_tokens", "maxOutput", "output_token_limit"); ok {
+			info.MaxOutput = int(n)
+		}
+		if p, ok := flowPricing(m); ok {
+			info.Pricing = p
+			info.PricingSource = "flow"
+		}
+		if b, ok := m["supports_tools"].(bool); ok {
+			info.SupportsTools = b
+		}
+		if b, ok := m["supports_vision"].(bool); ok {
+			info.SupportsVision = b
+		}
+		models = append(models, info)
+	}
+	sort.SliceStable(models, func(i, j int) bool {
+		if models[i].Provider != models[j].Provider {
+			return models[i].Provider < models[j].Provider
+		}
+		return models[i].ID < models[j].ID
+	})
+	return models, nil
+}
+
+// flowPricing reads the prices of a flow model entry, in dollars per
+// million tokens: a "pricing" object with input and output prices per
+// million, flat per-million fields, or LiteLLM-style per-token costs.
+func flowPricing(m map[string]any) (*modelPricing, bool) {
+	if nested, ok := m["pricing"].(map[string]any); ok {
+		in, okIn := numberField(nested, "input", "inputPerMTok", "input_per_mtok")
+		out, okOut := numberField(nested, "output", "outputPerMTok", "output_per_mtok")
+		if okIn || okOut {
+			return &modelPricing{Input: in, Output: out}, true
+		}
+	}
+	in, okIn := numberField(m, "input_price_per_mtok", "input_cost_per_mtok", "inputPerMTok")
+	out, okOut := numberField(m, "output_price_per_mtok", "output_cost_per_mtok", "outputPerMTok")
+	if okIn || okOut {
+		return &modelPricing{Input: in, Output: out}, true
+	}
+	in, okIn = numberField(m, "input_cost_per_token")
+	out, okOut = numberField(m, "output_cost_per_token")
+	if okIn || okOut {
+		return &modelPricing{Input: in * 1e6, Output: out * 1e6}, true
+	}
+	return nil, false
+}
+
+func numberField(m map[string]any, keys ...string) (float64, bool) {
+	for _, k := range keys {
+		if n, ok := m[k].(float64); ok && n > 0 {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// lookupModel resolves a model for budget and cost checks without running
+// flow: the cached catalog entry, which carries flow's numbers, with any
+// gaps filled from modelFamilies.
+func lookupModel(id string) (modelInfo, bool) {
+	known, ok := knownModel(id)
+	c, cached := readModelCatalog()
+	if !cached {
+		return known, ok
+	}
+	for _, m := range c.Models {
+		if !strings.EqualFold(m.ID, id) {
+			continue
+		}
+		if m.ContextWindow == 0 {
+			m.ContextWindow, m.LimitsSource = known.ContextWindow, known.LimitsSource
+		}
+		if m.Pricing == nil {
+			m.Pricing, m.PricingSource = known.Pricing, known.PricingSource
+		}
+		return m, m.ContextWindow > 0 || m.Pricing != nil
+	}
+	return known, ok
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeFlowModels(t *testing.T) {
+	data := []byte(`{"models": [
+		{"id": "gpt-4o-mini", "provider": "openai"},
+		{"id": "claude-sonnet-4-5-20250929", "provider": "anthropic", "context_window": 1000000},
+		{"id": "local-llama", "provider": "ollama"},
+		{"id": "gpt-4o", "provider": "openai", "input_cost_per_token": 0.000002, "output_cost_per_token": 0.000008},
+		{"id": "gemini-2.5-pro", "pricing": {"input": 2.5, "output": 15}},
+		{"id": ""}
+	]}`)
+	models, err := normalizeFlowModels(data)
+	require.NoError(t, err)
+	require.Len(t, models, 5)
+
+	byID := map[string]modelInfo{}
+	for _, m := range models {
+		byID[m.ID] = m
+	}
+	assert.Equal(t, 1000000, byID["claude-sonnet-4-5-20250929"].ContextWindow, "flow's numbers win")
+	assert.Equal(t, 64000, byID["claude-sonnet-4-5-20250929"].MaxOutput, "families fill the rest")
+	assert.Equal(t, 128000, byID["gpt-4o-mini"].ContextWindow)
+	require.NotNil(t, byID["gpt-4o-mini"].Pricing)
+	assert.Equal(t, 0.15, byID["gpt-4o-mini"].Pricing.Input)
+	assert.Equal(t, "builtin", byID["gpt-4o-mini"].PricingSource)
+	assert.Equal(t, "flow", byID["claude-sonnet-4-5-20250929"].LimitsSource)
+
+	require.NotNil(t, byID["gpt-4o"].Pricing)
+	assert.InDelta(t, 2.0, byID["gpt-4o"].Pricing.Input, 1e-9, "flow's per-token prices win over the table")
+	assert.InDelta(t, 8.0, byID["gpt-4o"].Pricing.Output, 1e-9)
+	assert.Equal(t, "flow", byID["gpt-4o"].PricingSource)
+	assert.Equal(t, &modelPricing{Input: 2.5, Output: 15}, byID["gemini-2.5-pro"].Pricing)
+	assert.Zero(t, byID["local-llama"].ContextWindow)
+	assert.Nil(t, byID["local-llama"].Pricing)
+	assert.Equal(t, "anthropic", models[0].Provider, "sorted by provider")
+}
+
+func TestKnownModelPrefersLongestFamily(t *testing.T) {
+	m, ok := knownModel("anthropic/claude-opus-4-5-20251101")
+	require.True(t, ok)
+	assert.Equal(t, 5.0, m.Pricing.Input)
+
+	m, _ = knownModel("claude-opus-4-1")
+	assert.Equal(t, 15.0, m.Pricing.Input)
+
+	assert.InDelta(t, 0.018, m.Pricing.Cost(200, 200), 1e-9)
+	var none *modelPricing
+	assert.Zero(t, none.Cost(1000, 1000))
+}
+
+func TestLoadModelCatalogServesFreshCache(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	t.Setenv("PATH", t.TempDir()) // no flow: only the cache can answer
+
+	_, err := loadModelCatalog(time.Hour, false)
+	require.Error(t, err)
+
+	cached := &modelCatalog{FetchedAt: time.Now().Add(-2 * time.Hour), Models: []modelInfo{{ID: "custom-model", ContextWindow: 4096}}}
+	require.NoError(t, writeModelCatalog(cached))
+
+	c, err := loadModelCatalog(time.Hour, false)
+	require.NoError(t, err, "a stale cache beats no catalog")
+	assert.Equal(t, "custom-model", c.Models[0].ID)
+
+	w, ok := contextWindow("custom-model")
+	require.True(t, ok)
+	assert.Equal(t, 4096, w, "budget checks read the cached catalog")
+
+	require.NoError(t, writeModelCatalog(&modelCatalog{FetchedAt: time.Now(), Models: []modelInfo{
+		{ID: "gpt-4o", Pricing: &modelPricing{Input: 2, Output: 8}, PricingSource: "flow"},
+	}}))
+	m, ok := lookupModel("gpt-4o")
+	require.True(t, ok)
+	assert.Equal(t, 2.0, m.Pricing.Input, "cached flow pricing wins")
+	assert.Equal(t, 128000, m.ContextWindow, "gaps are filled from the families")
+	assert.Equal(t, "builtin", m.LimitsSource)
+}
@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grovetools/core/pkg/workspace"
+	"github.com/spf13/cobra"
+)
+
+const defaultNoteType = "inbox"
+
+// noteInfo describes a note for the editor: where it is and how to refer to
+// it from another file.
+type noteInfo struct {
+	Path      string `json:"path"`
+	Alias     string `json:"alias"`
+	Title     string `json:"title,omitempty"`
+	Workspace string `json:"workspace,omitempty"`
+	Line      int    `json:"line,omitempty"` // first matching line, for find
+	Text      string `json:"text,omitempty"`
+}
+
+// notesDir is where new notes of noteType go for the workspace containing
+// cwd, in the given notebook or the workspace's own. Outside every
+// workspace notes go to the global notebook, which has no notebook to
+// choose, so a notebook is refused there.
+func notesDir(a *pathAliaser, cwd, notebook, noteType string) (string, error) {
+	if notebook != "" {
+		known := false
+		for _, nb := range a.notebooks {
+			known = known || nb.Name == notebook
+		}
+		if !known {
+			return "", fmt.Errorf("notebook %q is not defined in notebooks.definitions", notebook)
+		}
+	}
+	node := findWorkspaceByPath(a.provider, cwd, a.discoveryResult)
+	if node == nil {
+		if notebook != "" {
+			return "", fmt.Errorf("--notebook needs a workspace; %s is not inside one", cwd)
+		}
+		return a.locator.GetNotesDir(&workspace.WorkspaceNode{Name: "global"}, noteType)
+	}
+	n := *node
+	if notebook != "" {
+		n.NotebookName = notebook
+	}
+	return a.locator.GetNotesDir(&n, noteType)
+}
+
+// newNoteFile picks an unused "<date>-<slug>.md" name in dir.
+func newNoteFile(dir, title string, now time.Time) string {
+	slug := slugify(title)
+	if slug == "" {
+		slug = "note"
+	}
+	base := now.Format("20060102") + "-" + slug
+	path := filepath.Join(dir, base+".md")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.md", base, i))
+	}
+}
+
+// noteContent renders a new note: frontmatter with the title and creation
+// time, the title as a heading, then the body. source, when set, is the
+// alias of the file the body was captured from.
+func noteContent(title, body, source string, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\ntitle: %s\ncreated_at: %s\n", mustJSON(title), now.UTC().Format(time.RFC3339))
+	if source != "" {
+		fmt.Fprintf(&b, "source: %s\n", mustJSON(source))
+	}
+	fmt.Fprintf(&b, "---\n\n# %s\n\n", title)
+	if body = strings.TrimSpace(body); body != "" {
+		b.WriteString(body)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// noteTitle is a note's frontmatter title, else its first heading, else its
+// file name.
+func noteTitle(path string, content []byte) string {
+	doc := parseChatDocument(content)
+	if node, err := parseFrontmatterNode(doc.Front); err == nil {
+		if t := frontmatterField(node, "title"); t != "" {
+			return t
+		}
+	}
+	for _, line := range doc.lines[doc.bodyStart:] {
+		if h, ok := strings.CutPrefix(line, "# "); ok {
+			return strings.TrimSpace(h)
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// markdownLink links to target from a file in fromDir, or by absolute path
+// when fromDir is empty.
+func markdownLink(title, target, fromDir string) string {
+	link := target
+	if fromDir != "" {
+		if rel, err := filepath.Rel(fromDir, target); err == nil {
+			link = rel
+		}
+	}
+	link = strings.ReplaceAll(filepath.ToSlash(link), " ", "%20")
+	return fmt.Sprintf("[%s](%s)", strings.NewReplacer("[", `\[`, "]", `\]`).Replace(title), link)
+}
+
+// noteRoots are the directories notes live in: every workspace's notes
+// directory and the notebook roots.
+func noteRoots(a *pathAliaser) []searchRoot {
+	var roots []searchRoot
+	if dirs, err := a.locator.ScanForAllNotes(a.provider); err == nil {
+		for _, d := range dirs {
+			if d.Owner != nil {
+				roots = append(roots, searchRoot{Path: d.Path, Workspace: d.Owner.Name})
+			}
+		}
+	}
+	for _, nb := range a.notebooks {
+		roots = append(roots, searchRoot{Path: nb.RootDir})
+	}
+	return roots
+}
+
+// findNotes returns the notes matching query, one per file with its first
+// matching line. Plan jobs inside notebook roots are not notes and are
+// left out.
+func findNotes(idx *searchIndex, files []searchFile, query string, limit int, alias func(string) string) []noteInfo {
+	notes := []noteInfo{}
+	seen := make(map[string]bool)
+	for _, h := range searchFiles(idx, files, query, searchOptions{}) {
+		if seen[h.File] || h.Plan != "" {
+			continue
+		}
+		seen[h.File] = true
+		title := h.Title
+		if title == "" {
+			title = strings.TrimSuffix(filepath.Base(h.File), filepath.Ext(h.File))
+		}
+		notes = append(notes, noteInfo{Path: h.File, Alias: alias(h.File), Title: title, Workspace: h.Workspace, Line: h.Line, Text: h.Text})
+		if limit > 0 && len(notes) >= limit {
+			break
+		}
+	}
+	return notes
+}
+
+// newNbCmd groups the notebook commands.
+func newNbCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "nb",
+		Short: "Create, link and find notebook notes",
+		Long:  "Works with notes in the notebooks of the grove config, for capturing and cross-referencing notes from the editor.",
+	}
+	cmd.AddCommand(newNbNewCmd())
+	cmd.AddCommand(newNbLinkCmd())
+	cmd.AddCommand(newNbFindCmd())
+	return cmd
+}
+
+func newNbNewCmd() *cobra.Command {
+	var (
+		notebook      string
+		noteType      string
+		title         string
+		fromSelection bool
+		source        string
+		jsonOutput    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "new",
+		Short: "Create a note in a notebook",
+		Long: `Creates a note in the notes directory of the current workspace (in
+--notebook, or the workspace's own notebook) under --type, named after the
+date and --title. Outside every workspace the note goes to the global
+notebook, and --notebook is an error. --from-selection reads the note's body from stdin;
+--source records the file it came from as an alias.
+
+Prints the new note's path, or with --json its path and alias.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(title) == "" {
+				return fmt.Errorf("--title is required")
+			}
+			var body []byte
+			if fromSelection {
+				var err error
+				if body, err = io.ReadAll(os.Stdin); err != nil {
+					return fmt.Errorf("read selection: %w", err)
+				}
+			}
+			a, err := newPathAliaser()
+			if err != nil {
+				return err
+			}
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			dir, err := notesDir(a, cwd, notebook, noteType)
+			if err != nil {
+				return err
+			}
+			if source != "" {
+				if abs, err := filepath.Abs(source); err == nil {
+					source = a.Alias(abs)
+				}
+			}
+
+			if err := os.MkdirAll(dir, 0o750); err != nil {
+				return err
+			}
+			now := time.Now()
+			path := newNoteFile(dir, title, now)
+			if err := os.WriteFile(path, []byte(noteContent(strings.TrimSpace(title), string(body), source, now)), 0o600); err != nil {
+				return err
+			}
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(noteInfo{Path: path, Alias: a.Alias(path), Title: strings.TrimSpace(title)})
+			}
+			fmt.Println(path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&notebook, "notebook", "", "Notebook to create the note in (default: the workspace's)")
+	cmd.Flags().StringVar(&noteType, "type", defaultNoteType, "Note type, the directory under the notes directory")
+	cmd.Flags().StringVar(&title, "title", "", "Note title")
+	cmd.Flags().BoolVar(&fromSelection, "from-selection", false, "Read the note's body from stdin")
+	cmd.Flags().StringVar(&source, "source", "", "File the body was captured from")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the note's path and alias as JSON")
+
+	return cmd
+}
+
+func newNbLinkCmd() *cobra.Command {
+	var (
+		markdown bool
+		from     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "link <path>",
+		Short: "Print a reference to a note",
+		Long: `Prints the alias of a note ("@a:nb:..." inside a notebook, "@a:<workspace>/..."
+inside a workspace), as rules files and chats take it. --markdown prints a
+Markdown link titled after the note instead, relative to the file given
+with --from.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(path) //nolint:gosec // user-specified note
+			if err != nil {
+				return fmt.Errorf("read note: %w", err)
+			}
+			if markdown {
+				fromDir := ""
+				if from != "" {
+					if abs, err := filepath.Abs(from); err == nil {
+						fromDir = filepath.Dir(abs)
+					}
+				}
+				fmt.Println(markdownLink(noteTitle(path, content), path, fromDir))
+				return nil
+			}
+			a, err := newPathAliaser()
+			if err != nil {
+				return err
+			}
+			alias := a.Alias(path)
+			if alias == path {
+				return fmt.Errorf("%s is not inside a notebook or workspace", path)
+			}
+			fmt.Println(alias)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&markdown, "markdown", false, "Print a Markdown link instead of an alias")
+	cmd.Flags().StringVar(&from, "from", "", "File the Markdown link is inserted into")
+
+	return cmd
+}
+
+func newNbFindCmd() *cobra.Command {
+	var (
+		limit      int
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "find <query>",
+		Short: "Find notes containing every word of a query",
+		Long: `Searches the notes directories of all workspaces and the notebook roots,
+like 'search' but one result per note, with its alias for linking. Uses the
+same index as 'search'.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := newPathAliaser()
+			if err != nil {
+				return err
+			}
+			idx := loadSearchIndex(searchIndexPath())
+			notes := findNotes(idx, collectSearchFiles(noteRoots(a)), strings.Join(args, " "), limit, a.Alias)
+			idx.Save()
+
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(notes)
+			}
+			for _, n := range notes {
+				fmt.Printf("%s:%d: %s  %s\n", n.Path, n.Line, n.Title, n.Text)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 50, "Maximum number of notes (0 for no limit)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output notes as JSON")
+
+	return cmd
+}
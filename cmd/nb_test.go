@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNote(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC)
+
+	path := newNoteFile(dir, "Lexer: tabs?", now)
+	assert.Equal(t, filepath.Join(dir, "20250601-lexer-tabs.md"), path)
+	content := noteContent("Lexer: tabs?", "\n```go\nx := 1\n```\n\n", "@a:proj/lexer.go", now)
+	assert.Equal(t, "---\ntitle: \"Lexer: tabs?\"\ncreated_at: 2025-06-01T09:30:00Z\nsource: \"@a:proj/lexer.go\"\n---\n\n# Lexer: tabs?\n\n```go\nx := 1\n```\n", content)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	assert.Equal(t, filepath.Join(dir, "20250601-lexer-tabs-2.md"), newNoteFile(dir, "Lexer: tabs?", now), "existing notes are not overwritten")
+	assert.Equal(t, "Lexer: tabs?", noteTitle(path, []byte(content)))
+	assert.Equal(t, "Heading", noteTitle("/n/x.md", []byte("intro\n# Heading\n")))
+	assert.Equal(t, "x", noteTitle("/n/x.md", []byte("no title\n")))
+}
+
+func TestMarkdownLink(t *testing.T) {
+	assert.Equal(t, "[Design \\[draft\\]](../notes/my%20design.md)", markdownLink("Design [draft]", "/nb/notes/my design.md", "/nb/plans"))
+	assert.Equal(t, "[X](/nb/x.md)", markdownLink("X", "/nb/x.md", ""))
+}
+
+func TestFindNotes(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		filepath.Join(root, "notes", "inbox", "lexer.md"):          "---\ntitle: Lexer\n---\nTabs in the lexer.\n\nMore on lexer tabs.\n",
+		filepath.Join(root, "notes", "inbox", "other.md"):          "Nothing relevant.\n",
+		filepath.Join(root, "plans", "parser", "01-chat.md"):       "lexer tabs in a plan job\n",
+		filepath.Join(root, "notes", "learn", "tabs-and-lexer.md"): "The LEXER and tabs.\n",
+	}
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	idx := loadSearchIndex("")
+	alias := func(p string) string { return "@a:nb:" + filepath.ToSlash(p[len(root)+1:]) }
+
+	notes := findNotes(idx, collectSearchFiles([]searchRoot{{Path: root}}), "lexer tabs", 0, alias)
+	require.Len(t, notes, 2, "one result per note, plan jobs left out")
+	byAlias := map[string]noteInfo{}
+	for _, n := range notes {
+		byAlias[n.Alias] = n
+	}
+	lexer := byAlias["@a:nb:notes/inbox/lexer.md"]
+	assert.Equal(t, "Lexer", lexer.Title)
+	assert.Equal(t, 4, lexer.Line, "the first matching line")
+	assert.Equal(t, "tabs-and-lexer", byAlias["@a:nb:notes/learn/tabs-and-lexer.md"].Title)
+
+	assert.Len(t, findNotes(idx, collectSearchFiles([]searchRoot{{Path: root}}), "lexer", 1, alias), 1)
+}
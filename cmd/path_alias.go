@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grovetools/core/config"
+	"github.com/grovetools/core/pkg/workspace"
+	"github.com/grovetools/core/util/pathutil"
+	"github.com/sirupsen/logrus"
+)
+
+// notebookRoot is a configured notebook with its root directory expanded.
+type notebookRoot struct {
+	Name    string
+	RootDir string
+}
+
+// pathAliaser converts absolute paths into the aliases rules files and chat
+// directives use: "@a:nb:..." for notebook content, "@a:<workspace>/..." for
+// files inside a discovered workspace.
+type pathAliaser struct {
+	provider        *workspace.Provider
+	discoveryResult *workspace.DiscoveryResult
+	notebooks       []notebookRoot
+	locator         *workspace.NotebookLocator
+}
+
+// newPathAliaser runs workspace discovery and loads the notebook roots.
+func newPathAliaser() (*pathAliaser, error) {
+	// Initialize workspace provider for fast lookups.
+	// Suppress noisy discovery logs by redirecting logger output.
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	discoveryService := workspace.NewDiscoveryService(logger)
+	discoveryResult, err := discoveryService.DiscoverAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover workspaces: %w", err)
+	}
+
+	coreCfg, err := config.LoadDefault()
+	if err != nil {
+		ulog.Warn("Could not load grove config for notebook aliases").
+			Err(err).
+			Emit()
+	}
+
+	return &pathAliaser{
+		provider:        workspace.NewProvider(discoveryResult),
+		discoveryResult: discoveryResult,
+		notebooks:       notebookRoots(coreCfg),
+		locator:         workspace.NewNotebookLocator(coreCfg),
+	}, nil
+}
+
+// notebookRoots expands the configured notebook root directories, sorted by
+// length descending to match the most specific (longest) path first.
+func notebookRoots(coreCfg *config.Config) []notebookRoot {
+	var roots []notebookRoot
+	if coreCfg == nil || coreCfg.Notebooks == nil || coreCfg.Notebooks.Definitions == nil {
+		return roots
+	}
+	for name, nbConfig := range coreCfg.Notebooks.Definitions {
+		if nbConfig.RootDir != "" {
+			expandedRoot, err := pathutil.Expand(nbConfig.RootDir)
+			if err == nil {
+				roots = append(roots, notebookRoot{Name: name, RootDir: expandedRoot})
+			}
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		return len(roots[i].RootDir) > len(roots[j].RootDir)
+	})
+	return roots
+}
+
+// notebookAlias returns the "@a:nb:" alias for a path inside a notebook root.
+func notebookAlias(roots []notebookRoot, path string) (string, bool) {
+	for _, nb := range roots {
+		// Check if the file path is within this notebook's root dir.
+		if !strings.HasPrefix(path, nb.RootDir) {
+			continue
+		}
+		// Ensure it's a directory boundary to prevent partial matches (e.g., /path/to/nb vs /path/to/nb-plus).
+		if len(path) != len(nb.RootDir) && (len(path) <= len(nb.RootDir) || path[len(nb.RootDir)] != os.PathSeparator) {
+			continue
+		}
+		relPath, err := filepath.Rel(nb.RootDir, path)
+		if err != nil {
+			continue
+		}
+		// For the "default" notebook, omit the name for a cleaner alias and backward compatibility.
+		if nb.Name == "default" {
+			return fmt.Sprintf("@a:nb:%s", filepath.ToSlash(relPath)), true
+		}
+		return fmt.Sprintf("@a:nb:%s:%s", nb.Name, filepath.ToSlash(relPath)), true
+	}
+	return "", false
+}
+
+// Alias returns the alias for path, or path itself when it lies outside
+// every notebook and workspace.
+func (a *pathAliaser) Alias(path string) string {
+	// Check if this path is inside a notebook root
+	if alias, ok := notebookAlias(a.notebooks, path); ok {
+		return alias
+	}
+
+	// Find the most specific workspace containing this path
+	// Use case-insensitive matching on macOS/Windows
+	node := findWorkspaceByPath(a.provider, path, a.discoveryResult)
+	if node == nil {
+		// No containing workspace found, use the original absolute path
+		return path
+	}
+
+	// Found a containing workspace, create the alias
+	// On case-insensitive filesystems, normalize paths for consistent comparison
+	basePathNormalized, err := pathutil.NormalizeForLookup(node.Path)
+	if err != nil {
+		basePathNormalized = node.Path
+	}
+	filePathNormalized, err := pathutil.NormalizeForLookup(path)
+	if err != nil {
+		filePathNormalized = path
+	}
+
+	// If paths match on the normalized prefix, use the workspace's actual case
+	// to maintain consistency
+	if strings.HasPrefix(filePathNormalized, basePathNormalized) {
+		// Replace the matching prefix with the workspace's case
+		filePathNormalized = node.Path + path[len(node.Path):]
+	}
+
+	relativePath, err := filepath.Rel(node.Path, filePathNormalized)
+	if err != nil {
+		// Fallback to absolute path on error
+		return path
+	}
+
+	// Use the node's canonical identifier, replacing underscores with colons
+	// to create a resolvable, namespaced alias.
+	// e.g., "my-ecosystem_feature_sub-project" -> "my-ecosystem:feature:sub-project"
+	aliasPart := node.Identifier(":")
+
+	return fmt.Sprintf("@a:%s/%s", aliasPart, filepath.ToSlash(relativePath))
+}
@@ -47,6 +47,18 @@ func runFlowCommand(args ...string) error {
 	return nil
 }
 
+// flowPlanStatus returns `flow plan status --json` for a plan directory.
+func flowPlanStatus(planDir string) ([]byte, error) {
+	if _, err := exec.LookPath("flow"); err != nil {
+		return nil, fmt.Errorf("'flow' command not found in PATH. Please ensure the grove-flow binary is installed and accessible")
+	}
+	out, err := exec.Command("flow", "plan", "status", planDir, "--json").Output() //nolint:gosec // plan dir of the user's job
+	if err != nil {
+		return nil, fmt.Errorf("flow plan status failed: %w", err)
+	}
+	return out, nil
+}
+
 // newPlanInitCmd wraps `flow plan init`.
 // Most flags are removed as the interactive TUI handles them.
 // Keep extract-all-from for the :GrovePlanExtract command.
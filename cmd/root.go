@@ -16,6 +16,13 @@ func init() {
 	rootCmd.AddCommand(newPlanCmd())
 	rootCmd.AddCommand(newModelsCmd())
 	rootCmd.AddCommand(newTextCmd())
+	rootCmd.AddCommand(newRulesCmd())
+	rootCmd.AddCommand(newThemeCmd())
+	rootCmd.AddCommand(newTendCmd())
+	rootCmd.AddCommand(newSearchCmd())
+	rootCmd.AddCommand(newSchemaCmd())
+	rootCmd.AddCommand(newJobCmd())
+	rootCmd.AddCommand(newNbCmd())
 	rootCmd.AddCommand(newInternalCmd())
 }
 
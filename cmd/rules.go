@@ -0,0 +1,663 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grovetools/core/pkg/alias"
+	"github.com/spf13/cobra"
+)
+
+func newRulesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Analyze and maintain grove context rules files",
+		Long:  "Provides in-process analysis and formatting of .grove/rules files for the Neovim plugin, without shelling out to cx.",
+	}
+	cmd.AddCommand(newRulesStatsCmd())
+	cmd.AddCommand(newRulesExplainCmd())
+	cmd.AddCommand(newRulesLintCmd())
+	cmd.AddCommand(newRulesFmtCmd())
+	return cmd
+}
+
+// ruleKind classifies one line of a rules file.
+type ruleKind int
+
+const (
+	ruleBlank ruleKind = iota
+	ruleComment
+	ruleSeparator
+	// ruleDirective covers configuration lines (@view:, @default, the cache
+	// directives) that never contribute files themselves.
+	ruleDirective
+	ruleInclude
+	ruleExclude
+	// ruleUnsupported is a rule cx understands but this package does not
+	// evaluate in-process (git URLs, @cmd:, ruleset imports, ...).
+	ruleUnsupported
+)
+
+// ruleLine is one parsed line of a rules file.
+type ruleLine struct {
+	Number int    // 1-based line number
+	Text   string // the line with surrounding whitespace trimmed
+	Indent int    // bytes of leading whitespace before Text
+	Kind   ruleKind
+
+	// Pattern is the path or glob with the "!" prefix, alias directive and
+	// inline search filter removed. For aliased rules it is the part after
+	// the alias; empty means "everything under the alias".
+	Pattern string
+	// Alias is the alias name without its @a:/@alias: directive, e.g.
+	// "grove-ecosystem:core" or "nb:work:inbox/note.md".
+	Alias string
+	// Filter is the inline @find:/@grep:/@grep-i: suffix, if any.
+	Filter *ruleFilter
+	// Reason explains why a ruleUnsupported line is not evaluated.
+	Reason string
+	// ContextAlias is, for floating patterns, the alias of the nearest
+	// aliased rule above. Floating patterns are anchored at that workspace,
+	// matching how rules.lua resolves them with line context.
+	ContextAlias string
+}
+
+// ruleFilter narrows a rule's matches by file name or content.
+type ruleFilter struct {
+	Kind  string // "find", "grep" or "grep-i"
+	Query string
+}
+
+// configDirectivePrefixes are lines that configure the context rather than
+// select files. They mirror the non-rule lines skipped by virtual_text.lua.
+var configDirectivePrefixes = []string{
+	"@view:", "@v:", "@default", "@freeze-cache", "@no-expire", "@disable-cache", "@expire-time",
+}
+
+// unsupportedRulePrefixes are rules cx resolves through machinery (git
+// clones, shell commands, diffs) that has no in-process equivalent here.
+var unsupportedRulePrefixes = []struct {
+	prefix string
+	reason string
+}{
+	{"@cmd:", "@cmd: rules are resolved by cx"},
+	{"@changed:", "@changed: rules are resolved by cx"},
+	{"@diff:", "@diff: rules are resolved by cx"},
+	{"@include:", "@include: rules are resolved by cx"},
+	{"@tree:", "@tree: rules are resolved by cx"},
+	{"@find:", "standalone searches are resolved by cx"},
+	{"@grep:", "standalone searches are resolved by cx"},
+	{"@grep-i:", "standalone searches are resolved by cx"},
+	{"git@", "git repository rules are resolved by cx"},
+	{"http://", "git repository rules are resolved by cx"},
+	{"https://", "git repository rules are resolved by cx"},
+}
+
+// parseRules splits rules file content into classified lines.
+func parseRules(content string) []ruleLine {
+	var lines []ruleLine
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	n := 0
+	contextAlias := ""
+	for scanner.Scan() {
+		n++
+		line := parseRuleLine(n, scanner.Text())
+		switch {
+		case line.Alias != "" && !strings.HasPrefix(line.Alias, "nb:"):
+			contextAlias = line.Alias
+		case line.isFloating():
+			line.ContextAlias = contextAlias
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseRuleLine classifies a single rules file line.
+func parseRuleLine(number int, raw string) ruleLine {
+	text := strings.TrimSpace(raw)
+	line := ruleLine{Number: number, Text: text, Indent: len(raw) - len(strings.TrimLeft(raw, " \t"))}
+
+	switch {
+	case text == "":
+		line.Kind = ruleBlank
+		return line
+	case strings.HasPrefix(text, "#"):
+		line.Kind = ruleComment
+		return line
+	case strings.HasPrefix(text, "---"):
+		line.Kind = ruleSeparator
+		return line
+	}
+	for _, prefix := range configDirectivePrefixes {
+		if strings.HasPrefix(text, prefix) {
+			line.Kind = ruleDirective
+			return line
+		}
+	}
+
+	line.Kind = ruleInclude
+	rule := text
+	if strings.HasPrefix(rule, "!") {
+		line.Kind = ruleExclude
+		rule = strings.TrimSpace(rule[1:])
+	}
+
+	for _, u := range unsupportedRulePrefixes {
+		if strings.HasPrefix(rule, u.prefix) {
+			line.Kind = ruleUnsupported
+			line.Reason = u.reason
+			return line
+		}
+	}
+
+	rule, line.Filter = splitInlineFilter(rule)
+
+	if name, rest, ok := cutAliasDirective(rule); ok {
+		if name == "git" {
+			line.Kind = ruleUnsupported
+			line.Reason = "git repository rules are resolved by cx"
+			return line
+		}
+		if strings.Contains(name, "::") {
+			line.Kind = ruleUnsupported
+			line.Reason = "ruleset imports are resolved by cx"
+			return line
+		}
+		line.Alias = name
+		line.Pattern = rest
+		return line
+	}
+
+	line.Pattern = rule
+	return line
+}
+
+// isFloating reports whether a rule is a bare pattern without a directory
+// component ("*.go", "README.md"), which matches at any depth.
+func (l ruleLine) isFloating() bool {
+	if l.Kind != ruleInclude && l.Kind != ruleExclude {
+		return false
+	}
+	return l.Alias == "" && !strings.Contains(l.Pattern, "/") && !strings.HasPrefix(l.Pattern, "~")
+}
+
+// cutAliasDirective splits "@a:eco:repo/src/**" into the alias name and the
+// pattern after it. Notebook aliases ("@a:nb:...") carry their resource path
+// inside the alias, so they are returned whole with an empty pattern. Git
+// repositories ("@a:git:owner/repo/**") are not workspaces; they come back
+// as the name "git" with "owner/repo/**" as the pattern.
+func cutAliasDirective(rule string) (name, rest string, ok bool) {
+	var body string
+	switch {
+	case strings.HasPrefix(rule, "@a:"):
+		body = rule[len("@a:"):]
+	case strings.HasPrefix(rule, "@alias:"):
+		body = rule[len("@alias:"):]
+	default:
+		return "", "", false
+	}
+	body = strings.TrimSpace(body)
+	if strings.HasPrefix(body, "nb:") {
+		return body, "", true
+	}
+	if rest, ok := strings.CutPrefix(body, "git:"); ok {
+		return "git", rest, true
+	}
+	name, rest, _ = strings.Cut(body, "/")
+	return name, rest, true
+}
+
+// splitInlineFilter separates a trailing " @find:", " @grep:" or " @grep-i:"
+// search from the rule it narrows.
+func splitInlineFilter(rule string) (string, *ruleFilter) {
+	for _, kind := range []string{"grep-i", "grep", "find"} {
+		marker := " @" + kind + ":"
+		idx := strings.Index(rule, marker)
+		if idx < 0 {
+			continue
+		}
+		query := strings.TrimSpace(rule[idx+len(marker):])
+		query = strings.Trim(query, `"`)
+		return strings.TrimSpace(rule[:idx]), &ruleFilter{Kind: kind, Query: query}
+	}
+	return rule, nil
+}
+
+// rulesBaseDir returns the directory relative patterns in a rules file are
+// resolved against: the project owning the .grove directory, the file's own
+// directory otherwise, and cwd when no file is known (stdin input).
+func rulesBaseDir(rulesFile string) (string, error) {
+	if rulesFile == "" {
+		return os.Getwd()
+	}
+	abs, err := filepath.Abs(rulesFile)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Dir(abs)
+	if filepath.Base(dir) == ".grove" {
+		return filepath.Dir(dir), nil
+	}
+	return dir, nil
+}
+
+// ruleResolver turns rule lines into the absolute file paths they match.
+// File listings are cached per root, so many rules against the same
+// workspace walk it once.
+type ruleResolver struct {
+	baseDir string
+	aliases *alias.AliasResolver
+	files   map[string][]string
+}
+
+func newRuleResolver(baseDir string) *ruleResolver {
+	return &ruleResolver{
+		baseDir: baseDir,
+		aliases: alias.NewAliasResolverWithWorkDir(baseDir),
+		files:   make(map[string][]string),
+	}
+}
+
+// root splits a rule into the directory to list and the slash-separated glob
+// to match against paths relative to it.
+func (r *ruleResolver) root(line ruleLine) (root, pattern string, err error) {
+	pattern = strings.TrimSuffix(filepath.ToSlash(line.Pattern), "/")
+
+	if line.Alias != "" {
+		resolved, err := r.resolveAlias(line.Alias)
+		if err != nil {
+			return "", "", err
+		}
+		if pattern == "" {
+			return splitStaticPrefix(filepath.ToSlash(resolved))
+		}
+		return resolved, pattern, nil
+	}
+
+	if pattern == "" {
+		return "", "", fmt.Errorf("empty rule")
+	}
+	if strings.HasPrefix(pattern, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", err
+		}
+		pattern = path.Join(filepath.ToSlash(home), pattern[2:])
+	}
+	if path.IsAbs(pattern) {
+		return splitStaticPrefix(pattern)
+	}
+	if strings.HasPrefix(pattern, "../") || strings.HasPrefix(pattern, "./") {
+		return splitStaticPrefix(path.Join(filepath.ToSlash(r.baseDir), pattern))
+	}
+	// Floating patterns ("*.go", "README.md") match at any depth under the
+	// workspace of the nearest alias above them, or the rules' own project.
+	if line.isFloating() {
+		root := r.baseDir
+		if line.ContextAlias != "" {
+			resolved, err := r.resolveAlias(line.ContextAlias)
+			if err != nil {
+				return "", "", err
+			}
+			root = resolved
+		}
+		return root, "**/" + pattern, nil
+	}
+	return r.baseDir, pattern, nil
+}
+
+// aliasError is a rule failing because its workspace alias does not resolve.
+type aliasError struct {
+	Alias string
+	Err   error
+}
+
+func (e *aliasError) Error() string {
+	return fmt.Sprintf("could not resolve alias '%s': %v", e.Alias, e.Err)
+}
+
+func (e *aliasError) Unwrap() error { return e.Err }
+
+func (r *ruleResolver) resolveAlias(name string) (string, error) {
+	resolved, err := r.aliases.Resolve(name)
+	if err != nil {
+		return "", &aliasError{Alias: name, Err: err}
+	}
+	return resolved, nil
+}
+
+// splitStaticPrefix divides an absolute pattern at its first glob segment.
+// A pattern without globs names a file or a directory; a directory matches
+// everything beneath it.
+func splitStaticPrefix(abs string) (root, pattern string, err error) {
+	segments := strings.Split(abs, "/")
+	for i, seg := range segments {
+		if hasGlobMeta(seg) {
+			root = strings.Join(segments[:i], "/")
+			if root == "" {
+				root = "/"
+			}
+			return filepath.FromSlash(root), strings.Join(segments[i:], "/"), nil
+		}
+	}
+	info, err := os.Stat(filepath.FromSlash(abs))
+	if err != nil {
+		return "", "", err
+	}
+	if info.IsDir() {
+		return filepath.FromSlash(abs), "**", nil
+	}
+	return filepath.Dir(filepath.FromSlash(abs)), path.Base(abs), nil
+}
+
+// Match returns the sorted absolute paths a rule matches, before exclusions.
+func (r *ruleResolver) Match(line ruleLine) ([]string, error) {
+	root, pattern, err := r.root(line)
+	if err != nil {
+		return nil, err
+	}
+	files, err := r.list(root)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := expandBraces(pattern)
+	var matches []string
+	for _, rel := range files {
+		if !matchesAny(patterns, rel) {
+			continue
+		}
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		if line.Filter != nil && !line.Filter.accepts(abs) {
+			continue
+		}
+		matches = append(matches, abs)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// MatchFile reports whether a rule matches one file, returning the root the
+// rule was anchored at. It checks the pattern first and only lists the root
+// (to honor .gitignore) when the pattern matches.
+func (r *ruleResolver) MatchFile(line ruleLine, file string) (root string, matched bool, err error) {
+	root, pattern, err := r.root(line)
+	if err != nil {
+		return "", false, err
+	}
+	rel, err := filepath.Rel(root, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return root, false, nil
+	}
+	rel = filepath.ToSlash(rel)
+	if !matchesAny(expandBraces(pattern), rel) {
+		return root, false, nil
+	}
+	if line.Filter != nil && !line.Filter.accepts(file) {
+		return root, false, nil
+	}
+	files, err := r.list(root)
+	if err != nil {
+		return "", false, err
+	}
+	for _, f := range files {
+		if f == rel {
+			return root, true, nil
+		}
+	}
+	return root, false, nil
+}
+
+// list returns the files under root relative to it, honoring .gitignore when
+// root is inside a git repository.
+func (r *ruleResolver) list(root string) ([]string, error) {
+	if files, ok := r.files[root]; ok {
+		return files, nil
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	files, err := gitListFiles(root)
+	if err != nil {
+		files, err = walkFiles(root)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r.files[root] = files
+	return files, nil
+}
+
+// gitListFiles lists tracked and untracked-but-not-ignored files under root.
+func gitListFiles(root string) ([]string, error) {
+	// #nosec G204 -- root is a resolved directory, not shell-interpreted
+	out, err := exec.Command("git", "-C", root, "ls-files", "--cached", "--others", "--exclude-standard").Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, rel := range strings.Split(string(out), "\n") {
+		if rel != "" {
+			files = append(files, rel)
+		}
+	}
+	return files, nil
+}
+
+// walkFiles is the listing fallback for roots outside any git repository.
+func walkFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// accepts reports whether a file passes an inline search filter.
+func (f *ruleFilter) accepts(abs string) bool {
+	switch f.Kind {
+	case "find":
+		return strings.Contains(filepath.Base(abs), f.Query)
+	case "grep", "grep-i":
+		data, err := os.ReadFile(abs) //nolint:gosec // path comes from a rules match
+		if err != nil {
+			return false
+		}
+		if f.Kind == "grep-i" {
+			return bytes.Contains(bytes.ToLower(data), bytes.ToLower([]byte(f.Query)))
+		}
+		return bytes.Contains(data, []byte(f.Query))
+	}
+	return true
+}
+
+func hasGlobMeta(s string) bool {
+	return strings.ContainsAny(s, "*?[{")
+}
+
+func matchesAny(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		if matchRulePattern(p, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRulePattern reports whether rel (slash-separated, relative to the
+// rule's root) is selected by pattern. A pattern that matches a directory
+// selects everything beneath it, so "cmd" and "pkg/**" behave alike.
+func matchRulePattern(pattern, rel string) bool {
+	patSegs := strings.Split(pattern, "/")
+	relSegs := strings.Split(rel, "/")
+	for n := len(relSegs); n > 0; n-- {
+		if matchSegments(patSegs, relSegs[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, where "**"
+// spans zero or more segments and every other segment uses path.Match.
+func matchSegments(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			rest := pat[1:]
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(rest, segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pat[0], segs[0]); err != nil || !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// expandBraces expands "{a,b}" alternations, which path.Match does not
+// support, into the full set of plain glob patterns.
+func expandBraces(pattern string) []string {
+	open := strings.Index(pattern, "{")
+	if open < 0 {
+		return []string{pattern}
+	}
+	depth := 0
+	for i := open; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				var out []string
+				for _, alt := range splitTopLevel(pattern[open+1 : i]) {
+					out = append(out, expandBraces(pattern[:open]+alt+pattern[i+1:])...)
+				}
+				return out
+			}
+		}
+	}
+	return []string{pattern}
+}
+
+// splitTopLevel splits s on commas that are not nested inside braces.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// rulesEvaluation is the outcome of applying every rule in a file. A file is
+// in context when some inclusion matches it and no exclusion does, regardless
+// of the order the two appear in — exclusions are global, as in cx.
+type rulesEvaluation struct {
+	Lines   []ruleLine
+	Matches map[int][]string // line number -> files matched by that rule
+	Errors  map[int]error    // line number -> resolution error
+
+	// IncludedBy maps each file to the first inclusion line matching it.
+	IncludedBy map[string]int
+	// ExcludedBy maps each file to the first exclusion line matching it.
+	ExcludedBy map[string]int
+}
+
+// evaluateRules resolves every inclusion and exclusion line.
+func evaluateRules(lines []ruleLine, r *ruleResolver) *rulesEvaluation {
+	eval := &rulesEvaluation{
+		Lines:      lines,
+		Matches:    make(map[int][]string),
+		Errors:     make(map[int]error),
+		IncludedBy: make(map[string]int),
+		ExcludedBy: make(map[string]int),
+	}
+	for _, line := range lines {
+		if line.Kind != ruleInclude && line.Kind != ruleExclude {
+			continue
+		}
+		matches, err := r.Match(line)
+		if err != nil {
+			eval.Errors[line.Number] = err
+			continue
+		}
+		eval.Matches[line.Number] = matches
+		owners := eval.IncludedBy
+		if line.Kind == ruleExclude {
+			owners = eval.ExcludedBy
+		}
+		for _, f := range matches {
+			if _, seen := owners[f]; !seen {
+				owners[f] = line.Number
+			}
+		}
+	}
+	return eval
+}
+
+// InContext reports whether a file survives the rules.
+func (e *rulesEvaluation) InContext(file string) bool {
+	_, included := e.IncludedBy[file]
+	_, excluded := e.ExcludedBy[file]
+	return included && !excluded
+}
+
+// Files returns every file in context, sorted.
+func (e *rulesEvaluation) Files() []string {
+	var files []string
+	for f := range e.IncludedBy {
+		if e.InContext(f) {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ruleVerdict is the final decision on whether a file is in context.
+const (
+	verdictIncluded    = "included"
+	verdictExcluded    = "excluded"
+	verdictNotIncluded = "not_included"
+)
+
+// ruleExplanation is the JSON document `rules explain` prints.
+type ruleExplanation struct {
+	Path      string `json:"path"`
+	RulesFile string `json:"rulesFile"`
+	Verdict   string `json:"verdict"`
+	// Steps lists every rule that matched the file, in file order.
+	Steps []ruleStep `json:"steps"`
+	// Unevaluated lists rules that could not be checked (unsupported rule
+	// types, unresolvable aliases), any of which might also match the file.
+	Unevaluated []ruleStep `json:"unevaluated,omitempty"`
+}
+
+// ruleStep records one rule's effect on the explained file.
+type ruleStep struct {
+	LineNumber int    `json:"lineNumber"`
+	Rule       string `json:"rule"`
+	// Action is "include" or "exclude" for matching rules.
+	Action string `json:"action,omitempty"`
+	// Scope describes how the pattern was anchored: "alias", "floating",
+	// "relative" or "absolute".
+	Scope  string `json:"scope,omitempty"`
+	Root   string `json:"root,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func newRulesExplainCmd() *cobra.Command {
+	var rulesFile string
+
+	cmd := &cobra.Command{
+		Use:   "explain <path>",
+		Short: "Explain why a file is or is not in context",
+		Long: `Evaluates every rule in the rules file against one file and prints, as
+JSON, each rule line that included or excluded it, in order, along with the
+final verdict. Without --rules-file the nearest .grove/rules above the
+file is used.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := filepath.Abs(args[0])
+			if err != nil {
+				return fmt.Errorf("resolve path: %w", err)
+			}
+			if rulesFile == "" {
+				found, err := findRulesFile(filepath.Dir(target))
+				if err != nil {
+					return err
+				}
+				rulesFile = found
+			}
+			content, err := os.ReadFile(rulesFile) //nolint:gosec // user-specified rules file
+			if err != nil {
+				return fmt.Errorf("failed to read rules file %s: %w", rulesFile, err)
+			}
+			baseDir, err := rulesBaseDir(rulesFile)
+			if err != nil {
+				return fmt.Errorf("resolve rules base directory: %w", err)
+			}
+
+			explanation := explainFile(parseRules(string(content)), newRuleResolver(baseDir), target)
+			explanation.RulesFile = rulesFile
+			return json.NewEncoder(os.Stdout).Encode(explanation)
+		},
+	}
+
+	cmd.Flags().StringVar(&rulesFile, "rules-file", "", "Rules file to evaluate (default: nearest .grove/rules above the file)")
+
+	return cmd
+}
+
+// findRulesFile walks up from start to the nearest .grove/rules. The walk
+// stops at the repository root (the first directory holding .git), so a
+// rules file outside the project is never picked up.
+func findRulesFile(start string) (string, error) {
+	start, err := filepath.Abs(start)
+	if err != nil {
+		return "", fmt.Errorf("resolve directory: %w", err)
+	}
+	for dir := start; ; dir = filepath.Dir(dir) {
+		candidate := filepath.Join(dir, ".grove", "rules")
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil || filepath.Dir(dir) == dir {
+			return "", fmt.Errorf("no .grove/rules found above %s; pass --rules-file", start)
+		}
+	}
+}
+
+// explainFile checks target against each rule in order. Exclusions are
+// global, so a single matching exclusion decides the verdict no matter where
+// it sits relative to the inclusions.
+func explainFile(lines []ruleLine, r *ruleResolver, target string) ruleExplanation {
+	ex := ruleExplanation{Path: target, Steps: []ruleStep{}}
+	included, excluded := false, false
+
+	for _, line := range lines {
+		switch line.Kind {
+		case ruleUnsupported:
+			ex.Unevaluated = append(ex.Unevaluated, ruleStep{
+				LineNumber: line.Number, Rule: line.Text, Reason: line.Reason,
+			})
+			continue
+		case ruleInclude, ruleExclude:
+		default:
+			continue
+		}
+
+		root, matched, err := r.MatchFile(line, target)
+		if err != nil {
+			ex.Unevaluated = append(ex.Unevaluated, ruleStep{
+				LineNumber: line.Number, Rule: line.Text, Reason: err.Error(),
+			})
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		step := ruleStep{LineNumber: line.Number, Rule: line.Text, Scope: ruleScope(line), Root: root}
+		if line.Kind == ruleExclude {
+			step.Action = "exclude"
+			excluded = true
+		} else {
+			step.Action = "include"
+			included = true
+		}
+		ex.Steps = append(ex.Steps, step)
+	}
+
+	switch {
+	case included && !excluded:
+		ex.Verdict = verdictIncluded
+	case included:
+		ex.Verdict = verdictExcluded
+	default:
+		ex.Verdict = verdictNotIncluded
+	}
+	return ex
+}
+
+// ruleScope names how a rule's pattern is anchored, using the same
+// distinctions rules.lua preview_rule_files draws.
+func ruleScope(line ruleLine) string {
+	p := line.Pattern
+	switch {
+	case line.Alias != "":
+		return "alias"
+	case filepath.IsAbs(p) || strings.HasPrefix(p, "~/"):
+		return "absolute"
+	case line.isFloating():
+		return "floating"
+	default:
+		return "relative"
+	}
+}
@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+func newRulesFmtCmd() *cobra.Command {
+	var write bool
+
+	cmd := &cobra.Command{
+		Use:   "fmt [file]",
+		Short: "Canonicalize a rules file",
+		Long: `Rewrites a rules file into canonical form: @alias: becomes @a:, absolute
+paths inside known workspaces and notebooks become aliases (the same mapping
+as 'internal resolve-aliases'), duplicate rules are dropped, and rules are
+grouped by workspace. Comments and the GROVE:MARKS block are preserved.
+
+Reads the file argument, or stdin when the argument is "-" or omitted, and
+prints the result. With --write the file is rewritten in place instead.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var file string
+			var content []byte
+			var err error
+			if len(args) > 0 && args[0] != "-" {
+				file = args[0]
+				content, err = os.ReadFile(file) //nolint:gosec // user-specified rules file
+			} else {
+				content, err = io.ReadAll(os.Stdin)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read rules: %w", err)
+			}
+			if write && file == "" {
+				return fmt.Errorf("--write requires a file argument")
+			}
+
+			// Discovery is only worth paying for when there is an absolute
+			// path to convert, so the aliaser is built on first use, and
+			// only attempted once.
+			var (
+				aliaser *pathAliaser
+				once    sync.Once
+			)
+			aliasPath := func(abs string) string {
+				once.Do(func() {
+					a, err := newPathAliaser()
+					if err != nil {
+						ulog.Debug("Workspace discovery failed; leaving absolute paths as-is").Err(err).Emit()
+						return
+					}
+					aliaser = a
+				})
+				if aliaser == nil {
+					return abs
+				}
+				return aliaser.Alias(abs)
+			}
+
+			formatted := formatRules(string(content), aliasPath)
+
+			if !write {
+				_, err := fmt.Fprint(os.Stdout, formatted)
+				return err
+			}
+			if formatted == string(content) {
+				return nil
+			}
+			info, err := os.Stat(file)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(file, []byte(formatted), info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&write, "write", "w", false, "Rewrite the file in place")
+
+	return cmd
+}
+
+// fmtOptions selects which rewrites formatWith applies. Prefix normalization
+// is always applied; it never changes what a rule matches.
+type fmtOptions struct {
+	convert bool // absolute paths -> aliases
+	dedupe  bool
+	group   bool
+}
+
+// fmtFallbacks are tried in order until one preserves the anchoring of every
+// floating pattern. Reordering, dropping an aliased duplicate, or turning an
+// absolute path into an alias can each change which alias a later floating
+// pattern ("*.go") hangs off, so a rewrite that would do that is abandoned
+// for a more conservative one.
+var fmtFallbacks = []fmtOptions{
+	{convert: true, dedupe: true, group: true},
+	{convert: true, dedupe: true},
+	{convert: true},
+	{dedupe: true, group: true},
+	{dedupe: true},
+	{},
+}
+
+// formatRules returns the canonical form of a rules file. aliasPath maps an
+// absolute path to its alias, returning the path unchanged when it has none.
+func formatRules(content string, aliasPath func(string) string) string {
+	lines := parseRules(content)
+	want := floatingAnchors(lines)
+	var out string
+	for _, opts := range fmtFallbacks {
+		out = formatWith(lines, opts, aliasPath)
+		if sameAnchors(want, floatingAnchors(parseRules(out))) {
+			return out
+		}
+	}
+	return out
+}
+
+// floatingAnchors records which alias each floating pattern is anchored at.
+func floatingAnchors(lines []ruleLine) map[string]bool {
+	anchors := make(map[string]bool)
+	for _, line := range lines {
+		if line.isFloating() {
+			anchors[line.ContextAlias+"\x00"+canonicalRule(line.Text)] = true
+		}
+	}
+	return anchors
+}
+
+func sameAnchors(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if !b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// fmtItem is a rule together with the comment lines directly above it.
+type fmtItem struct {
+	comments []string
+	text     string
+	key      string // workspace group
+	blank    bool   // a blank line kept as-is in ungrouped output
+	header   bool   // a section's leading comment block
+}
+
+func formatWith(lines []ruleLine, opts fmtOptions, aliasPath func(string) string) string {
+	var (
+		out       []string
+		items     []fmtItem
+		pending   []string // comments waiting for the rule they describe
+		seen      = make(map[string]bool)
+		inMarks   bool
+		prevBlank bool
+	)
+
+	// flush closes the current section at a barrier line (separator,
+	// directive, marks block), which rules are never moved across.
+	flush := func() {
+		out = append(out, emitSection(items, pending, opts.group)...)
+		if prevBlank {
+			out = append(out, "")
+		}
+		items, pending = nil, nil
+	}
+
+	for _, line := range lines {
+		blank := line.Kind == ruleBlank
+		switch {
+		case inMarks:
+			out = append(out, line.Text)
+			inMarks = !strings.HasPrefix(line.Text, marksBlockEnd)
+		case strings.HasPrefix(line.Text, marksBlockStart):
+			flush()
+			out = append(out, line.Text)
+			inMarks = true
+		case blank:
+			switch {
+			case !opts.group:
+				items = append(items, fmtItem{blank: true, comments: pending})
+				pending = nil
+			case len(items) == 0 && len(pending) > 0:
+				items = append(items, fmtItem{header: true, comments: pending})
+				pending = nil
+			case len(items) == 0:
+				out = append(out, "")
+			}
+		case line.Kind == ruleComment:
+			pending = append(pending, line.Text)
+		case line.Kind == ruleSeparator || line.Kind == ruleDirective:
+			flush()
+			out = append(out, normalizeAliasPrefix(line.Text))
+		default:
+			text := normalizeRule(line, opts.convert, aliasPath)
+			if opts.dedupe {
+				sig := line.ContextAlias + "\x00" + canonicalRule(text)
+				if seen[sig] {
+					continue // its comments carry over to the next rule
+				}
+				seen[sig] = true
+			}
+			items = append(items, fmtItem{
+				comments: pending,
+				text:     text,
+				key:      ruleGroupKey(parseRuleLine(line.Number, text), line.ContextAlias),
+			})
+			pending = nil
+		}
+		prevBlank = blank
+	}
+	flush()
+	return joinRuleLines(out)
+}
+
+// emitSection renders one section's rules, grouped by workspace when asked:
+// local rules first, then each workspace in order of first appearance, with
+// a blank line between groups.
+func emitSection(items []fmtItem, trailing []string, group bool) []string {
+	var out []string
+	if !group {
+		for _, it := range items {
+			out = append(out, it.comments...)
+			if it.blank {
+				out = append(out, "")
+			} else {
+				out = append(out, it.text)
+			}
+		}
+		return append(out, trailing...)
+	}
+
+	var order []string
+	groups := make(map[string][]fmtItem)
+	for _, it := range items {
+		if it.header {
+			out = append(out, it.comments...)
+			out = append(out, "")
+			continue
+		}
+		if _, ok := groups[it.key]; !ok {
+			if it.key == "" {
+				order = append([]string{""}, order...)
+			} else {
+				order = append(order, it.key)
+			}
+		}
+		groups[it.key] = append(groups[it.key], it)
+	}
+	for i, key := range order {
+		if i > 0 {
+			out = append(out, "")
+		}
+		for _, it := range groups[key] {
+			out = append(out, it.comments...)
+			out = append(out, it.text)
+		}
+	}
+	return append(out, trailing...)
+}
+
+// joinRuleLines collapses runs of blank lines and trims blank lines at
+// either end.
+func joinRuleLines(lines []string) string {
+	var b strings.Builder
+	prevBlank := true
+	pendingBlank := false
+	for _, l := range lines {
+		if l == "" {
+			if !prevBlank {
+				pendingBlank = true
+			}
+			continue
+		}
+		if pendingBlank {
+			b.WriteString("\n")
+			pendingBlank = false
+		}
+		b.WriteString(l)
+		b.WriteString("\n")
+		prevBlank = false
+	}
+	return b.String()
+}
+
+// aliasDirectiveRe matches the long alias spelling where a directive may
+// appear: at the start of a rule (after an optional "!") and after @view:,
+// @v: or @tree:.
+var aliasDirectiveRe = regexp.MustCompile(`(^!?\s*|@(?:view|v|tree):\s*)@alias:`)
+
+// normalizeAliasPrefix rewrites @alias: to the short @a: form.
+func normalizeAliasPrefix(text string) string {
+	return aliasDirectiveRe.ReplaceAllString(text, "${1}@a:")
+}
+
+// normalizeRule canonicalizes one rule's text, optionally replacing an
+// absolute path with its workspace or notebook alias.
+func normalizeRule(line ruleLine, convert bool, aliasPath func(string) string) string {
+	text := normalizeAliasPrefix(line.Text)
+	if !convert || line.Alias != "" || (line.Kind != ruleInclude && line.Kind != ruleExclude) {
+		return text
+	}
+	pattern := filepath.ToSlash(line.Pattern)
+	if !path.IsAbs(pattern) {
+		return text
+	}
+
+	static, rest := pattern, ""
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		if hasGlobMeta(seg) {
+			static = strings.Join(segments[:i], "/")
+			rest = strings.Join(segments[i:], "/")
+			break
+		}
+	}
+
+	alias := strings.TrimSuffix(aliasPath(filepath.FromSlash(static)), "/.")
+	if !strings.HasPrefix(alias, "@a:") {
+		return text
+	}
+	// Notebook aliases name one resource; a glob after one would not resolve.
+	if strings.HasPrefix(alias, "@a:nb:") && rest != "" {
+		return text
+	}
+	if rest != "" {
+		alias += "/" + rest
+	}
+	return strings.Replace(text, line.Pattern, alias, 1)
+}
+
+// ruleGroupKey names the workspace a rule belongs to for grouping: floating
+// patterns stay with the alias they are anchored at, notebook rules share one
+// group, and absolute paths outside any workspace share another.
+func ruleGroupKey(line ruleLine, contextAlias string) string {
+	if line.isFloating() {
+		return contextAlias
+	}
+	if name, _, ok := aliasReference(line); ok {
+		if strings.HasPrefix(name, "nb:") {
+			return "nb:"
+		}
+		return name
+	}
+	if path.IsAbs(filepath.ToSlash(line.Pattern)) {
+		return "/"
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAliases maps workspace roots to alias names for formatRules.
+func fakeAliases(roots map[string]string) func(string) string {
+	return func(abs string) string {
+		for root, name := range roots {
+			if abs == root {
+				return "@a:" + name + "/."
+			}
+			if strings.HasPrefix(abs, root+"/") {
+				return "@a:" + name + "/" + strings.TrimPrefix(abs, root+"/")
+			}
+		}
+		return abs
+	}
+}
+
+func TestFormatRulesNormalizesAndGroups(t *testing.T) {
+	in := `# Context for the plugin
+
+cmd/**
+@alias:core/pkg/**
+lua/**/*.lua
+/repos/core/config/*.go
+!/repos/core/config/*_test.go
+cmd/**
+/elsewhere/notes.md
+---
+@v: @alias:core
+`
+	want := `# Context for the plugin
+
+cmd/**
+lua/**/*.lua
+
+@a:core/pkg/**
+@a:core/config/*.go
+!@a:core/config/*_test.go
+
+/elsewhere/notes.md
+---
+@v: @a:core
+`
+	got := formatRules(in, fakeAliases(map[string]string{"/repos/core": "core"}))
+	assert.Equal(t, want, got)
+	assert.Equal(t, got, formatRules(got, fakeAliases(nil)), "formatting is idempotent")
+}
+
+func TestFormatRulesKeepsCommentsAndMarks(t *testing.T) {
+	in := `@a:core/pkg/**
+# the plugin itself
+lua/**
+` + marksBlockStart + `
+@alias:core/a.go
+@alias:core/a.go
+` + marksBlockEnd + `
+# trailing note
+`
+	want := `# the plugin itself
+lua/**
+
+@a:core/pkg/**
+` + marksBlockStart + `
+@alias:core/a.go
+@alias:core/a.go
+` + marksBlockEnd + `
+# trailing note
+`
+	assert.Equal(t, want, formatRules(in, fakeAliases(nil)), "the managed block is never rewritten")
+}
+
+// Moving a floating pattern away from the alias it follows would re-anchor
+// it, so grouping backs off rather than change what the rule matches.
+func TestFormatRulesPreservesFloatingAnchors(t *testing.T) {
+	// Dropping the repeated core alias in place would hand "*.go" to flow;
+	// grouping keeps it under core.
+	in := "@a:core/pkg/**\n@a:flow/cmd/**\n@a:core/pkg/**\n*.go\n"
+	got := formatRules(in, fakeAliases(nil))
+	assert.Equal(t, floatingAnchors(parseRules(in)), floatingAnchors(parseRules(got)))
+	assert.Equal(t, "@a:core/pkg/**\n*.go\n\n@a:flow/cmd/**\n", got)
+
+	// Converting an absolute path to an alias would capture the local
+	// floating pattern after it, so the pattern moves ahead of the alias.
+	in = "/repos/core/pkg/**\n*.md\n"
+	assert.Equal(t, "*.md\n\n@a:core/pkg/**\n", formatRules(in, fakeAliases(map[string]string{"/repos/core": "core"})))
+}
+
+func TestNormalizeAliasPrefix(t *testing.T) {
+	assert.Equal(t, "@a:core/**", normalizeAliasPrefix("@alias:core/**"))
+	assert.Equal(t, "!@a:core/**", normalizeAliasPrefix("!@alias:core/**"))
+	assert.Equal(t, "@view: @a:core", normalizeAliasPrefix("@view: @alias:core"))
+	assert.Equal(t, `pkg/** @grep: "@alias:"`, normalizeAliasPrefix(`pkg/** @grep: "@alias:"`))
+}
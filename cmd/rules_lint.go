@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/grovetools/core/config"
+	"github.com/spf13/cobra"
+)
+
+// The managed block marks.lua writes into the active rules file.
+const (
+	marksBlockStart = "# GROVE:MARKS:START"
+	marksBlockEnd   = "# GROVE:MARKS:END"
+)
+
+// Diagnostic severities, named after vim.diagnostic's levels.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// diagnostic is one finding, positioned for vim.diagnostic. Lines and columns
+// are 1-based; EndColumn is exclusive.
+type diagnostic struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Severity  string `json:"severity"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+}
+
+// lineDiagnostic spans a rule's text on its line.
+func lineDiagnostic(line ruleLine, severity, code, message string) diagnostic {
+	return diagnostic{
+		Line:      line.Number,
+		Column:    line.Indent + 1,
+		EndLine:   line.Number,
+		EndColumn: line.Indent + len(line.Text) + 1,
+		Severity:  severity,
+		Code:      code,
+		Message:   message,
+	}
+}
+
+// nvimConfig is the `nvim` section of grove.yml, grove-nvim's own. Keys
+// added to core's sections (such as `context`) never reach extensions, so
+// grove-nvim's settings live here.
+type nvimConfig struct {
+	TokenBudget int `yaml:"token_budget"`
+}
+
+// configTokenBudget reads nvim.token_budget from the grove config that
+// applies to dir; 0 when it is unset or the config cannot be loaded.
+func configTokenBudget(dir string) int {
+	cfg, err := config.LoadFrom(dir)
+	if err != nil || cfg == nil {
+		return 0
+	}
+	var nc nvimConfig
+	if err := cfg.UnmarshalExtension("nvim", &nc); err != nil {
+		return 0
+	}
+	return nc.TokenBudget
+}
+
+func newRulesLintCmd() *cobra.Command {
+	var (
+		rulesFile   string
+		tokenBudget int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "lint [file]",
+		Short: "Report problems in a rules file as JSON diagnostics",
+		Long: `Checks a rules file for duplicate rules, rules shadowed by later
+exclusions, patterns that match no files, unresolved @a:/@view: aliases, a
+damaged GROVE:MARKS block, and a context that exceeds the token budget.
+
+Reads the file argument, or stdin when the argument is "-" or omitted.
+--rules-file names the file stdin content belongs to. The budget comes from
+--token-budget, falling back to nvim.token_budget in the grove config.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var content []byte
+			var err error
+			if len(args) > 0 && args[0] != "-" {
+				rulesFile = args[0]
+				content, err = os.ReadFile(rulesFile) //nolint:gosec // user-specified rules file
+			} else {
+				content, err = io.ReadAll(os.Stdin)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read rules: %w", err)
+			}
+
+			baseDir, err := rulesBaseDir(rulesFile)
+			if err != nil {
+				return fmt.Errorf("resolve rules base directory: %w", err)
+			}
+
+			if !cmd.Flags().Changed("token-budget") {
+				tokenBudget = configTokenBudget(baseDir)
+			}
+
+			cache := loadTokenCache()
+			defer cache.Save()
+
+			diags := lintRules(parseRules(string(content)), newRuleResolver(baseDir), cache, tokenBudget)
+			return json.NewEncoder(os.Stdout).Encode(diags)
+		},
+	}
+
+	cmd.Flags().StringVar(&rulesFile, "rules-file", "", "Path of the rules file stdin content belongs to")
+	cmd.Flags().IntVar(&tokenBudget, "token-budget", 0, "Maximum context tokens (0 disables the check)")
+
+	return cmd
+}
+
+// lintRules runs every check and returns diagnostics sorted by position.
+func lintRules(lines []ruleLine, r *ruleResolver, cache *tokenCache, tokenBudget int) []diagnostic {
+	diags := []diagnostic{}
+	diags = append(diags, lintMarksBlock(lines)...)
+	diags = append(diags, lintDuplicates(lines)...)
+	diags = append(diags, lintAliases(lines, r)...)
+
+	eval := evaluateRules(lines, r)
+	diags = append(diags, lintMatches(eval)...)
+	if tokenBudget > 0 {
+		diags = append(diags, lintBudget(eval, cache, tokenBudget)...)
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Line != diags[j].Line {
+			return diags[i].Line < diags[j].Line
+		}
+		return diags[i].Column < diags[j].Column
+	})
+	return diags
+}
+
+// lintMarksBlock checks that the managed marks block appears at most once
+// and is properly closed.
+func lintMarksBlock(lines []ruleLine) []diagnostic {
+	var diags []diagnostic
+	var open *ruleLine
+	blocks := 0
+	for i := range lines {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line.Text, marksBlockStart):
+			if open != nil {
+				diags = append(diags, lineDiagnostic(line, severityError, "marks-block",
+					fmt.Sprintf("marks block opened on line %d is never closed before this one", open.Number)))
+			}
+			blocks++
+			if blocks == 2 {
+				diags = append(diags, lineDiagnostic(line, severityError, "marks-block",
+					"duplicate marks block; marks sync only maintains the first"))
+			}
+			open = &lines[i]
+		case strings.HasPrefix(line.Text, marksBlockEnd):
+			if open == nil {
+				diags = append(diags, lineDiagnostic(line, severityError, "marks-block",
+					"marks block end without a matching start"))
+			}
+			open = nil
+		}
+	}
+	if open != nil {
+		diags = append(diags, lineDiagnostic(*open, severityError, "marks-block",
+			"marks block is never closed"))
+	}
+	return diags
+}
+
+// canonicalRule normalizes spelling differences that do not change a rule's
+// meaning, so "@alias:x" and "@a:x" count as duplicates.
+func canonicalRule(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	text = strings.Replace(text, "@alias:", "@a:", 1)
+	text = strings.Replace(text, "@v:", "@view:", 1)
+	return text
+}
+
+// lintDuplicates flags rules repeated verbatim (modulo alias spelling).
+func lintDuplicates(lines []ruleLine) []diagnostic {
+	var diags []diagnostic
+	seen := make(map[string]int)
+	for _, line := range lines {
+		switch line.Kind {
+		case ruleInclude, ruleExclude, ruleUnsupported, ruleDirective:
+		default:
+			continue
+		}
+		// Floating patterns under different aliases are different rules.
+		key := line.ContextAlias + "\x00" + canonicalRule(line.Text)
+		if first, ok := seen[key]; ok {
+			diags = append(diags, lineDiagnostic(line, severityWarning, "duplicate",
+				fmt.Sprintf("duplicate of line %d", first)))
+			continue
+		}
+		seen[key] = line.Number
+	}
+	return diags
+}
+
+// aliasReference locates the workspace alias a line refers to, including
+// aliases inside @view: directives and ruleset imports. The returned name is
+// what the alias resolver accepts; column is the 1-based start of the name.
+func aliasReference(line ruleLine) (name string, column int, ok bool) {
+	if line.Kind == ruleBlank || line.Kind == ruleComment || line.Kind == ruleSeparator {
+		return "", 0, false
+	}
+	idx, prefixLen := strings.Index(line.Text, "@a:"), len("@a:")
+	if alt := strings.Index(line.Text, "@alias:"); alt >= 0 && (idx < 0 || alt < idx) {
+		idx, prefixLen = alt, len("@alias:")
+	}
+	if idx < 0 {
+		return "", 0, false
+	}
+	body := line.Text[idx+prefixLen:]
+	if end := strings.IndexAny(body, " \t"); end >= 0 {
+		body = body[:end]
+	}
+	if base, _, found := strings.Cut(body, "::"); found {
+		body = base
+	}
+	if strings.HasPrefix(body, "git:") {
+		// Git repositories are cloned by cx, not resolved as workspaces.
+		return "", 0, false
+	}
+	if !strings.HasPrefix(body, "nb:") {
+		body, _, _ = strings.Cut(body, "/")
+	}
+	if body == "" {
+		return "", 0, false
+	}
+	return body, line.Indent + idx + prefixLen + 1, true
+}
+
+// lintAliases resolves every alias reference once and flags the failures.
+func lintAliases(lines []ruleLine, r *ruleResolver) []diagnostic {
+	var diags []diagnostic
+	failed := make(map[string]error)
+	resolved := make(map[string]bool)
+	for _, line := range lines {
+		name, col, ok := aliasReference(line)
+		if !ok {
+			continue
+		}
+		if !resolved[name] {
+			if _, err := r.resolveAlias(name); err != nil {
+				failed[name] = err
+			}
+			resolved[name] = true
+		}
+		if err, bad := failed[name]; bad {
+			diags = append(diags, diagnostic{
+				Line:      line.Number,
+				Column:    col,
+				EndLine:   line.Number,
+				EndColumn: col + len(name),
+				Severity:  severityError,
+				Code:      "unresolved-alias",
+				Message:   err.Error(),
+			})
+		}
+	}
+	return diags
+}
+
+// lintMatches flags rules that match nothing and inclusions whose every
+// file is removed by a later exclusion.
+func lintMatches(eval *rulesEvaluation) []diagnostic {
+	var diags []diagnostic
+	for _, line := range eval.Lines {
+		if line.Kind != ruleInclude && line.Kind != ruleExclude {
+			continue
+		}
+		if err, ok := eval.Errors[line.Number]; ok {
+			var aliasErr *aliasError
+			if errors.As(err, &aliasErr) {
+				continue // reported by lintAliases
+			}
+			diags = append(diags, lineDiagnostic(line, severityWarning, "no-match",
+				fmt.Sprintf("pattern matches no files: %v", err)))
+			continue
+		}
+
+		matches := eval.Matches[line.Number]
+		if len(matches) == 0 {
+			diags = append(diags, lineDiagnostic(line, severityWarning, "no-match", "pattern matches no files"))
+			continue
+		}
+		if line.Kind != ruleInclude {
+			continue
+		}
+
+		shadowing := make(map[int]bool)
+		for _, f := range matches {
+			by, excluded := eval.ExcludedBy[f]
+			if !excluded || by < line.Number {
+				shadowing = nil
+				break
+			}
+			shadowing[by] = true
+		}
+		if len(shadowing) == 0 {
+			continue
+		}
+		excluders := make([]int, 0, len(shadowing))
+		for n := range shadowing {
+			excluders = append(excluders, n)
+		}
+		sort.Ints(excluders)
+		refs := make([]string, len(excluders))
+		for i, n := range excluders {
+			refs[i] = fmt.Sprintf("%d", n)
+		}
+		diags = append(diags, lineDiagnostic(line, severityWarning, "shadowed",
+			fmt.Sprintf("all %d files matched by this rule are excluded by line %s", len(matches), strings.Join(refs, ", "))))
+	}
+	return diags
+}
+
+// lintBudget flags the rule at which the running context size crosses the
+// token budget.
+func lintBudget(eval *rulesEvaluation, cache *tokenCache, budget int) []diagnostic {
+	stats := computeLineStats(eval, cache)
+	total := 0
+	for _, s := range stats {
+		total += s.TotalTokens
+	}
+	if total <= budget {
+		return nil
+	}
+
+	byNumber := make(map[int]ruleLine, len(eval.Lines))
+	for _, line := range eval.Lines {
+		byNumber[line.Number] = line
+	}
+	running := 0
+	for _, s := range stats {
+		running += s.TotalTokens
+		if running > budget {
+			return []diagnostic{lineDiagnostic(byNumber[s.LineNumber], severityError, "token-budget",
+				fmt.Sprintf("context exceeds the %d token budget here (~%d tokens by this rule, ~%d total)", budget, running, total))}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func codes(diags []diagnostic) map[int][]string {
+	out := make(map[int][]string)
+	for _, d := range diags {
+		out[d.Line] = append(out[d.Line], d.Code)
+	}
+	return out
+}
+
+func TestLintMarksBlock(t *testing.T) {
+	lines := parseRules(marksBlockStart + "\n@a:core/a.go\n" + marksBlockEnd + "\n" +
+		marksBlockStart + "\n@a:core/b.go\n" + marksBlockEnd + "\n" +
+		marksBlockEnd + "\n" +
+		marksBlockStart + "\n")
+
+	assert.Equal(t, map[int][]string{
+		4: {"marks-block"}, // second block
+		7: {"marks-block"}, // stray end
+		8: {"marks-block"}, // never closed
+	}, codes(lintMarksBlock(lines)))
+}
+
+func TestLintDuplicates(t *testing.T) {
+	lines := parseRules("*.go\n@a:core/pkg/**\n@alias:core/pkg/**\n# *.go\n  *.go\n  *.go\n")
+	diags := lintDuplicates(lines)
+	require.Len(t, diags, 2, "a floating pattern repeated under another alias is not a duplicate")
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Equal(t, "duplicate of line 2", diags[0].Message)
+	assert.Equal(t, 6, diags[1].Line)
+	assert.Equal(t, "duplicate of line 5", diags[1].Message)
+	assert.Equal(t, 3, diags[1].Column, "column points past the indentation")
+}
+
+func TestAliasReference(t *testing.T) {
+	cases := []struct {
+		raw    string
+		name   string
+		column int
+	}{
+		{"@a:eco:core/pkg/**", "eco:core", 4},
+		{"!@alias:core/docs", "core", 9},
+		{"@view: @a:eco:core::default", "eco:core", 11},
+		{"@a:nb:work:inbox/x.md", "nb:work:inbox/x.md", 4},
+	}
+	for _, tc := range cases {
+		name, col, ok := aliasReference(parseRuleLine(1, tc.raw))
+		require.True(t, ok, tc.raw)
+		assert.Equal(t, tc.name, name, tc.raw)
+		assert.Equal(t, tc.column, col, tc.raw)
+	}
+
+	_, _, ok := aliasReference(parseRuleLine(1, "# @a:core"))
+	assert.False(t, ok, "comments carry no alias")
+	_, _, ok = aliasReference(parseRuleLine(1, "@a:git:grovetools/core/**/*.go"))
+	assert.False(t, ok, "git repositories are not workspace aliases")
+}
+
+func TestLintMatchesAndBudget(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"cmd/root.go":    "package cmd ...................",
+		"docs/guide.md":  "# guide",
+		"docs/README.md": "# readme",
+	})
+	lines := parseRules("cmd/**\ndocs/**\n*.rs\n!docs/**\n")
+	eval := evaluateRules(lines, newRuleResolver(root))
+
+	assert.Equal(t, map[int][]string{
+		2: {"shadowed"},
+		3: {"no-match"},
+	}, codes(lintMatches(eval)))
+
+	cache := &tokenCache{entries: make(map[string]tokenCacheEntry)}
+	diags := lintBudget(eval, cache, 5)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 1, diags[0].Line)
+	assert.Equal(t, "token-budget", diags[0].Code)
+	assert.Empty(t, lintBudget(eval, cache, 1000))
+}
+
+func TestConfigTokenBudget(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	root := writeTree(t, map[string]string{
+		"grove.yml": "name: demo\ncontext:\n  repos_dir: /tmp/repos\nnvim:\n  token_budget: 50000\n",
+	})
+	assert.Equal(t, 50000, configTokenBudget(root))
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "grove.yml"), []byte("name: demo\ncontext:\n  token_budget: 50000\n"), 0o600))
+	assert.Equal(t, 0, configTokenBudget(root), "context is core's section; keys added there are dropped")
+}
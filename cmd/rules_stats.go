@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// lineStats is the per-line shape `cx stats --per-line` emits, kept
+// field-for-field so virtual_text.lua can consume either producer.
+type lineStats struct {
+	Rule              string      `json:"rule"`
+	LineNumber        int         `json:"lineNumber"`
+	FileCount         int         `json:"fileCount"`
+	TotalTokens       int         `json:"totalTokens"`
+	ResolvedPaths     []string    `json:"resolvedPaths,omitempty"`
+	ExcludedFileCount int         `json:"excludedFileCount,omitempty"`
+	ExcludedTokens    int         `json:"excludedTokens,omitempty"`
+	FilteredByLine    []lineCount `json:"filteredByLine,omitempty"`
+	ExcludedByLine    []lineCount `json:"excludedByLine,omitempty"`
+	SkipReason        string      `json:"skipReason,omitempty"`
+	Severity          string      `json:"severity,omitempty"`
+	// Unsupported marks rules left to cx; their counts are unknown here.
+	Unsupported bool `json:"unsupported,omitempty"`
+}
+
+// lineCount attributes a number of files to another rules line.
+type lineCount struct {
+	LineNumber int `json:"lineNumber"`
+	Count      int `json:"count"`
+}
+
+func newRulesStatsCmd() *cobra.Command {
+	var (
+		perLine   bool
+		rulesFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Compute file and token counts for rules read from stdin",
+		Long: `Reads rules file content from stdin and prints file counts and token
+estimates as JSON. With --per-line the output is one entry per rule line, in
+the same shape as 'cx stats --per-line'. --rules-file names the file the
+content belongs to, so relative patterns resolve against its project.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			content, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read from stdin: %w", err)
+			}
+			baseDir, err := rulesBaseDir(rulesFile)
+			if err != nil {
+				return fmt.Errorf("resolve rules base directory: %w", err)
+			}
+
+			eval := evaluateRules(parseRules(string(content)), newRuleResolver(baseDir))
+
+			cache := loadTokenCache()
+			defer cache.Save()
+
+			var result any
+			if perLine {
+				result = computeLineStats(eval, cache)
+			} else {
+				result = computeTotals(eval, cache)
+			}
+			return json.NewEncoder(os.Stdout).Encode(result)
+		},
+	}
+
+	cmd.Flags().BoolVar(&perLine, "per-line", false, "Report stats for each rule line")
+	cmd.Flags().StringVar(&rulesFile, "rules-file", "", "Path of the rules file the content belongs to")
+
+	return cmd
+}
+
+// rulesTotals summarizes the whole context.
+type rulesTotals struct {
+	FileCount   int `json:"fileCount"`
+	TotalTokens int `json:"totalTokens"`
+}
+
+func computeTotals(eval *rulesEvaluation, cache *tokenCache) rulesTotals {
+	var totals rulesTotals
+	for _, f := range eval.Files() {
+		tokens, ok := cache.Tokens(f)
+		if !ok {
+			continue
+		}
+		totals.FileCount++
+		totals.TotalTokens += tokens
+	}
+	return totals
+}
+
+// computeLineStats builds one entry per inclusion, exclusion and unsupported
+// rule line. Files are attributed to the first inclusion that matches them;
+// later inclusions matching the same files report them in FilteredByLine.
+func computeLineStats(eval *rulesEvaluation, cache *tokenCache) []lineStats {
+	stats := []lineStats{}
+	for _, line := range eval.Lines {
+		switch line.Kind {
+		case ruleInclude, ruleExclude, ruleUnsupported:
+		default:
+			continue
+		}
+
+		s := lineStats{Rule: line.Text, LineNumber: line.Number}
+		if line.Kind == ruleUnsupported {
+			s.SkipReason = line.Reason
+			s.Severity = "Notice"
+			s.Unsupported = true
+			stats = append(stats, s)
+			continue
+		}
+		if err, ok := eval.Errors[line.Number]; ok {
+			s.SkipReason = err.Error()
+			s.Severity = "Error"
+			stats = append(stats, s)
+			continue
+		}
+
+		filtered := make(map[int]int)
+		excluded := make(map[int]int)
+		for _, f := range eval.Matches[line.Number] {
+			tokens, ok := cache.Tokens(f)
+			if !ok {
+				continue // binary or unreadable: never part of context
+			}
+
+			if line.Kind == ruleExclude {
+				if _, included := eval.IncludedBy[f]; included {
+					s.ExcludedFileCount++
+					s.ExcludedTokens += tokens
+				}
+				continue
+			}
+
+			if by, ok := eval.ExcludedBy[f]; ok {
+				s.ExcludedFileCount++
+				s.ExcludedTokens += tokens
+				excluded[by]++
+				continue
+			}
+			if owner := eval.IncludedBy[f]; owner != line.Number {
+				filtered[owner]++
+				continue
+			}
+			s.FileCount++
+			s.TotalTokens += tokens
+			s.ResolvedPaths = append(s.ResolvedPaths, f)
+		}
+		s.FilteredByLine = sortedLineCounts(filtered)
+		s.ExcludedByLine = sortedLineCounts(excluded)
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+func sortedLineCounts(counts map[int]int) []lineCount {
+	if len(counts) == 0 {
+		return nil
+	}
+	out := make([]lineCount, 0, len(counts))
+	for line, count := range counts {
+		out = append(out, lineCount{LineNumber: line, Count: count})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LineNumber < out[j].LineNumber })
+	return out
+}
+
+// tokenCache persists per-file token estimates keyed by path, invalidated by
+// mtime and size. Rules buffers are re-analysed on every debounced edit, and
+// without it each pass would re-read every matched file.
+type tokenCache struct {
+	path    string
+	entries map[string]tokenCacheEntry
+	dirty   bool
+}
+
+type tokenCacheEntry struct {
+	ModTime int64 `json:"mtime"`
+	Size    int64 `json:"size"`
+	Tokens  int   `json:"tokens"`
+	Binary  bool  `json:"binary,omitempty"`
+}
+
+// loadTokenCache reads the on-disk cache. A missing or corrupt cache is not
+// an error — it just starts empty.
+func loadTokenCache() *tokenCache {
+	c := &tokenCache{path: cachePath("token-counts.json")}
+	if !readCache(c.path, &c.entries) || c.entries == nil {
+		c.entries = make(map[string]tokenCacheEntry)
+	}
+	return c
+}
+
+// Tokens returns the token estimate for a file, and false when the file is
+// binary or cannot be read.
+func (c *tokenCache) Tokens(file string) (int, bool) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return 0, false
+	}
+	mtime := info.ModTime().UnixNano()
+	if e, ok := c.entries[file]; ok && e.ModTime == mtime && e.Size == info.Size() {
+		return e.Tokens, !e.Binary
+	}
+
+	data, err := os.ReadFile(file) //nolint:gosec // path comes from a rules match
+	if err != nil {
+		return 0, false
+	}
+	entry := tokenCacheEntry{ModTime: mtime, Size: info.Size()}
+	if isBinary(data) {
+		entry.Binary = true
+	} else {
+		entry.Tokens = estimateTokens(data)
+	}
+	c.entries[file] = entry
+	c.dirty = true
+	return entry.Tokens, !entry.Binary
+}
+
+// Save writes the cache back if anything changed.
+func (c *tokenCache) Save() {
+	if !c.dirty {
+		return
+	}
+	_ = writeCache(c.path, c.entries)
+}
+
+// estimateTokens approximates a tokenizer at four characters per token, the
+// same heuristic cx uses for its stats.
+func estimateTokens(data []byte) int {
+	return utf8.RuneCount(data) / 4
+}
+
+// isBinary uses git's heuristic: a NUL byte in the first 8000 bytes.
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
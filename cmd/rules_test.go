@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRuleLine(t *testing.T) {
+	cases := []struct {
+		raw     string
+		kind    ruleKind
+		alias   string
+		pattern string
+	}{
+		{"", ruleBlank, "", ""},
+		{"  # comment", ruleComment, "", ""},
+		{"---", ruleSeparator, "", ""},
+		{"@view: @a:core", ruleDirective, "", ""},
+		{"@default: dev", ruleDirective, "", ""},
+		{"cmd/**/*.go", ruleInclude, "", "cmd/**/*.go"},
+		{"!**/*_test.go", ruleExclude, "", "**/*_test.go"},
+		{"@a:eco:core/pkg/**", ruleInclude, "eco:core", "pkg/**"},
+		{"@alias:core", ruleInclude, "core", ""},
+		{"!@a:core/docs/**", ruleExclude, "core", "docs/**"},
+		{"@a:nb:work:inbox/note.md", ruleInclude, "nb:work:inbox/note.md", ""},
+		{"@a:eco:core::default", ruleUnsupported, "", ""},
+		{"@a:git:grovetools/core/**/*.go", ruleUnsupported, "", ""},
+		{"@cmd: git ls-files", ruleUnsupported, "", ""},
+		{"https://github.com/grovetools/core@v1", ruleUnsupported, "", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.raw, func(t *testing.T) {
+			line := parseRuleLine(1, tc.raw)
+			assert.Equal(t, tc.kind, line.Kind)
+			assert.Equal(t, tc.alias, line.Alias)
+			assert.Equal(t, tc.pattern, line.Pattern)
+		})
+	}
+}
+
+func TestParseRuleLineInlineFilter(t *testing.T) {
+	line := parseRuleLine(3, `pkg/** @grep-i: "TODO"`)
+	assert.Equal(t, "pkg/**", line.Pattern)
+	require.NotNil(t, line.Filter)
+	assert.Equal(t, "grep-i", line.Filter.Kind)
+	assert.Equal(t, "TODO", line.Filter.Query)
+}
+
+func TestMatchRulePattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/root.go", true},
+		{"cmd/*.go", "cmd/root.go", true},
+		{"cmd/*.go", "cmd/sub/root.go", false},
+		// A pattern naming a directory selects everything beneath it.
+		{"cmd", "cmd/sub/root.go", true},
+		{"**/docs", "pkg/docs/docs.json", true},
+		{"cmd", "cmdline/root.go", false},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, matchRulePattern(tc.pattern, tc.rel), "%s ~ %s", tc.pattern, tc.rel)
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	assert.Equal(t, []string{"**/*.go", "**/*.lua"}, expandBraces("**/*.{go,lua}"))
+	assert.Equal(t, []string{"a/x", "a/y/1", "a/y/2"}, expandBraces("a/{x,y/{1,2}}"))
+	assert.Equal(t, []string{"plain"}, expandBraces("plain"))
+}
+
+// writeTree creates files (relative path -> content) under a fresh temp dir.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o750))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	}
+	return root
+}
+
+func TestComputeLineStats(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"main.go":          "package main // 24 chars",
+		"cmd/root.go":      "package cmd",
+		"cmd/root_test.go": "package cmd",
+		"README.md":        "# readme",
+		"logo.png":         "\x89PNG\x00\x00",
+	})
+
+	rules := "# context\n*.go\ncmd/**\n!**/*_test.go\n*.png\nmissing/**\n/nonexistent-grove-root/**\n@a:git:grovetools/core/**\n"
+	eval := evaluateRules(parseRules(rules), newRuleResolver(root))
+	cache := &tokenCache{entries: make(map[string]tokenCacheEntry)}
+	stats := computeLineStats(eval, cache)
+
+	byLine := make(map[int]lineStats)
+	for _, s := range stats {
+		byLine[s.LineNumber] = s
+	}
+	require.Len(t, stats, 7, "comments produce no stats")
+
+	goRule := byLine[2]
+	assert.Equal(t, 2, goRule.FileCount, "main.go and cmd/root.go")
+	assert.Equal(t, 1, goRule.ExcludedFileCount)
+	assert.Equal(t, []lineCount{{LineNumber: 4, Count: 1}}, goRule.ExcludedByLine)
+	assert.Equal(t, 6+2, goRule.TotalTokens)
+
+	// cmd/root.go was already claimed by line 2.
+	cmdRule := byLine[3]
+	assert.Equal(t, 0, cmdRule.FileCount)
+	assert.Equal(t, []lineCount{{LineNumber: 2, Count: 1}}, cmdRule.FilteredByLine)
+
+	assert.Equal(t, 1, byLine[4].ExcludedFileCount)
+	assert.Equal(t, 0, byLine[5].FileCount, "binary files are never counted")
+	assert.Equal(t, 0, byLine[6].FileCount)
+	assert.Empty(t, byLine[6].Severity, "a pattern matching nothing is not an error")
+	assert.Equal(t, "Error", byLine[7].Severity)
+	assert.True(t, byLine[8].Unsupported, "git rules are left to cx")
+	assert.Equal(t, "Notice", byLine[8].Severity)
+
+	assert.True(t, eval.InContext(filepath.Join(root, "cmd", "root.go")))
+	assert.False(t, eval.InContext(filepath.Join(root, "cmd", "root_test.go")))
+	assert.False(t, eval.InContext(filepath.Join(root, "README.md")))
+}
+
+func TestRulesBaseDir(t *testing.T) {
+	dir, err := rulesBaseDir("/repo/.grove/rules")
+	require.NoError(t, err)
+	assert.Equal(t, "/repo", dir)
+
+	dir, err = rulesBaseDir("/notebook/rules/dev.rules")
+	require.NoError(t, err)
+	assert.Equal(t, "/notebook/rules", dir)
+}
+
+func TestParseRulesFloatingContext(t *testing.T) {
+	lines := parseRules("*.md\n@a:eco:core/pkg/**\n*.go\n!*_test.go\n@a:nb:work:inbox/x.md\n*.txt\n")
+	assert.Equal(t, "", lines[0].ContextAlias, "no alias above the first rule")
+	assert.Equal(t, "eco:core", lines[2].ContextAlias)
+	assert.Equal(t, "eco:core", lines[3].ContextAlias, "exclusions float too")
+	assert.Equal(t, "eco:core", lines[5].ContextAlias, "notebook aliases do not anchor floating patterns")
+}
+
+func TestExplainFile(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"cmd/root.go":      "package cmd",
+		"cmd/root_test.go": "package cmd",
+		"docs/guide.md":    "# guide",
+	})
+	rules := parseRules("cmd/**\n*.go\n!*_test.go\n@cmd: ls\n")
+	r := newRuleResolver(root)
+
+	ex := explainFile(rules, r, filepath.Join(root, "cmd", "root_test.go"))
+	assert.Equal(t, verdictExcluded, ex.Verdict)
+	require.Len(t, ex.Steps, 3)
+	assert.Equal(t, "include", ex.Steps[0].Action)
+	assert.Equal(t, "relative", ex.Steps[0].Scope)
+	assert.Equal(t, "floating", ex.Steps[1].Scope)
+	assert.Equal(t, "exclude", ex.Steps[2].Action)
+	require.Len(t, ex.Unevaluated, 1)
+	assert.Equal(t, 4, ex.Unevaluated[0].LineNumber)
+
+	assert.Equal(t, verdictIncluded, explainFile(rules, r, filepath.Join(root, "cmd", "root.go")).Verdict)
+
+	ex = explainFile(rules, r, filepath.Join(root, "docs", "guide.md"))
+	assert.Equal(t, verdictNotIncluded, ex.Verdict)
+	assert.Empty(t, ex.Steps)
+}
+
+func TestFindRulesFile(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".grove/rules":      "*.go",
+		"cmd/sub/file.go":   "package sub",
+		"other/.grove/keep": "",
+		"repo/.git/HEAD":    "ref: refs/heads/main",
+		"repo/src/main.go":  "package main",
+	})
+
+	found, err := findRulesFile(filepath.Join(root, "cmd", "sub"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".grove", "rules"), found)
+
+	// The walk stops at repo/, the repository root, before reaching the
+	// rules file above it.
+	_, err = findRulesFile(filepath.Join(root, "repo", "src"))
+	assert.Error(t, err)
+}
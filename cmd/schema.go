@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// chatSchemaVersion changes when fields are renamed or removed, so editor
+// caches of the schema can be invalidated.
+const chatSchemaVersion = 1
+
+// schemaValue is an allowed value of an enum field.
+type schemaValue struct {
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+// schemaField is a frontmatter field or directive key. Values names the
+// dynamic source completions should offer ("templates", "models", "skills",
+// "jobs"); Enum lists fixed values.
+type schemaField struct {
+	Name        string        `json:"name"`
+	Type        string        `json:"type"` // "string", "list", "int", "bool" or "map"
+	Description string        `json:"description"`
+	Enum        []schemaValue `json:"enum,omitempty"`
+	Values      string        `json:"values,omitempty"`
+	Internal    bool          `json:"internal,omitempty"` // written by tools, not by hand
+}
+
+// chatSchema describes the keys of chat and job files.
+type chatSchema struct {
+	Version     int           `json:"version"`
+	Directive   []schemaField `json:"directive"`
+	Frontmatter []schemaField `json:"frontmatter"`
+	JobTypes    []schemaValue `json:"jobTypes"`
+	Statuses    []schemaValue `json:"statuses"`
+}
+
+// jobTypes and jobStatuses are the values flow accepts for a job's type and
+// status, in the order its TUI lists them.
+var (
+	jobTypes = []schemaValue{
+		{"chat", "Multi-turn conversation in the job file"},
+		{"oneshot", "Single prompt and response written to the job file"},
+		{"interactive_agent", "Agent session in a tmux window, driven by the user"},
+		{"headless_agent", "Agent session that runs to completion unattended"},
+		{"shell", "Shell command from the job body"},
+	}
+	jobStatuses = []schemaValue{
+		{"pending", "Ready to run once its dependencies complete"},
+		{"pending_user", "Waiting for the next user turn"},
+		{"pending_llm", "Waiting for a model response"},
+		{"running", "Being executed"},
+		{"completed", "Finished successfully"},
+		{"failed", "Finished with an error"},
+		{"blocked", "A dependency failed or is missing"},
+		{"needs_review", "Finished, waiting for review"},
+		{"hold", "Paused by the user"},
+		{"interrupted", "Stopped before it finished"},
+		{"abandoned", "No longer pursued"},
+		{"todo", "Placeholder not ready to run"},
+	}
+	turnStates = []schemaValue{
+		{"running", "Placeholder for a response in progress"},
+	}
+)
+
+// newChatSchema is the built-in schema of job frontmatter and chat
+// directives, and the single place grove-nvim lists them. flow's job model
+// lives outside this module, so the fields follow the YAML tags of flow's Job
+// type and the keys the chat commands here write. loadChatSchema lays flow's
+// own report over it; the table is the fallback when flow cannot give one.
+func newChatSchema() *chatSchema {
+	return &chatSchema{
+		Version: chatSchemaVersion,
+		Directive: []schemaField{
+			{Name: "template", Type: "string", Description: "Opens a user turn rendered with this template", Values: "templates"},
+			{Name: "model", Type: "string", Description: "Model for the response to this turn", Values: "models"},
+			{Name: "id", Type: "string", Description: "Marks an LLM response", Internal: true},
+			{Name: "state", Type: "string", Description: "State of a response placeholder", Enum: turnStates, Internal: true},
+			{Name: "timestamp", Type: "string", Description: "When the response was written (RFC 3339)", Internal: true},
+			{Name: "usage", Type: "map", Description: "Token counts recorded for the response", Internal: true},
+		},
+		Frontmatter: []schemaField{
+			{Name: "id", Type: "string", Description: "Unique job id within the plan"},
+			{Name: "title", Type: "string", Description: "Human-readable job title"},
+			{Name: "type", Type: "string", Description: "How flow runs the job", Enum: jobTypes},
+			{Name: "status", Type: "string", Description: "Lifecycle state of the job", Enum: jobStatuses},
+			{Name: "model", Type: "string", Description: "Model for LLM jobs; defaults to the plan's or flow's model", Values: "models"},
+			{Name: "template", Type: "string", Description: "Job template the prompt is rendered with", Values: "templates"},
+			{Name: "depends_on", Type: "list", Description: "Job files in the plan that must complete first", Values: "jobs"},
+			{Name: "prepend_dependencies", Type: "bool", Description: "Inline dependency outputs into the prompt instead of attaching them"},
+			{Name: "prompt_source", Type: "list", Description: "Files whose contents are added to the prompt"},
+			{Name: "rules_file", Type: "string", Description: "Context rules file, relative to the job"},
+			{Name: "worktree", Type: "string", Description: "Git worktree the job runs in"},
+			{Name: "skill", Type: "string", Description: "Skill the agent starts with", Values: "skills"},
+			{Name: "skill_sequence", Type: "list", Description: "Skills the agent runs in order", Values: "skills"},
+			{Name: "summary", Type: "string", Description: "Short summary of the job's result"},
+			{Name: "created_at", Type: "string", Description: "Creation time (RFC 3339)", Internal: true},
+			{Name: "updated_at", Type: "string", Description: "Last update time (RFC 3339)", Internal: true},
+			{Name: "completed_at", Type: "string", Description: "Completion time (RFC 3339)", Internal: true},
+			{Name: "forked_from", Type: "string", Description: "Job file this chat was forked from", Internal: true},
+			{Name: "forked_from_id", Type: "string", Description: "Id of the job this chat was forked from", Internal: true},
+			{Name: "forked_at_turn", Type: "int", Description: "Last turn copied from the forked job", Internal: true},
+			{Name: "compacted_from", Type: "string", Description: "Backup of the chat before older turns were summarized", Internal: true},
+		},
+		JobTypes: jobTypes,
+		Statuses: jobStatuses,
+	}
+}
+
+// newSchemaCmd groups the commands that publish schemas for the editor.
+func newSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the schemas of grove files",
+	}
+	cmd.AddCommand(newSchemaChatCmd())
+	cmd.AddCommand(newSchemaGroveConfigCmd())
+	return cmd
+}
+
+func newSchemaChatCmd() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "chat",
+		Short: "Print the job frontmatter fields and chat directive keys",
+		Long: `Lists the frontmatter fields of job files and the keys of chat directives
+(<!-- grove: {...} -->), with the allowed job types and statuses. --json
+prints the form the Neovim completions read.
+
+Frontmatter fields come from flow's job schema ('flow schema job --json',
+cached for a day) merged with the built-in table, which supplies the
+descriptions and the fields grove-nvim writes. Without flow the built-in
+table is used alone.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema := loadChatSchema()
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(schema)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			printFields := func(title string, fields []schemaField) {
+				fmt.Fprintf(w, "%s\n", title)
+				for _, f := range fields {
+					desc := f.Description
+					if len(f.Enum) > 0 {
+						values := make([]string, len(f.Enum))
+						for i, v := range f.Enum {
+							values[i] = v.Value
+						}
+						desc += " (" + strings.Join(values, ", ") + ")"
+					}
+					fmt.Fprintf(w, "  %s\t%s\t%s\n", f.Name, f.Type, desc)
+				}
+			}
+			printFields("FRONTMATTER", schema.Frontmatter)
+			fmt.Fprintln(w)
+			printFields("DIRECTIVE", schema.Directive)
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the schema as JSON")
+
+	return cmd
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grovetools/core/config"
+	"github.com/spf13/cobra"
+)
+
+// Files `schema grove-config --out` writes.
+const (
+	groveSchemaFile = "grove.schema.json"
+	jobSchemaFile   = "job-frontmatter.schema.json"
+)
+
+// configExtension is a section of grove.yml that core does not define.
+// Other tools may read more keys from it, so it stays open to unknown
+// properties.
+type configExtension struct {
+	Section     string
+	Description string
+	Properties  map[string]any
+}
+
+// groveConfigExtensions are the config keys grove-nvim reads beyond core's
+// types, in the same structs it unmarshals them into (flowConfig,
+// nvimConfig).
+var groveConfigExtensions = []configExtension{
+	{
+		Section:     "flow",
+		Description: "Configuration for grove-flow plans and jobs",
+		Properties: map[string]any{
+			"oneshot_model": map[string]any{"type": "string", "description": "Default model for LLM jobs without one"},
+		},
+	},
+	{
+		Section:     "nvim",
+		Description: "Configuration for grove-nvim",
+		Properties: map[string]any{
+			"token_budget": map[string]any{"type": "integer", "minimum": 0, "description": "Maximum context tokens before rules lint warns (0 disables the check)"},
+		},
+	},
+}
+
+// groveConfigSchema composes core's grove.yml schema with the extension
+// sections. Top-level keys stay open: extension schemas of other tools are
+// not available offline, and their sections must not show up as errors.
+func groveConfigSchema() (map[string]any, error) {
+	base, err := config.GenerateSchema()
+	if err != nil {
+		return nil, fmt.Errorf("generate core config schema: %w", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(base, &schema); err != nil {
+		return nil, fmt.Errorf("decode core config schema: %w", err)
+	}
+	schema["title"] = "Grove Configuration"
+	schema["description"] = "grove.yml, generated by grove-nvim from the installed core version."
+	schema["additionalProperties"] = true
+
+	props, _ := schema["properties"].(map[string]any)
+	if props == nil {
+		props = map[string]any{}
+		schema["properties"] = props
+	}
+	for _, ext := range groveConfigExtensions {
+		props[ext.Section] = map[string]any{
+			"type":                 "object",
+			"description":          ext.Description,
+			"properties":           ext.Properties,
+			"additionalProperties": true,
+		}
+	}
+	return schema, nil
+}
+
+// resolveSchemaRef follows a local "#/$defs/Name" reference to the
+// definition the property points at.
+func resolveSchemaRef(schema, node map[string]any) map[string]any {
+	ref, _ := node["$ref"].(string)
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		return node
+	}
+	defs, _ := schema["$defs"].(map[string]any)
+	if def, ok := defs[name].(map[string]any); ok {
+		return def
+	}
+	return node
+}
+
+// jobFrontmatterSchema is the JSON Schema of job frontmatter, from the same
+// schema `job validate` checks against.
+func jobFrontmatterSchema(s *chatSchema) map[string]any {
+	props := make(map[string]any, len(s.Frontmatter))
+	for _, f := range s.Frontmatter {
+		p := map[string]any{"description": f.Description}
+		switch f.Type {
+		case "list":
+			p["type"] = "array"
+			p["items"] = map[string]any{"type": "string"}
+		case "int":
+			p["type"] = "integer"
+		case "bool":
+			p["type"] = "boolean"
+		case "map":
+			p["type"] = "object"
+		default:
+			p["type"] = "string"
+		}
+		if len(f.Enum) > 0 {
+			values := make([]string, len(f.Enum))
+			for i, v := range f.Enum {
+				values[i] = v.Value
+			}
+			p["enum"] = values
+		}
+		props[f.Name] = p
+	}
+	return map[string]any{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       "Grove Job Frontmatter",
+		"description": "Frontmatter of grove-flow job files, generated by grove-nvim.",
+		"type":        "object",
+		"properties":  props,
+	}
+}
+
+func writeSchemaFile(path string, schema map[string]any) error {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+func newSchemaGroveConfigCmd() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:   "grove-config",
+		Short: "Generate the JSON Schema of grove.yml",
+		Long: `Generates the JSON Schema of grove.yml from the config types of the
+installed core version, with the sections grove-nvim reads added (flow,
+nvim). Sections of other tools are allowed but not checked.
+
+Prints the schema, or with --out writes ` + groveSchemaFile + ` and
+` + jobSchemaFile + ` (the schema of job frontmatter) into that directory.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema, err := groveConfigSchema()
+			if err != nil {
+				return err
+			}
+			if out == "" {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(schema)
+			}
+			if err := os.MkdirAll(out, 0o750); err != nil {
+				return err
+			}
+			if err := writeSchemaFile(filepath.Join(out, groveSchemaFile), schema); err != nil {
+				return err
+			}
+			return writeSchemaFile(filepath.Join(out, jobSchemaFile), jobFrontmatterSchema(loadChatSchema()))
+		},
+	}
+
+	cmd.Flags().StringVar(&out, "out", "", "Directory to write the schema files into")
+
+	return cmd
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultSchemaTTL is how long flow's job schema is served from disk.
+const defaultSchemaTTL = 24 * time.Hour
+
+// flowJobSchema is the on-disk cache of the frontmatter fields flow reports.
+type flowJobSchema struct {
+	FetchedAt time.Time     `json:"fetchedAt"`
+	Fields    []schemaField `json:"fields"`
+}
+
+func (s *flowJobSchema) fetched() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	return s.FetchedAt
+}
+
+func writeFlowJobSchema(s *flowJobSchema) error {
+	return writeCache(cachePath("job-schema.json"), s)
+}
+
+// loadFlowJobFields serves flow's job fields from the cache while it is
+// younger than ttl, and otherwise asks flow. A stale cache is better than
+// none; false means flow's schema is unknown.
+func loadFlowJobFields(ttl time.Duration) ([]schemaField, bool) {
+	s, err := loadFetchedCache(cachePath("job-schema.json"), ttl, func() (*flowJobSchema, error) {
+		fields, err := fetchFlowJobFields()
+		if err != nil {
+			return nil, err
+		}
+		return &flowJobSchema{FetchedAt: time.Now().UTC(), Fields: fields}, nil
+	})
+	if err != nil {
+		ulog.Debug("Failed to read flow's job schema").Err(err).Emit()
+		return nil, false
+	}
+	return s.Fields, len(s.Fields) > 0
+}
+
+// fetchFlowJobFields runs `flow schema job --json`, the JSON Schema of
+// flow's Job type.
+func fetchFlowJobFields() ([]schemaField, error) {
+	if _, err := exec.LookPath("flow"); err != nil {
+		return nil, fmt.Errorf("'flow' command not found in PATH. Please ensure the grove-flow binary is installed and accessible")
+	}
+	var stderr bytes.Buffer
+	c := exec.Command("flow", "schema", "job", "--json")
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("flow schema job failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseFlowJobSchema(out)
+}
+
+// parseFlowJobSchema reads the properties of a JSON Schema, following a
+// top-level $ref into $defs, as schema fields.
+func parseFlowJobSchema(data []byte) ([]schemaField, error) {
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("decode flow job schema: %w", err)
+	}
+	props, _ := resolveSchemaRef(schema, schema)["properties"].(map[string]any)
+	if len(props) == 0 {
+		return nil, fmt.Errorf("flow job schema has no properties")
+	}
+	fields := make([]schemaField, 0, len(props))
+	for name, raw := range props {
+		p, _ := raw.(map[string]any)
+		p = resolveSchemaRef(schema, p)
+		f := schemaField{Name: name, Type: schemaFieldType(p)}
+		f.Description, _ = p["description"].(string)
+		if values, ok := p["enum"].([]any); ok {
+			for _, v := range values {
+				if s, ok := v.(string); ok {
+					f.Enum = append(f.Enum, schemaValue{Value: s})
+				}
+			}
+		}
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields, nil
+}
+
+// schemaFieldType maps a JSON Schema type onto a schemaField type.
+func schemaFieldType(p map[string]any) string {
+	t, _ := p["type"].(string)
+	if types, ok := p["type"].([]any); ok && len(types) > 0 {
+		t, _ = types[0].(string)
+	}
+	switch t {
+	case "array":
+		return "list"
+	case "integer", "number":
+		return "int"
+	case "boolean":
+		return "bool"
+	case "object":
+		return "map"
+	}
+	return "string"
+}
+
+// mergeFlowFields lays flow's fields over the built-in table. Every field
+// of the table is kept, including ones flow does not report; where flow
+// reports a field, its type and allowed values win, while the table keeps
+// its descriptions and completion sources. Fields only flow knows are
+// appended.
+func mergeFlowFields(builtin, flow []schemaField) []schemaField {
+	byName := make(map[string]schemaField, len(flow))
+	for _, f := range flow {
+		byName[f.Name] = f
+	}
+	merged := make([]schemaField, 0, len(builtin)+len(flow))
+	seen := make(map[string]bool, len(builtin))
+	for _, b := range builtin {
+		seen[b.Name] = true
+		f, ok := byName[b.Name]
+		if !ok {
+			merged = append(merged, b)
+			continue
+		}
+		b.Type = f.Type
+		if len(f.Enum) > 0 {
+			b.Enum = mergeEnum(b.Enum, f.Enum)
+		}
+		if b.Description == "" {
+			b.Description = f.Description
+		}
+		merged = append(merged, b)
+	}
+	for _, f := range flow {
+		if seen[f.Name] {
+			continue
+		}
+		if f.Description == "" {
+			f.Description = "Reported by flow"
+		}
+		merged = append(merged, f)
+	}
+	return merged
+}
+
+// mergeEnum keeps flow's values, described from the table where it can be.
+func mergeEnum(builtin, flow []schemaValue) []schemaValue {
+	desc := make(map[string]string, len(builtin))
+	for _, v := range builtin {
+		desc[v.Value] = v.Description
+	}
+	out := make([]schemaValue, len(flow))
+	for i, v := range flow {
+		out[i] = schemaValue{Value: v.Value, Description: firstNonEmpty(desc[v.Value], v.Description)}
+	}
+	return out
+}
+
+// loadChatSchema is newChatSchema with flow's own job schema laid over its
+// frontmatter fields, so fields a newer flow adds are known without a
+// grove-nvim release. Without flow it is the built-in table.
+func loadChatSchema() *chatSchema {
+	s := newChatSchema()
+	fields, ok := loadFlowJobFields(defaultSchemaTTL)
+	if !ok {
+		return s
+	}
+	s.Frontmatter = mergeFlowFields(s.Frontmatter, fields)
+	for _, f := range s.Frontmatter {
+		switch f.Name {
+		case "type":
+			s.JobTypes = f.Enum
+		case "status":
+			s.Statuses = f.Enum
+		}
+	}
+	return s
+}
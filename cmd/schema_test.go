@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatSchema(t *testing.T) {
+	s := newChatSchema()
+
+	names := func(fields []schemaField) map[string]schemaField {
+		m := make(map[string]schemaField, len(fields))
+		for _, f := range fields {
+			assert.NotContains(t, m, f.Name, "duplicate field")
+			assert.NotEmpty(t, f.Description, f.Name)
+			m[f.Name] = f
+		}
+		return m
+	}
+	front := names(s.Frontmatter)
+	directive := names(s.Directive)
+
+	for _, key := range []string{"template", "model", "id"} {
+		assert.Contains(t, directive, key, "keys the directive completion used to hard-code")
+	}
+	for _, key := range []string{"id", "title", "type", "status", "model", "template", "depends_on", "skill", "skill_sequence", "rules_file"} {
+		assert.Contains(t, front, key)
+	}
+	assert.Equal(t, s.JobTypes, front["type"].Enum)
+	assert.Equal(t, s.Statuses, front["status"].Enum)
+	assert.Equal(t, "jobs", front["depends_on"].Values)
+	assert.True(t, front["forked_from"].Internal, "fields the chat commands write are marked internal")
+}
+
+func TestGroveConfigSchema(t *testing.T) {
+	schema, err := groveConfigSchema()
+	require.NoError(t, err)
+	assert.Equal(t, true, schema["additionalProperties"], "other tools' sections are allowed")
+
+	props := schema["properties"].(map[string]any)
+	assert.Contains(t, props, "notebooks", "core's sections come from its config types")
+	flow := props["flow"].(map[string]any)
+	assert.Contains(t, flow["properties"], "oneshot_model")
+
+	context := resolveSchemaRef(schema, props["context"].(map[string]any))
+	ctxProps := context["properties"].(map[string]any)
+	assert.Contains(t, ctxProps, "repos_dir")
+	assert.NotContains(t, ctxProps, "token_budget", "core's sections are left as core defines them")
+	nvim := props["nvim"].(map[string]any)
+	assert.Contains(t, nvim["properties"], "token_budget")
+}
+
+func TestJobFrontmatterSchema(t *testing.T) {
+	props := jobFrontmatterSchema(newChatSchema())["properties"].(map[string]any)
+	assert.Equal(t, "array", props["depends_on"].(map[string]any)["type"])
+	assert.Equal(t, "boolean", props["prepend_dependencies"].(map[string]any)["type"])
+	assert.Contains(t, props["type"].(map[string]any)["enum"], "interactive_agent")
+}
+
+const flowJobSchemaFixture = `{
+  "$ref": "#/$defs/Job",
+  "$defs": {
+    "Job": {
+      "type": "object",
+      "properties": {
+        "id": {"type": "string", "description": "Job id"},
+        "type": {"type": "string", "enum": ["chat", "oneshot", "review"]},
+        "depends_on": {"type": "array", "items": {"type": "string"}},
+        "retries": {"type": "integer", "description": "Times to retry a failed run"},
+        "gate": {"$ref": "#/$defs/Gate"}
+      }
+    },
+    "Gate": {"type": "object", "description": "Approval gate"}
+  }
+}`
+
+func TestParseFlowJobSchema(t *testing.T) {
+	fields, err := parseFlowJobSchema([]byte(flowJobSchemaFixture))
+	require.NoError(t, err)
+	byName := make(map[string]schemaField, len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+	require.Len(t, byName, 5)
+	assert.Equal(t, "list", byName["depends_on"].Type)
+	assert.Equal(t, "int", byName["retries"].Type)
+	assert.Equal(t, "map", byName["gate"].Type, "property refs are followed")
+	assert.Equal(t, "Approval gate", byName["gate"].Description)
+
+	_, err = parseFlowJobSchema([]byte(`{"type": "object"}`))
+	assert.Error(t, err)
+}
+
+func TestMergeFlowFields(t *testing.T) {
+	flow, err := parseFlowJobSchema([]byte(flowJobSchemaFixture))
+	require.NoError(t, err)
+	merged := mergeFlowFields(newChatSchema().Frontmatter, flow)
+
+	byName := make(map[string]schemaField, len(merged))
+	for _, f := range merged {
+		byName[f.Name] = f
+	}
+	assert.Equal(t, "Times to retry a failed run", byName["retries"].Description, "fields only flow knows are added")
+	assert.Equal(t, "map", byName["gate"].Type)
+	assert.Equal(t, "Unique job id within the plan", byName["id"].Description, "the table keeps its descriptions")
+	assert.Equal(t, "jobs", byName["depends_on"].Values)
+	assert.True(t, byName["forked_from"].Internal, "fields grove-nvim writes stay")
+
+	types := byName["type"].Enum
+	require.Len(t, types, 3, "flow decides the allowed values")
+	assert.Equal(t, "Multi-turn conversation in the job file", types[0].Description)
+	assert.Equal(t, "review", types[2].Value)
+}
+
+func TestLoadChatSchemaFallsBackToTable(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	t.Setenv("PATH", t.TempDir()) // no flow
+	assert.Equal(t, newChatSchema(), loadChatSchema())
+
+	require.NoError(t, writeFlowJobSchema(&flowJobSchema{
+		FetchedAt: time.Now().Add(-48 * time.Hour),
+		Fields:    []schemaField{{Name: "retries", Type: "int"}},
+	}))
+	s := loadChatSchema()
+	assert.Equal(t, "retries", s.Frontmatter[len(s.Frontmatter)-1].Name, "a stale cache beats the table alone")
+}
@@ -0,0 +1,515 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/grovetools/core/pkg/workspace"
+	"github.com/spf13/cobra"
+)
+
+const (
+	searchIndexVersion = 2
+	defaultSearchLimit = 200
+)
+
+// searchRoot is a directory to search and the workspace that owns it; notebook
+// roots have no single owner.
+type searchRoot struct {
+	Path      string
+	Workspace string
+}
+
+// searchFile is a Markdown file found under a search root.
+type searchFile struct {
+	Path      string
+	Workspace string
+	ModTime   int64
+	Size      int64
+}
+
+// searchHit is one matching line. Line and Col are 1-based, as a picker or
+// the quickfix list expects them.
+type searchHit struct {
+	File      string `json:"file"`
+	Workspace string `json:"workspace,omitempty"`
+	Plan      string `json:"plan,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Turn      int    `json:"turn"`
+	Role      string `json:"role"`
+	Line      int    `json:"line"`
+	Col       int    `json:"col"`
+	Text      string `json:"text"`
+}
+
+// searchIndex persists a term index over the turns of every searched file:
+// each term maps to the (file, turn) pairs it occurs in. Files are keyed by
+// path and re-indexed only when their mtime or size changes, like the token
+// cache, so a query reads the index and the few files that changed instead
+// of every chat and note.
+type searchIndex struct {
+	path   string
+	files  map[string]*indexedFile
+	terms  map[string][]termPosting
+	nextID int
+	dirty  bool
+}
+
+// termPosting is a turn a term occurs in: an indexedFile ID and the position
+// of the turn in its Turns.
+type termPosting [2]int
+
+type indexedFile struct {
+	ID      int           `json:"id"`
+	ModTime int64         `json:"mtime"`
+	Size    int64         `json:"size"`
+	Title   string        `json:"title,omitempty"`
+	Turns   []indexedTurn `json:"turns"`
+}
+
+// indexedTurn is a turn's text without its directive; Line is the file line
+// Text starts on. The text is kept so hits can be reported line by line
+// without re-reading the file.
+type indexedTurn struct {
+	Index int    `json:"turn"`
+	Role  string `json:"role"`
+	Line  int    `json:"line"`
+	Text  string `json:"text"`
+}
+
+type searchIndexFile struct {
+	Version int                      `json:"version"`
+	NextID  int                      `json:"nextId"`
+	Files   map[string]*indexedFile  `json:"files"`
+	Terms   map[string][]termPosting `json:"terms"`
+}
+
+// searchIndexPath is where the index lives; empty disables persistence.
+func searchIndexPath() string {
+	return cachePath("search-index.json")
+}
+
+// loadSearchIndex reads the index at path. A missing, corrupt or outdated
+// index is not an error — it just starts empty.
+func loadSearchIndex(path string) *searchIndex {
+	idx := &searchIndex{path: path, files: make(map[string]*indexedFile), terms: make(map[string][]termPosting)}
+	var f searchIndexFile
+	if !readCache(path, &f) || f.Version != searchIndexVersion || f.Files == nil || f.Terms == nil {
+		return idx
+	}
+	idx.files, idx.terms, idx.nextID = f.Files, f.Terms, f.NextID
+	return idx
+}
+
+// Save writes the index back if anything changed.
+func (idx *searchIndex) Save() {
+	if !idx.dirty {
+		return
+	}
+	_ = writeCache(idx.path, searchIndexFile{Version: searchIndexVersion, NextID: idx.nextID, Files: idx.files, Terms: idx.terms})
+}
+
+// Update returns the indexed turns of f, re-reading and re-indexing the file
+// only when it changed since it was indexed.
+func (idx *searchIndex) Update(f searchFile) (*indexedFile, bool) {
+	old, ok := idx.files[f.Path]
+	if ok && old.ModTime == f.ModTime && old.Size == f.Size {
+		return old, true
+	}
+	if ok {
+		idx.remove(f.Path)
+	}
+	content, err := os.ReadFile(f.Path) //nolint:gosec // path comes from a plan or notebook walk
+	if err != nil || isBinary(content) {
+		return nil, false
+	}
+	e := indexFile(content)
+	e.ModTime, e.Size = f.ModTime, f.Size
+	e.ID = idx.nextID
+	idx.nextID++
+	for i, t := range e.Turns {
+		for _, term := range indexTerms(t.Text) {
+			idx.terms[term] = append(idx.terms[term], termPosting{e.ID, i})
+		}
+	}
+	idx.files[f.Path] = e
+	idx.dirty = true
+	return e, true
+}
+
+// remove drops a file and its postings.
+func (idx *searchIndex) remove(path string) {
+	e, ok := idx.files[path]
+	if !ok {
+		return
+	}
+	for _, t := range e.Turns {
+		for _, term := range indexTerms(t.Text) {
+			kept := idx.terms[term][:0]
+			for _, p := range idx.terms[term] {
+				if p[0] != e.ID {
+					kept = append(kept, p)
+				}
+			}
+			if len(kept) == 0 {
+				delete(idx.terms, term)
+			} else {
+				idx.terms[term] = kept
+			}
+		}
+	}
+	delete(idx.files, path)
+	idx.dirty = true
+}
+
+// Prune drops the files that no longer exist.
+func (idx *searchIndex) Prune(seen map[string]bool) {
+	for path := range idx.files {
+		if seen[path] {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			idx.remove(path)
+		}
+	}
+}
+
+// candidates returns the turns that can contain every word of the query,
+// by file ID and turn position. A query word matches inside longer words,
+// so each of its terms selects the postings of every indexed term that
+// contains it. ok is false when the query has no terms to look up and every
+// turn is a candidate.
+func (idx *searchIndex) candidates(words []string) (turns map[int]map[int]bool, ok bool) {
+	for _, word := range words {
+		for _, q := range indexTerms(word) {
+			found := make(map[int]map[int]bool)
+			for term, postings := range idx.terms {
+				if !strings.Contains(term, q) {
+					continue
+				}
+				for _, p := range postings {
+					if turns != nil && !turns[p[0]][p[1]] {
+						continue
+					}
+					if found[p[0]] == nil {
+						found[p[0]] = make(map[int]bool)
+					}
+					found[p[0]][p[1]] = true
+				}
+			}
+			turns, ok = found, true
+			if len(turns) == 0 {
+				return turns, true
+			}
+		}
+	}
+	return turns, ok
+}
+
+// indexTerms splits text into its distinct lowercase words: runs of letters
+// and digits.
+func indexTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(words))
+	terms := words[:0]
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// indexFile parses a chat or note into its turns. A note without directives
+// is a single user turn.
+func indexFile(content []byte) *indexedFile {
+	doc := parseChatDocument(content)
+	e := &indexedFile{Turns: []indexedTurn{}}
+	if node, err := parseFrontmatterNode(doc.Front); err == nil {
+		e.Title = frontmatterField(node, "title")
+	}
+	for _, t := range doc.Turns {
+		first := t.start
+		if t.Directive != nil {
+			first++
+		}
+		e.Turns = append(e.Turns, indexedTurn{Index: t.Index, Role: t.Role, Line: first + 1, Text: doc.text(first, t.end)})
+	}
+	return e
+}
+
+// collectSearchFiles walks the roots for Markdown files, skipping hidden
+// directories such as .archive and .compact. A file under several roots is
+// attributed to the most specific root with an owner.
+func collectSearchFiles(roots []searchRoot) []searchFile {
+	owned := append([]searchRoot(nil), roots...)
+	sort.Slice(owned, func(i, j int) bool { return len(owned[i].Path) > len(owned[j].Path) })
+	owner := func(path string) string {
+		for _, r := range owned {
+			if r.Workspace != "" && (path == r.Path || strings.HasPrefix(path, r.Path+string(filepath.Separator))) {
+				return r.Workspace
+			}
+		}
+		return ""
+	}
+
+	seen := make(map[string]bool)
+	var files []searchFile
+	for _, root := range roots {
+		_ = filepath.WalkDir(root.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != root.Path && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if filepath.Ext(path) != ".md" || seen[path] {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			seen[path] = true
+			files = append(files, searchFile{Path: path, Workspace: owner(path), ModTime: info.ModTime().UnixNano(), Size: info.Size()})
+			return nil
+		})
+	}
+	// Most recently changed first, so the chat you were just in ranks high.
+	sort.SliceStable(files, func(i, j int) bool { return files[i].ModTime > files[j].ModTime })
+	return files
+}
+
+// planName is the plan a job file belongs to: its directory, when that sits
+// in a "plans" directory.
+func planName(path string) string {
+	dir := filepath.Dir(path)
+	if filepath.Base(filepath.Dir(dir)) == "plans" {
+		return filepath.Base(dir)
+	}
+	return ""
+}
+
+// searchOptions narrow a query.
+type searchOptions struct {
+	Plan  string // plan name or directory
+	Role  string // "user" or "llm"
+	Limit int
+}
+
+// matchesPlan reports whether file belongs to the plan given by name or by
+// directory.
+func (o searchOptions) matchesPlan(file string) bool {
+	if o.Plan == "" {
+		return true
+	}
+	if filepath.IsAbs(o.Plan) {
+		return filepath.Dir(file) == filepath.Clean(o.Plan)
+	}
+	return planName(file) == o.Plan
+}
+
+// searchFiles finds the turns containing every word of query, case
+// insensitively, and returns a hit for each of their lines that contains
+// one of the words. The index narrows the turns to read; each candidate is
+// then checked against the query text itself.
+func searchFiles(idx *searchIndex, files []searchFile, query string, opts searchOptions) []searchHit {
+	terms := strings.Fields(strings.ToLower(query))
+	hits := []searchHit{}
+	if len(terms) == 0 {
+		return hits
+	}
+	indexed := make([]*indexedFile, len(files))
+	for i, f := range files {
+		if !opts.matchesPlan(f.Path) {
+			continue
+		}
+		if e, ok := idx.Update(f); ok {
+			indexed[i] = e
+		}
+	}
+	candidates, narrowed := idx.candidates(terms)
+	for i, f := range files {
+		e := indexed[i]
+		if e == nil || (narrowed && candidates[e.ID] == nil) {
+			continue
+		}
+		for j, t := range e.Turns {
+			if narrowed && !candidates[e.ID][j] {
+				continue
+			}
+			if opts.Role != "" && t.Role != opts.Role {
+				continue
+			}
+			lower := strings.ToLower(t.Text)
+			if !containsAll(lower, terms) {
+				continue
+			}
+			for k, line := range strings.Split(t.Text, "\n") {
+				col := firstTerm(strings.ToLower(line), terms)
+				if col < 0 {
+					continue
+				}
+				hits = append(hits, searchHit{
+					File: f.Path, Workspace: f.Workspace, Plan: planName(f.Path), Title: e.Title,
+					Turn: t.Index, Role: t.Role, Line: t.Line + k, Col: col + 1, Text: strings.TrimSpace(line),
+				})
+				if opts.Limit > 0 && len(hits) >= opts.Limit {
+					return hits
+				}
+			}
+		}
+	}
+	return hits
+}
+
+func containsAll(s string, terms []string) bool {
+	for _, t := range terms {
+		if !strings.Contains(s, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// firstTerm returns the byte offset of the earliest term in s, or -1.
+func firstTerm(s string, terms []string) int {
+	best := -1
+	for _, t := range terms {
+		if i := strings.Index(s, t); i >= 0 && (best < 0 || i < best) {
+			best = i
+		}
+	}
+	return best
+}
+
+// searchRoots lists the plan and notebook directories to search. With a
+// workspace only its plan, chat and note directories are searched; the
+// shared notebook roots are left out.
+func searchRoots(workspaceFilter string) ([]searchRoot, error) {
+	a, err := newPathAliaser()
+	if err != nil {
+		return nil, err
+	}
+
+	if workspaceFilter == "." || strings.ContainsRune(workspaceFilter, filepath.Separator) {
+		dir, err := filepath.Abs(workspaceFilter)
+		if err != nil {
+			return nil, err
+		}
+		node := findWorkspaceByPath(a.provider, dir, a.discoveryResult)
+		if node == nil {
+			return nil, fmt.Errorf("no workspace contains %s", dir)
+		}
+		workspaceFilter = node.Name
+	}
+
+	var roots []searchRoot
+	add := func(dirs []workspace.ScannedDir, err error) {
+		if err != nil {
+			return
+		}
+		for _, d := range dirs {
+			if d.Owner != nil && (workspaceFilter == "" || d.Owner.Name == workspaceFilter) {
+				roots = append(roots, searchRoot{Path: d.Path, Workspace: d.Owner.Name})
+			}
+		}
+	}
+	add(a.locator.ScanForAllPlans(a.provider))
+	add(a.locator.ScanForAllChats(a.provider))
+	add(a.locator.ScanForAllNotes(a.provider))
+	if workspaceFilter == "" {
+		for _, nb := range a.notebooks {
+			roots = append(roots, searchRoot{Path: nb.RootDir})
+		}
+	} else if len(roots) == 0 {
+		return nil, fmt.Errorf("no plans or notes found for workspace %q", workspaceFilter)
+	}
+	return roots, nil
+}
+
+func newSearchCmd() *cobra.Command {
+	var (
+		plan          string
+		workspaceName string
+		role          string
+		limit         int
+		jsonOutput    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search chats and notes across plans and notebooks",
+		Long: `Searches the Markdown files of every plan, chat and notes directory the
+workspaces resolve to, and of the configured notebook roots. A turn matches
+when it contains every word of the query, ignoring case; each of its lines
+with one of the words is a hit.
+
+--workspace limits the search to one workspace by name, or to the one
+containing a path ("." for the current directory). --plan limits it to a
+plan by name or directory, --role to user or llm turns.
+
+A term index of every turn is kept in the grove cache directory. Files are
+re-read and re-indexed only when they changed, and a query reads only the
+turns the index names.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if role != "" && role != "user" && role != "llm" {
+				return fmt.Errorf("--role must be user or llm, got %q", role)
+			}
+			opts := searchOptions{Plan: plan, Role: role, Limit: limit}
+			if plan != "" && (strings.ContainsRune(plan, filepath.Separator) || plan == ".") {
+				dir, err := filepath.Abs(plan)
+				if err != nil {
+					return err
+				}
+				opts.Plan = dir
+			}
+
+			roots, err := searchRoots(workspaceName)
+			if err != nil {
+				return err
+			}
+			files := collectSearchFiles(roots)
+			idx := loadSearchIndex(searchIndexPath())
+			hits := searchFiles(idx, files, strings.Join(args, " "), opts)
+			if workspaceName == "" {
+				seen := make(map[string]bool, len(files))
+				for _, f := range files {
+					seen[f.Path] = true
+				}
+				idx.Prune(seen)
+			}
+			idx.Save()
+
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(hits)
+			}
+			// file:line:col: text, the format of grep -n --column and :vimgrep.
+			for _, h := range hits {
+				fmt.Printf("%s:%d:%d: [%d %s] %s\n", h.File, h.Line, h.Col, h.Turn, h.Role, h.Text)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&plan, "plan", "", "Only search this plan (name or directory)")
+	cmd.Flags().StringVar(&workspaceName, "workspace", "", "Only search this workspace (name, or a path inside it)")
+	cmd.Flags().StringVar(&role, "role", "", "Only search user or llm turns")
+	cmd.Flags().IntVar(&limit, "limit", defaultSearchLimit, "Maximum number of hits (0 for no limit)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output hits as JSON")
+
+	return cmd
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSearchFixture(t *testing.T) (plans, notebook string) {
+	t.Helper()
+	root := t.TempDir()
+	plans = filepath.Join(root, "proj", "plans")
+	notebook = filepath.Join(root, "nb")
+	files := map[string]string{
+		filepath.Join(plans, "parser", "01-chat.md"): "---\ntitle: Parser\n---\nHow should the lexer handle tabs?\n\n" +
+			"<!-- grove: {\"id\": \"a1\"} -->\nThe lexer should expand\nTabs to four spaces.\n",
+		filepath.Join(plans, "parser", ".archive", "01-chat.responses.md"): "lexer tabs\n",
+		filepath.Join(notebook, "inbox", "idea.md"):                        "Tabs in the LEXER are a mistake.\n",
+		filepath.Join(notebook, "inbox", "todo.txt"):                       "lexer tabs\n",
+	}
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return plans, notebook
+}
+
+func TestCollectSearchFiles(t *testing.T) {
+	plans, notebook := writeSearchFixture(t)
+	files := collectSearchFiles([]searchRoot{{Path: plans, Workspace: "proj"}, {Path: notebook}, {Path: plans}})
+
+	byPath := map[string]string{}
+	for _, f := range files {
+		byPath[f.Path] = f.Workspace
+	}
+	assert.Equal(t, map[string]string{
+		filepath.Join(plans, "parser", "01-chat.md"): "proj",
+		filepath.Join(notebook, "inbox", "idea.md"):  "",
+	}, byPath, "hidden directories and other files are skipped, duplicates collapse")
+}
+
+func TestSearchFiles(t *testing.T) {
+	plans, notebook := writeSearchFixture(t)
+	files := collectSearchFiles([]searchRoot{{Path: plans, Workspace: "proj"}, {Path: notebook}})
+	idx := loadSearchIndex(filepath.Join(t.TempDir(), "index.json"))
+
+	hits := searchFiles(idx, files, "Lexer TABS", searchOptions{})
+	require.Len(t, hits, 4)
+	var chat []searchHit
+	for _, h := range hits {
+		if h.Plan == "parser" {
+			chat = append(chat, h)
+		}
+	}
+	require.Len(t, chat, 3)
+	assert.Equal(t, searchHit{File: filepath.Join(plans, "parser", "01-chat.md"), Workspace: "proj", Plan: "parser", Title: "Parser",
+		Turn: 1, Role: "user", Line: 4, Col: 16, Text: "How should the lexer handle tabs?"}, chat[0])
+	assert.Equal(t, 7, chat[1].Line)
+	assert.Equal(t, 8, chat[2].Line, "every matching line of the turn is a hit")
+
+	hits = searchFiles(idx, files, "lexer tabs", searchOptions{Role: "llm"})
+	require.Len(t, hits, 2)
+	assert.Equal(t, 2, hits[0].Turn)
+	assert.Len(t, searchFiles(idx, files, "lexer", searchOptions{Plan: "other"}), 0)
+	assert.Len(t, searchFiles(idx, files, "lexer handle spaces", searchOptions{}), 0, "all words must be in one turn")
+	assert.Len(t, searchFiles(idx, files, "tabs", searchOptions{Limit: 1}), 1)
+}
+
+func TestSearchIndexPersists(t *testing.T) {
+	plans, _ := writeSearchFixture(t)
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	chat := filepath.Join(plans, "parser", "01-chat.md")
+
+	idx := loadSearchIndex(indexPath)
+	files := collectSearchFiles([]searchRoot{{Path: plans}})
+	require.Len(t, searchFiles(idx, files, "lexer", searchOptions{}), 2)
+	idx.Save()
+
+	// An unchanged file is answered from the index: postings select the
+	// turns, without reading the file again.
+	idx = loadSearchIndex(indexPath)
+	id := idx.files[chat].ID
+	assert.Contains(t, idx.terms["lexer"], termPosting{id, 0})
+	assert.Contains(t, idx.terms["lexer"], termPosting{id, 1})
+	assert.Equal(t, []termPosting{{id, 1}}, idx.terms["expand"])
+	idx.files[chat].Turns[1].Text = "cached expand"
+	hits := searchFiles(idx, files, "expand", searchOptions{})
+	require.Len(t, hits, 1)
+	assert.Equal(t, "cached expand", hits[0].Text)
+
+	// A changed file is indexed again and its old terms are dropped.
+	require.NoError(t, os.WriteFile(chat, []byte("No match here.\n"), 0o600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(chat, later, later))
+	files = collectSearchFiles([]searchRoot{{Path: plans}})
+	assert.Len(t, searchFiles(idx, files, "expand", searchOptions{}), 0)
+	assert.NotContains(t, idx.terms, "expand")
+	assert.Contains(t, idx.terms, "match")
+
+	require.NoError(t, os.Remove(chat))
+	idx.Prune(map[string]bool{})
+	assert.NotContains(t, idx.files, chat)
+	assert.NotContains(t, idx.terms, "match", "pruned files leave no postings")
+}
+
+func TestSearchIndexCandidates(t *testing.T) {
+	plans, notebook := writeSearchFixture(t)
+	idx := loadSearchIndex("")
+	files := collectSearchFiles([]searchRoot{{Path: plans}, {Path: notebook}})
+	require.NotEmpty(t, searchFiles(idx, files, "lex", searchOptions{}), "query words match inside longer words")
+
+	turns, ok := idx.candidates([]string{"lexer", "four"})
+	require.True(t, ok)
+	require.Len(t, turns, 1, "only the chat has both words")
+	for _, byTurn := range turns {
+		assert.Equal(t, map[int]bool{1: true}, byTurn)
+	}
+	assert.Len(t, searchFiles(idx, files, "spaces.", searchOptions{}), 1, "punctuation in the query is matched against the text")
+	_, ok = idx.candidates([]string{"--"})
+	assert.False(t, ok, "a query without terms reads every turn")
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func newTendCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tend",
+		Short: "Work with tend E2E scenarios",
+		Long: `Reads tend scenarios from Go source without compiling the test binary, so
+the editor can act on the scenario under the cursor.`,
+	}
+	cmd.AddCommand(newTendLocateCmd())
+	cmd.AddCommand(newTendListCmd())
+	cmd.AddCommand(newTendRunCmd())
+	cmd.AddCommand(newTendExecCmd())
+	cmd.AddCommand(newTendRerunCmd())
+	return cmd
+}
+
+// locateResult is the scenario (or scenarios) enclosing a source position.
+// A position inside a step helper resolves to every scenario using it.
+type locateResult struct {
+	Scenarios []*tendScenario `json:"scenarios"`
+	Step      *tendStep       `json:"step,omitempty"`
+}
+
+func newTendLocateCmd() *cobra.Command {
+	var (
+		file string
+		line int
+		col  int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "locate",
+		Short: "Find the tend scenario at a source position",
+		Long: `Parses the Go package containing --file and prints, as JSON, the scenario
+whose constructor function (one returning *harness.Scenario) encloses
+--line/--col, with its tags and steps. When the position is inside a step
+helper (a function returning harness.Step), every scenario listing that
+helper is returned, and "step" names the step under the cursor.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "" || line < 1 {
+				return fmt.Errorf("--file and --line are required")
+			}
+			result, err := locateScenario(file, line, col)
+			if err != nil {
+				return err
+			}
+			return json.NewEncoder(os.Stdout).Encode(result)
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Go source file")
+	cmd.Flags().IntVar(&line, "line", 0, "1-based line")
+	cmd.Flags().IntVar(&col, "col", 1, "1-based column")
+
+	return cmd
+}
+
+// locateScenario resolves a 1-based file position to its scenario.
+func locateScenario(file string, line, col int) (*locateResult, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := parseTendPackage(filepath.Dir(abs))
+	if err != nil {
+		return nil, fmt.Errorf("no Go files to parse in %s", filepath.Dir(abs))
+	}
+	pos, err := pkg.position(abs, line, col)
+	if err != nil {
+		return nil, err
+	}
+
+	scenarios := pkg.scenarios()
+	for _, s := range scenarios {
+		if !contains(s.decl, pos) {
+			continue
+		}
+		result := &locateResult{Scenarios: []*tendScenario{s}}
+		for i := range s.Steps {
+			if contains(s.Steps[i].expr, pos) {
+				result.Step = &s.Steps[i]
+				break
+			}
+		}
+		return result, nil
+	}
+
+	for name, fd := range pkg.funcs {
+		if !contains(fd, pos) || !pkg.returnsHarness(fd, "Step", false) {
+			continue
+		}
+		result := &locateResult{Scenarios: []*tendScenario{}}
+		for _, s := range scenarios {
+			for i := range s.Steps {
+				if s.Steps[i].Helper != name {
+					continue
+				}
+				if result.Step == nil {
+					result.Step = &s.Steps[i]
+				}
+				result.Scenarios = append(result.Scenarios, s)
+				break
+			}
+		}
+		if len(result.Scenarios) == 0 {
+			return nil, fmt.Errorf("step helper %s is not used by any scenario", name)
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("no tend scenario at %s:%d:%d", file, line, col)
+}
+
+// position converts a 1-based line and column in path to a token.Pos.
+func (pkg *tendPackage) position(path string, line, col int) (token.Pos, error) {
+	for _, f := range pkg.files {
+		tf := pkg.fset.File(f.Pos())
+		if tf.Name() != path {
+			continue
+		}
+		if line > tf.LineCount() {
+			return token.NoPos, fmt.Errorf("line %d is past the end of %s", line, path)
+		}
+		start := tf.LineStart(line)
+		end := tf.Base() + tf.Size()
+		if line < tf.LineCount() {
+			end = int(tf.LineStart(line + 1))
+		}
+		pos := int(start) + max(col, 1) - 1
+		if pos >= end {
+			pos = end - 1
+		}
+		return token.Pos(pos), nil
+	}
+	return token.NoPos, fmt.Errorf("%s does not parse as Go", path)
+}
+
+func contains(node ast.Node, pos token.Pos) bool {
+	return node != nil && node.Pos() <= pos && pos < node.End()
+}
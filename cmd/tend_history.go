@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grovetools/core/pkg/paths"
+	"github.com/grovetools/core/pkg/workspace"
+	"github.com/spf13/cobra"
+)
+
+// tendHistoryLimit caps the runs kept per workspace.
+const tendHistoryLimit = 100
+
+// tendRunRecord is one recorded scenario run.
+type tendRunRecord struct {
+	Scenario  string    `json:"scenario"`
+	Command   string    `json:"command"` // template; %s is the scenario name
+	Success   bool      `json:"success"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// tendHistory is a workspace's run log, oldest first.
+type tendHistory struct {
+	path string
+
+	Workspace string          `json:"workspace"`
+	Runs      []tendRunRecord `json:"runs"`
+}
+
+// tendHistoryPath keys the state file on the workspace containing dir, so
+// every directory of a project shares one history.
+func tendHistoryPath(dir string) (root, path string) {
+	root = dir
+	if node, err := workspace.GetProjectByPath(dir); err == nil && node != nil && node.Path != "" {
+		root = node.Path
+	}
+	stateDir := paths.StateDir()
+	if stateDir == "" {
+		return root, ""
+	}
+	sum := sha256.Sum256([]byte(root))
+	name := filepath.Base(root) + "-" + hex.EncodeToString(sum[:])[:12] + ".json"
+	return root, filepath.Join(stateDir, "nvim", "tend-history", name)
+}
+
+// loadTendHistory reads the history for dir. A missing or corrupt file
+// starts an empty history.
+func loadTendHistory(dir string) *tendHistory {
+	root, path := tendHistoryPath(dir)
+	h := &tendHistory{path: path, Workspace: root}
+	if path == "" {
+		return h
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path is derived from the state dir
+	if err != nil {
+		return h
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		h.Runs = nil
+	}
+	h.Workspace = root
+	return h
+}
+
+func (h *tendHistory) record(r tendRunRecord) {
+	h.Runs = append(h.Runs, r)
+	if len(h.Runs) > tendHistoryLimit {
+		h.Runs = h.Runs[len(h.Runs)-tendHistoryLimit:]
+	}
+}
+
+func (h *tendHistory) save() error {
+	if h.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(h.path, data, 0o600)
+}
+
+// last is the most recent run.
+func (h *tendHistory) last() (tendRunRecord, bool) {
+	if len(h.Runs) == 0 {
+		return tendRunRecord{}, false
+	}
+	return h.Runs[len(h.Runs)-1], true
+}
+
+// failed returns, for each scenario whose latest run failed, that run; the
+// order is the order the failures happened in.
+func (h *tendHistory) failed() []tendRunRecord {
+	latest := make(map[string]int, len(h.Runs))
+	for i, r := range h.Runs {
+		latest[r.Scenario] = i
+	}
+	var out []tendRunRecord
+	for i, r := range h.Runs {
+		if latest[r.Scenario] == i && !r.Success {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// tendCommand expands a command template for a scenario. The name is
+// shell-quoted unless the template already places %s inside quotes; a
+// template without %s gets the quoted name appended. Only the first %s is
+// replaced, so other % signs in the template are passed through untouched.
+func tendCommand(template, scenario string) string {
+	i := strings.Index(template, "%s")
+	if i < 0 {
+		return strings.TrimRight(template, " ") + " " + shellQuote(scenario)
+	}
+	name := shellQuote(scenario)
+	if insideShellQuotes(template[:i]) {
+		name = scenario
+	}
+	return template[:i] + name + template[i+len("%s"):]
+}
+
+// shellQuote single-quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// insideShellQuotes reports whether prefix leaves a quote open.
+func insideShellQuotes(prefix string) bool {
+	var quote rune
+	for _, r := range prefix {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '\'' || r == '"'):
+			quote = r
+		}
+	}
+	return quote != 0
+}
+
+// execTendCommand runs template for scenario through the shell with the
+// terminal attached and records the outcome in the workspace history.
+func execTendCommand(template, scenario string) (bool, error) {
+	// #nosec G204 -- the command template is the user's own configuration
+	c := exec.Command("sh", "-c", tendCommand(template, scenario))
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := c.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return false, err
+	}
+	recordTendRun(scenario, template, err == nil)
+	return err == nil, nil
+}
+
+// recordTendRun appends a run to the current workspace's history. History
+// is best effort: failing to write it never fails the run.
+func recordTendRun(scenario, template string, success bool) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	h := loadTendHistory(cwd)
+	h.record(tendRunRecord{Scenario: scenario, Command: template, Success: success, Timestamp: time.Now().UTC()})
+	if err := h.save(); err != nil {
+		ulog.Debug("Failed to save tend history").Err(err).Emit()
+	}
+}
+
+func newTendExecCmd() *cobra.Command {
+	var template string
+
+	cmd := &cobra.Command{
+		Use:   "exec <scenario>",
+		Short: "Run a scenario through a command template and record the run",
+		Long: `Runs the command template (as test_runner.command_template, %s standing for
+the scenario name) with the terminal attached, then records the scenario,
+template, result and time in the workspace's run history for 'tend rerun'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ok, err := execTendCommand(template, args[0])
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("scenario %s failed", args[0])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&template, "template", "tend run %s", "Command template; %s is the scenario name")
+
+	return cmd
+}
+
+func newTendRerunCmd() *cobra.Command {
+	var (
+		last     bool
+		failed   bool
+		template string
+		list     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rerun",
+		Short: "Re-run the last scenario or every scenario that last failed",
+		Long: `Replays runs from the workspace's history. --last re-runs the most recent
+scenario; --failed re-runs each scenario whose latest run failed, in the order
+they failed. Each replay uses the template it was recorded with unless
+--template overrides it, and is recorded in turn.
+
+--list prints the runs that would be replayed as JSON instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if last == failed {
+				return fmt.Errorf("pass exactly one of --last or --failed")
+			}
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			h := loadTendHistory(cwd)
+
+			var runs []tendRunRecord
+			if last {
+				if r, ok := h.last(); ok {
+					runs = []tendRunRecord{r}
+				}
+			} else {
+				runs = h.failed()
+			}
+			if list {
+				if runs == nil {
+					runs = []tendRunRecord{}
+				}
+				return json.NewEncoder(os.Stdout).Encode(runs)
+			}
+			if len(runs) == 0 {
+				if last {
+					return fmt.Errorf("no tend runs recorded for %s", h.Workspace)
+				}
+				fmt.Println("No failed scenarios to re-run.")
+				return nil
+			}
+
+			var failures []string
+			for _, r := range runs {
+				tpl := r.Command
+				if template != "" {
+					tpl = template
+				}
+				ok, err := execTendCommand(tpl, r.Scenario)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					failures = append(failures, r.Scenario)
+				}
+			}
+			if len(failures) > 0 {
+				return fmt.Errorf("failed: %s", strings.Join(failures, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&last, "last", false, "Re-run the most recent scenario")
+	cmd.Flags().BoolVar(&failed, "failed", false, "Re-run every scenario whose latest run failed")
+	cmd.Flags().StringVar(&template, "template", "", "Override the recorded command template")
+	cmd.Flags().BoolVar(&list, "list", false, "Print the runs that would be replayed as JSON")
+
+	return cmd
+}
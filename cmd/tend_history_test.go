@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTendHistory(t *testing.T) {
+	t.Setenv("GROVE_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	h := loadTendHistory(dir)
+	_, ok := h.last()
+	assert.False(t, ok)
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, r := range []tendRunRecord{
+		{Scenario: "a", Success: false},
+		{Scenario: "b", Success: false},
+		{Scenario: "c", Success: true},
+		{Scenario: "a", Success: true}, // fixed since
+		{Scenario: "c", Success: false},
+	} {
+		r.Command, r.Timestamp = "tend run %s", at
+		h.record(r)
+	}
+	require.NoError(t, h.save())
+
+	reloaded := loadTendHistory(dir)
+	require.Len(t, reloaded.Runs, 5)
+	last, ok := reloaded.last()
+	require.True(t, ok)
+	assert.Equal(t, "c", last.Scenario)
+
+	var failed []string
+	for _, r := range reloaded.failed() {
+		failed = append(failed, r.Scenario)
+	}
+	assert.Equal(t, []string{"b", "c"}, failed)
+
+	for i := 0; i < tendHistoryLimit; i++ {
+		reloaded.record(tendRunRecord{Scenario: "x", Success: true})
+	}
+	assert.Len(t, reloaded.Runs, tendHistoryLimit)
+}
+
+func TestTendCommand(t *testing.T) {
+	assert.Equal(t, "tend run --debug-session 'my-scenario'", tendCommand("tend run --debug-session %s", "my-scenario"))
+	assert.Equal(t, `echo 'it'\''s'`, tendCommand("echo %s", "it's"))
+	assert.Equal(t, "echo 'CUSTOM: x'", tendCommand("echo 'CUSTOM: %s'", "x"), "templates quoting %s are left alone")
+	assert.Equal(t, `run "x"`, tendCommand(`run "%s"`, "x"))
+	assert.Equal(t, "tend run --debug-session 'x'", tendCommand("tend run --debug-session", "x"), "a template without %s gets the name appended")
+	assert.Equal(t, "echo 100% 'x'", tendCommand("echo 100% %s", "x"), "other % signs are not format verbs")
+}
+
+func TestTendRunTemplate(t *testing.T) {
+	tpl := tendRunTemplate("tend", []string{"--timeout", "5m"})
+	assert.Equal(t, "'tend' run %s '--timeout' '5m'", tpl)
+	assert.Equal(t, "'tend' run 'x' '--timeout' '5m'", tendCommand(tpl, "x"))
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultTendDir is where grove repos keep their tend scenarios.
+const defaultTendDir = "tests/e2e"
+
+func newTendListCmd() *cobra.Command {
+	var (
+		jsonOutput bool
+		tags       []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list [dir]",
+		Short: "List the tend scenarios defined in a package",
+		Long: `Statically scans the Go package in dir (default: tests/e2e, else the current
+directory) for functions returning *harness.Scenario and prints each
+scenario's name, description, tags and source position.
+
+Scenarios passed to tend, for example through the scenarios slice in main.go,
+are listed first in registration order and marked registered; constructors
+nothing calls follow.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := ""
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			abs, err := tendPackageDir(dir)
+			if err != nil {
+				return err
+			}
+			pkg, err := parseTendPackage(abs)
+			if err != nil {
+				return fmt.Errorf("no Go files to parse in %s", abs)
+			}
+
+			scenarios := filterByTags(listScenarios(pkg), tags)
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(scenarios)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, s := range scenarios {
+				location := fmt.Sprintf("%s:%d", relativeTo(abs, s.File), s.Line)
+				if !s.Registered {
+					location += " (unregistered)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, strings.Join(s.Tags, ","), location)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output scenarios as JSON")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Only list scenarios carrying every given tag")
+
+	return cmd
+}
+
+// tendPackageDir resolves a scenario package directory, defaulting to
+// tests/e2e when it exists and the current directory otherwise.
+func tendPackageDir(dir string) (string, error) {
+	if dir == "" {
+		dir = defaultTendDir
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			dir = "."
+		}
+	}
+	return filepath.Abs(dir)
+}
+
+// listScenarios orders the package's scenarios: registered ones as tend
+// receives them, then the rest in source order.
+func listScenarios(pkg *tendPackage) []*tendScenario {
+	scenarios := pkg.scenarios()
+	sort.SliceStable(scenarios, func(i, j int) bool {
+		a, b := scenarios[i], scenarios[j]
+		if a.Registered != b.Registered {
+			return a.Registered
+		}
+		return a.order < b.order
+	})
+	return scenarios
+}
+
+func filterByTags(scenarios []*tendScenario, tags []string) []*tendScenario {
+	out := []*tendScenario{}
+	for _, s := range scenarios {
+		matched := true
+		for _, tag := range tags {
+			if !slices.Contains(s.Tags, tag) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func relativeTo(base, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return rel
+	}
+	return path
+}
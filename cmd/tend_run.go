@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// tendReport is the part of tend's --json report file we read.
+type tendReport struct {
+	Results []struct {
+		Name       string `json:"name"`
+		Success    bool   `json:"success"`
+		Duration   string `json:"duration"`
+		FailedStep string `json:"failed_step"`
+		Error      string `json:"error"`
+		Steps      []struct {
+			Name     string `json:"name"`
+			Duration string `json:"duration"`
+			Success  bool   `json:"success"`
+			Error    string `json:"error"`
+		} `json:"steps"`
+	} `json:"results"`
+}
+
+// tendRunResult is one scenario run, steps mapped back to their source.
+type tendRunResult struct {
+	Scenario string        `json:"scenario"`
+	Success  bool          `json:"success"`
+	Duration string        `json:"duration,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	File     string        `json:"file,omitempty"`
+	Line     int           `json:"line,omitempty"`
+	Col      int           `json:"col,omitempty"`
+	Steps    []tendRunStep `json:"steps"`
+}
+
+type tendRunStep struct {
+	Name     string `json:"name"`
+	Success  bool   `json:"success"`
+	Duration string `json:"duration,omitempty"`
+	Error    string `json:"error,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Col      int    `json:"col,omitempty"`
+}
+
+func newTendRunCmd() *cobra.Command {
+	var (
+		jsonOutput bool
+		dir        string
+		tendBin    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run <scenario> [-- tend-args...]",
+		Short: "Run a tend scenario and report per-step results",
+		Long: `Runs 'tend run <scenario>' non-interactively and reports each step's result,
+duration and error. Steps are mapped back to their source positions by
+scanning the scenario package (--dir, default tests/e2e or the current
+directory), so a failure points at the step function that failed.
+
+tend's own output streams to stderr. Exits non-zero when the scenario fails.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			result, err := runTendScenario(tendBin, name, args[1:])
+			if err != nil {
+				return err
+			}
+			if pkg := scenarioPackage(dir); pkg != nil {
+				mapTendSources(result, pkg)
+			}
+			recordTendRun(name, tendRunTemplate(tendBin, args[1:]), result.Success)
+
+			if jsonOutput {
+				if err := json.NewEncoder(os.Stdout).Encode(result); err != nil {
+					return err
+				}
+			} else {
+				printTendRun(os.Stdout, result)
+			}
+			if !result.Success {
+				return fmt.Errorf("scenario %s failed", name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the run result as JSON")
+	cmd.Flags().StringVar(&dir, "dir", "", "Scenario package used to map steps to source (default: tests/e2e or .)")
+	cmd.Flags().StringVar(&tendBin, "tend", "tend", "tend binary to run")
+
+	return cmd
+}
+
+// tendRunTemplate is the command template `tend rerun` replays a structured
+// run with: the same tend binary and extra arguments, without the report
+// file.
+func tendRunTemplate(tendBin string, extra []string) string {
+	parts := []string{shellQuote(tendBin), "run", "%s"}
+	for _, a := range extra {
+		parts = append(parts, shellQuote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// scenarioPackage parses the scenario package. One that cannot be read only
+// costs the source positions, so it yields nil.
+func scenarioPackage(dir string) *tendPackage {
+	abs, err := tendPackageDir(dir)
+	if err != nil {
+		return nil
+	}
+	pkg, err := parseTendPackage(abs)
+	if err != nil {
+		return nil
+	}
+	return pkg
+}
+
+// runTendScenario runs tend with a JSON report file. When tend writes no
+// report (it failed before running anything, or predates --json) the
+// result is recovered from its text output instead.
+func runTendScenario(tendBin, name string, extra []string) (*tendRunResult, error) {
+	tmp, err := os.MkdirTemp("", "grove-tend-run-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	reportPath := filepath.Join(tmp, "report.json")
+
+	args := append([]string{"run", name, "--json", reportPath}, extra...)
+	var output bytes.Buffer
+	// #nosec G204 -- runs the configured tend binary with user-chosen arguments
+	c := exec.Command(tendBin, args...)
+	c.Stdout = io.MultiWriter(os.Stderr, &output)
+	c.Stderr = io.MultiWriter(os.Stderr, &output)
+	runErr := c.Run()
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return nil, fmt.Errorf("failed to run %s: %w", tendBin, runErr)
+	}
+
+	if data, err := os.ReadFile(reportPath); err == nil {
+		if result, ok := resultFromReport(data, name); ok {
+			return result, nil
+		}
+	}
+	result := parseTendOutput(output.String(), name)
+	if runErr != nil {
+		result.Success = false
+		if result.Error == "" {
+			result.Error = lastLines(output.String(), 5)
+		}
+	}
+	return result, nil
+}
+
+func resultFromReport(data []byte, name string) (*tendRunResult, bool) {
+	var report tendReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, false
+	}
+	for _, r := range report.Results {
+		if r.Name != name {
+			continue
+		}
+		result := &tendRunResult{
+			Scenario: r.Name,
+			Success:  r.Success,
+			Duration: r.Duration,
+			Error:    r.Error,
+			Steps:    []tendRunStep{},
+		}
+		for _, s := range r.Steps {
+			result.Steps = append(result.Steps, tendRunStep{
+				Name:     s.Name,
+				Success:  s.Success,
+				Duration: s.Duration,
+				Error:    s.Error,
+			})
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+var (
+	ansiRe          = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	tendStepStartRe = regexp.MustCompile(`^\[\d+/\d+\] (.+)$`)
+	tendStepEndRe   = regexp.MustCompile(`^\S+ (.+) \((Completed in|Failed after) (\S+)\)$`)
+	tendScenarioRe  = regexp.MustCompile(`^\S+ Scenario (completed successfully in (\S+)|failed: )`)
+)
+
+// parseTendOutput recovers step results from tend's text output: "[i/n]
+// name" starts a step, "<icon> name (Failed after 1s)" ends one, and the
+// "Error:" line below a failure carries its message. Steps that start and
+// never fail passed.
+func parseTendOutput(output, name string) *tendRunResult {
+	result := &tendRunResult{Scenario: name, Success: true, Steps: []tendRunStep{}}
+	var current *tendRunStep
+	for _, raw := range strings.Split(ansiRe.ReplaceAllString(output, ""), "\n") {
+		line := strings.TrimSpace(raw)
+		if m := tendStepStartRe.FindStringSubmatch(line); m != nil {
+			result.Steps = append(result.Steps, tendRunStep{Name: m[1], Success: true})
+			current = &result.Steps[len(result.Steps)-1]
+			continue
+		}
+		if m := tendStepEndRe.FindStringSubmatch(line); m != nil && current != nil && m[1] == current.Name {
+			current.Duration = m[3]
+			if m[2] == "Failed after" {
+				current.Success = false
+				result.Success = false
+			}
+			continue
+		}
+		if m := tendScenarioRe.FindStringSubmatch(line); m != nil {
+			if m[2] != "" {
+				result.Duration = m[2]
+			} else {
+				result.Success = false
+			}
+			current = nil
+			continue
+		}
+		if msg, ok := strings.CutPrefix(line, "Error: "); ok {
+			if current != nil && !current.Success && current.Error == "" {
+				current.Error = msg
+			}
+			if result.Error == "" && !result.Success {
+				result.Error = msg
+			}
+		}
+	}
+	return result
+}
+
+// mapTendSources attaches source positions: the scenario's definition and,
+// for each step run, the next unclaimed step of that name in source order.
+func mapTendSources(result *tendRunResult, pkg *tendPackage) {
+	for _, s := range pkg.scenarios() {
+		if s.Name != result.Scenario {
+			continue
+		}
+		result.File, result.Line, result.Col = s.File, s.Line, s.Col
+		next := 0
+		for i := range result.Steps {
+			step := &result.Steps[i]
+			for j := next; j < len(s.Steps); j++ {
+				if s.Steps[j].Name == step.Name {
+					step.File, step.Line, step.Col = s.Steps[j].File, s.Steps[j].Line, s.Steps[j].Col
+					next = j + 1
+					break
+				}
+			}
+			if step.File == "" {
+				step.File, step.Line, step.Col = s.File, s.Line, s.Col
+			}
+		}
+		return
+	}
+}
+
+func printTendRun(w io.Writer, result *tendRunResult) {
+	for _, s := range result.Steps {
+		mark := "✓"
+		if !s.Success {
+			mark = "✗"
+		}
+		fmt.Fprintf(w, "%s %s", mark, s.Name)
+		if s.Duration != "" {
+			fmt.Fprintf(w, " (%s)", s.Duration)
+		}
+		if s.File != "" {
+			fmt.Fprintf(w, "  %s:%d", s.File, s.Line)
+		}
+		fmt.Fprintln(w)
+		if s.Error != "" {
+			fmt.Fprintf(w, "    %s\n", s.Error)
+		}
+	}
+	status := "passed"
+	if !result.Success {
+		status = "failed"
+	}
+	fmt.Fprintf(w, "%s %s", result.Scenario, status)
+	if result.Duration != "" {
+		fmt.Fprintf(w, " in %s", result.Duration)
+	}
+	fmt.Fprintln(w)
+}
+
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(ansiRe.ReplaceAllString(s, ""), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
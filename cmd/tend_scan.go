@@ -0,0 +1,470 @@
+package cmd
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const harnessImportPath = "github.com/grovetools/tend/pkg/harness"
+
+// harnessStepNames are the harness step constructors whose names are fixed
+// rather than passed as the first argument.
+var harnessStepNames = map[string]string{
+	"SetupMocks":                      "Setup Mocks",
+	"SetupMocksWithSubprocessSupport": "Setup test environment with subprocess-safe mocks",
+}
+
+// harnessNamedSteps are the harness step constructors taking the step name
+// as their first argument.
+var harnessNamedSteps = map[string]bool{
+	"NewStep":         true,
+	"SequentialSteps": true,
+	"RetryStep":       true,
+	"ConditionalStep": true,
+	"DelayStep":       true,
+}
+
+// tendScenario is a scenario recovered from source. Line and Col point at
+// the Name value when it is a literal, else at the constructor function.
+type tendScenario struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags"`
+	Func        string     `json:"func"`
+	File        string     `json:"file"`
+	Line        int        `json:"line"`
+	Col         int        `json:"col"`
+	Steps       []tendStep `json:"steps"`
+	// Registered is set when the constructor is called from outside a
+	// scenario, as in the slice tests/e2e/main.go hands to tend.
+	Registered bool `json:"registered"`
+
+	decl  *ast.FuncDecl
+	order int // position among registrations
+}
+
+// tendStep is one entry of a scenario's Setup, Steps or Teardown. Its
+// position is that of the step constructor, inside the helper function when
+// the scenario lists a helper call.
+type tendStep struct {
+	Name   string `json:"name"`
+	Phase  string `json:"phase"` // setup, steps or teardown
+	Helper string `json:"helper,omitempty"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Col    int    `json:"col"`
+
+	expr ast.Node // the entry in the scenario's step list
+}
+
+// tendPackage is the parsed, non-test Go files of one directory.
+type tendPackage struct {
+	fset   *token.FileSet
+	files  []*ast.File
+	funcs  map[string]*ast.FuncDecl
+	consts map[string]ast.Expr
+	// harness maps each file to the name it imports the harness under; files
+	// that do not import it are absent.
+	harness map[*ast.File]string
+}
+
+// parseTendPackage parses dir's Go files. Unparseable files are skipped so
+// a buffer mid-edit elsewhere in the package does not block the rest.
+func parseTendPackage(dir string) (*tendPackage, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	pkg := &tendPackage{
+		fset:    token.NewFileSet(),
+		funcs:   make(map[string]*ast.FuncDecl),
+		consts:  make(map[string]ast.Expr),
+		harness: make(map[*ast.File]string),
+	}
+	for _, path := range matches {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(pkg.fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		pkg.files = append(pkg.files, f)
+		if name, ok := harnessImportName(f); ok {
+			pkg.harness[f] = name
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					pkg.funcs[d.Name.Name] = d
+				}
+			case *ast.GenDecl:
+				if d.Tok != token.CONST {
+					continue
+				}
+				for _, spec := range d.Specs {
+					vs := spec.(*ast.ValueSpec)
+					for i, n := range vs.Names {
+						if i < len(vs.Values) {
+							pkg.consts[n.Name] = vs.Values[i]
+						}
+					}
+				}
+			}
+		}
+	}
+	if len(pkg.files) == 0 {
+		return nil, os.ErrNotExist
+	}
+	return pkg, nil
+}
+
+func harnessImportName(f *ast.File) (string, bool) {
+	for _, imp := range f.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == harnessImportPath {
+			if imp.Name != nil {
+				return imp.Name.Name, true
+			}
+			return "harness", true
+		}
+	}
+	return "", false
+}
+
+// fileOf returns the file declaring node.
+func (pkg *tendPackage) fileOf(node ast.Node) *ast.File {
+	for _, f := range pkg.files {
+		if f.Pos() <= node.Pos() && node.End() <= f.End() {
+			return f
+		}
+	}
+	return nil
+}
+
+// returnsHarness reports whether fd's only result is harness.<typ>, or a
+// pointer to it when ptr is set.
+func (pkg *tendPackage) returnsHarness(fd *ast.FuncDecl, typ string, ptr bool) bool {
+	if fd.Type.Results == nil || len(fd.Type.Results.List) != 1 {
+		return false
+	}
+	name, ok := pkg.harness[pkg.fileOf(fd)]
+	if !ok {
+		return false
+	}
+	expr := fd.Type.Results.List[0].Type
+	if ptr {
+		star, ok := expr.(*ast.StarExpr)
+		if !ok {
+			return false
+		}
+		expr = star.X
+	}
+	return isSelector(expr, name, typ)
+}
+
+func isSelector(expr ast.Expr, pkgName, sel string) bool {
+	s, ok := expr.(*ast.SelectorExpr)
+	if !ok || s.Sel.Name != sel {
+		return false
+	}
+	id, ok := s.X.(*ast.Ident)
+	return ok && id.Name == pkgName
+}
+
+// harnessCall returns the harness function call invokes, if any.
+func harnessCall(call *ast.CallExpr, pkgName string) (string, bool) {
+	s, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	id, ok := s.X.(*ast.Ident)
+	if !ok || id.Name != pkgName {
+		return "", false
+	}
+	return s.Sel.Name, true
+}
+
+// scenarios returns every scenario constructor in the package, in file
+// and declaration order.
+func (pkg *tendPackage) scenarios() []*tendScenario {
+	var out []*tendScenario
+	for _, f := range pkg.files {
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil || fd.Body == nil || !pkg.returnsHarness(fd, "Scenario", true) {
+				continue
+			}
+			out = append(out, pkg.scenarioFrom(fd))
+		}
+	}
+	pkg.markRegistered(out)
+	return out
+}
+
+// markRegistered flags the scenarios whose constructors are called outside
+// any scenario constructor, recording the order of the first call.
+func (pkg *tendPackage) markRegistered(scenarios []*tendScenario) {
+	byFunc := make(map[string]*tendScenario, len(scenarios))
+	for _, s := range scenarios {
+		byFunc[s.Func] = s
+	}
+	order := 0
+	for _, f := range pkg.files {
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && byFunc[fd.Name.Name] != nil && byFunc[fd.Name.Name].decl == fd {
+				continue
+			}
+			ast.Inspect(decl, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				id, ok := call.Fun.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				if s := byFunc[id.Name]; s != nil && !s.Registered {
+					order++
+					s.Registered, s.order = true, order
+				}
+				return true
+			})
+		}
+	}
+}
+
+// scenarioFrom reads the scenario fd returns: a &harness.Scenario literal or
+// a harness.NewScenario* call, optionally chained with WithSetup and
+// WithTeardown, returned directly or through a local variable.
+func (pkg *tendPackage) scenarioFrom(fd *ast.FuncDecl) *tendScenario {
+	hn := pkg.harness[pkg.fileOf(fd)]
+	s := &tendScenario{Func: fd.Name.Name, Tags: []string{}, Steps: []tendStep{}, decl: fd}
+	namePos := fd.Name.Pos()
+
+	expr := lastReturn(fd)
+	if id, ok := expr.(*ast.Ident); ok {
+		expr = localValue(fd, id.Name)
+	}
+	var setup, teardown []ast.Expr
+	for {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			break
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (sel.Sel.Name != "WithSetup" && sel.Sel.Name != "WithTeardown") {
+			break
+		}
+		if sel.Sel.Name == "WithSetup" {
+			setup = append(append([]ast.Expr{}, call.Args...), setup...)
+		} else {
+			teardown = append(append([]ast.Expr{}, call.Args...), teardown...)
+		}
+		expr = sel.X
+	}
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		expr = u.X
+	}
+
+	var steps []ast.Expr
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		if !isSelector(e.Type, hn, "Scenario") {
+			break
+		}
+		for _, elt := range e.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, _ := kv.Key.(*ast.Ident)
+			if key == nil {
+				continue
+			}
+			switch key.Name {
+			case "Name":
+				s.Name = pkg.stringValue(kv.Value)
+				namePos = kv.Value.Pos()
+			case "Description":
+				s.Description = pkg.stringValue(kv.Value)
+			case "Tags":
+				s.Tags = pkg.stringsValue(fd, kv.Value)
+			case "Setup":
+				setup = append(append([]ast.Expr{}, pkg.stepExprs(fd, kv.Value)...), setup...)
+			case "Steps":
+				steps = pkg.stepExprs(fd, kv.Value)
+			case "Teardown":
+				teardown = append(append([]ast.Expr{}, pkg.stepExprs(fd, kv.Value)...), teardown...)
+			}
+		}
+	case *ast.CallExpr:
+		if fn, ok := harnessCall(e, hn); !ok || (fn != "NewScenario" && fn != "NewScenarioWithOptions") || len(e.Args) < 4 {
+			break
+		}
+		s.Name = pkg.stringValue(e.Args[0])
+		namePos = e.Args[0].Pos()
+		s.Description = pkg.stringValue(e.Args[1])
+		s.Tags = pkg.stringsValue(fd, e.Args[2])
+		steps = pkg.stepExprs(fd, e.Args[3])
+	}
+
+	pos := pkg.fset.Position(namePos)
+	s.File, s.Line, s.Col = pos.Filename, pos.Line, pos.Column
+	for _, phase := range []struct {
+		name  string
+		exprs []ast.Expr
+	}{{"setup", setup}, {"steps", steps}, {"teardown", teardown}} {
+		for _, e := range phase.exprs {
+			st := pkg.stepFrom(e, hn)
+			st.Phase = phase.name
+			s.Steps = append(s.Steps, st)
+		}
+	}
+	return s
+}
+
+// lastReturn is the first result of fd's final top-level return statement.
+func lastReturn(fd *ast.FuncDecl) ast.Expr {
+	for i := len(fd.Body.List) - 1; i >= 0; i-- {
+		if ret, ok := fd.Body.List[i].(*ast.ReturnStmt); ok && len(ret.Results) > 0 {
+			return ret.Results[0]
+		}
+	}
+	return nil
+}
+
+// localValue finds the value fd's body first assigns to name.
+func localValue(fd *ast.FuncDecl, name string) ast.Expr {
+	var value ast.Expr
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		if value != nil {
+			return false
+		}
+		switch s := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range s.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Name == name && i < len(s.Rhs) {
+					value = s.Rhs[i]
+				}
+			}
+		case *ast.ValueSpec:
+			for i, id := range s.Names {
+				if id.Name == name && i < len(s.Values) {
+					value = s.Values[i]
+				}
+			}
+		}
+		return value == nil
+	})
+	return value
+}
+
+// stringValue evaluates a string literal, a package constant, or a
+// concatenation of those; anything else yields "".
+func (pkg *tendPackage) stringValue(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			v, _ := strconv.Unquote(e.Value)
+			return v
+		}
+	case *ast.Ident:
+		if v, ok := pkg.consts[e.Name]; ok {
+			return pkg.stringValue(v)
+		}
+	case *ast.ParenExpr:
+		return pkg.stringValue(e.X)
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			return pkg.stringValue(e.X) + pkg.stringValue(e.Y)
+		}
+	}
+	return ""
+}
+
+func (pkg *tendPackage) stringsValue(fd *ast.FuncDecl, expr ast.Expr) []string {
+	if id, ok := expr.(*ast.Ident); ok {
+		expr = localValue(fd, id.Name)
+	}
+	out := []string{}
+	if lit, ok := expr.(*ast.CompositeLit); ok {
+		for _, elt := range lit.Elts {
+			if v := pkg.stringValue(elt); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// stepExprs returns the elements of a []harness.Step literal, following a
+// local variable holding one.
+func (pkg *tendPackage) stepExprs(fd *ast.FuncDecl, expr ast.Expr) []ast.Expr {
+	if id, ok := expr.(*ast.Ident); ok {
+		expr = localValue(fd, id.Name)
+	}
+	if lit, ok := expr.(*ast.CompositeLit); ok {
+		return lit.Elts
+	}
+	return nil
+}
+
+// stepFrom resolves one step expression: a harness step constructor, a
+// harness.Step literal, or a call to a helper in the package returning one.
+func (pkg *tendPackage) stepFrom(expr ast.Expr, hn string) tendStep {
+	st := tendStep{expr: expr}
+	pos := expr.Pos()
+	if call, ok := expr.(*ast.CallExpr); ok {
+		if id, ok := call.Fun.(*ast.Ident); ok {
+			st.Helper = id.Name
+			if helper, ok := pkg.funcs[id.Name]; ok && helper.Body != nil && pkg.returnsHarness(helper, "Step", false) {
+				if ret := lastReturn(helper); ret != nil {
+					expr = ret
+					pos = ret.Pos()
+					hn = pkg.harness[pkg.fileOf(helper)]
+				}
+			}
+		}
+	}
+	st.Name = pkg.stepName(expr, hn)
+	p := pkg.fset.Position(pos)
+	st.File, st.Line, st.Col = p.Filename, p.Line, p.Column
+	return st
+}
+
+func (pkg *tendPackage) stepName(expr ast.Expr, hn string) string {
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		fn, ok := harnessCall(e, hn)
+		if !ok {
+			return ""
+		}
+		if name, ok := harnessStepNames[fn]; ok {
+			return name
+		}
+		if harnessNamedSteps[fn] && len(e.Args) > 0 {
+			return pkg.stringValue(e.Args[0])
+		}
+	case *ast.CompositeLit:
+		if !isSelector(e.Type, hn, "Step") {
+			return ""
+		}
+		for _, elt := range e.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Name" {
+					return pkg.stringValue(kv.Value)
+				}
+			}
+		}
+	}
+	return ""
+}
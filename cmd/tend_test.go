@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tendFixtureScenarios = `package main
+
+import (
+	"github.com/grovetools/tend/pkg/harness"
+)
+
+const prefix = "grove-"
+
+func LiteralScenario() *harness.Scenario {
+	return &harness.Scenario{
+		Name:        "literal-scenario",
+		Description: "Built from a composite literal",
+		Tags:        []string{"smoke", "nvim"},
+		Steps: []harness.Step{
+			harness.NewStep("inline step", func(ctx *harness.Context) error {
+				return nil
+			}),
+			sharedStep(),
+		},
+	}
+}
+
+func ConstructedScenario() *harness.Scenario {
+	steps := []harness.Step{
+		sharedStep(),
+		harness.SetupMocks(),
+	}
+	return harness.NewScenario(prefix+"constructed", "Built with NewScenario", []string{"slow"}, steps).
+		WithTeardown(harness.DelayStep("settle", 0))
+}
+`
+
+const tendFixtureSteps = `package main
+
+import h "github.com/grovetools/tend/pkg/harness"
+
+func sharedStep() h.Step {
+	return h.NewStep("shared step", func(ctx *h.Context) error {
+		return nil
+	})
+}
+`
+
+// writeTendFixture writes a two-file scenario package and returns its dir.
+func writeTendFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scenarios.go"), []byte(tendFixtureScenarios), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "steps.go"), []byte(tendFixtureSteps), 0o600))
+	return dir
+}
+
+// fixtureLine is the 1-based line of the first line of content containing s.
+func fixtureLine(t *testing.T, content, s string) int {
+	t.Helper()
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, s) {
+			return i + 1
+		}
+	}
+	t.Fatalf("fixture has no line containing %q", s)
+	return 0
+}
+
+func TestTendScenarios(t *testing.T) {
+	pkg, err := parseTendPackage(writeTendFixture(t))
+	require.NoError(t, err)
+
+	scenarios := pkg.scenarios()
+	require.Len(t, scenarios, 2)
+
+	lit := scenarios[0]
+	assert.Equal(t, "literal-scenario", lit.Name)
+	assert.Equal(t, "Built from a composite literal", lit.Description)
+	assert.Equal(t, []string{"smoke", "nvim"}, lit.Tags)
+	assert.Equal(t, fixtureLine(t, tendFixtureScenarios, `Name:        "literal-scenario"`), lit.Line)
+	require.Len(t, lit.Steps, 2)
+	assert.Equal(t, "inline step", lit.Steps[0].Name)
+	assert.Equal(t, "shared step", lit.Steps[1].Name)
+	assert.Equal(t, "sharedStep", lit.Steps[1].Helper)
+	assert.Equal(t, "steps.go", filepath.Base(lit.Steps[1].File), "helper steps point into the helper")
+	assert.Equal(t, fixtureLine(t, tendFixtureSteps, `h.NewStep("shared step"`), lit.Steps[1].Line)
+
+	built := scenarios[1]
+	assert.Equal(t, "grove-constructed", built.Name, "constants are folded")
+	assert.Equal(t, []string{"slow"}, built.Tags)
+	var names, phases []string
+	for _, s := range built.Steps {
+		names = append(names, s.Name)
+		phases = append(phases, s.Phase)
+	}
+	assert.Equal(t, []string{"shared step", "Setup Mocks", "settle"}, names)
+	assert.Equal(t, []string{"steps", "steps", "teardown"}, phases)
+}
+
+func TestLocateScenario(t *testing.T) {
+	dir := writeTendFixture(t)
+	scenarios := filepath.Join(dir, "scenarios.go")
+
+	t.Run("inside the constructor", func(t *testing.T) {
+		res, err := locateScenario(scenarios, fixtureLine(t, tendFixtureScenarios, "Tags:"), 3)
+		require.NoError(t, err)
+		require.Len(t, res.Scenarios, 1)
+		assert.Equal(t, "literal-scenario", res.Scenarios[0].Name)
+		assert.Nil(t, res.Step)
+	})
+
+	t.Run("inside an inline step", func(t *testing.T) {
+		res, err := locateScenario(scenarios, fixtureLine(t, tendFixtureScenarios, "return nil"), 5)
+		require.NoError(t, err)
+		require.NotNil(t, res.Step)
+		assert.Equal(t, "inline step", res.Step.Name)
+	})
+
+	t.Run("inside a step helper", func(t *testing.T) {
+		res, err := locateScenario(filepath.Join(dir, "steps.go"), fixtureLine(t, tendFixtureSteps, "return nil"), 1)
+		require.NoError(t, err)
+		require.Len(t, res.Scenarios, 2, "every scenario using the helper")
+		require.NotNil(t, res.Step)
+		assert.Equal(t, "shared step", res.Step.Name)
+	})
+
+	t.Run("outside any scenario", func(t *testing.T) {
+		_, err := locateScenario(scenarios, fixtureLine(t, tendFixtureScenarios, "const prefix"), 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestListScenarios(t *testing.T) {
+	dir := writeTendFixture(t)
+	mainGo := `package main
+
+import "github.com/grovetools/tend/pkg/harness"
+
+func main() {
+	scenarios := []*harness.Scenario{
+		ConstructedScenario(),
+	}
+	_ = scenarios
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(mainGo), 0o600))
+
+	pkg, err := parseTendPackage(dir)
+	require.NoError(t, err)
+	scenarios := listScenarios(pkg)
+	require.Len(t, scenarios, 2)
+	assert.Equal(t, "grove-constructed", scenarios[0].Name, "registered scenarios come first")
+	assert.True(t, scenarios[0].Registered)
+	assert.Equal(t, "literal-scenario", scenarios[1].Name)
+	assert.False(t, scenarios[1].Registered)
+
+	smoke := filterByTags(scenarios, []string{"smoke", "nvim"})
+	require.Len(t, smoke, 1)
+	assert.Equal(t, "literal-scenario", smoke[0].Name)
+	assert.Empty(t, filterByTags(scenarios, []string{"smoke", "slow"}))
+}
+
+func TestParseTendOutput(t *testing.T) {
+	output := "\n\x1b[1m🧪 Scenario: literal-scenario\x1b[0m\n" +
+		"------------------------------------------------------------\n" +
+		"\n[1/2] inline step\n" +
+		"✓ inline step (Completed in 12ms)\n" +
+		"\n[2/2] shared step\n" +
+		"✗ shared step (Failed after 1.5s)\n" +
+		"  ✓ file exists\n" +
+		"  Error: expected 'ok' in output\n" +
+		"------------------------------------------------------------\n" +
+		"✗ Scenario failed: literal-scenario\n" +
+		"Error: step shared step failed\n"
+
+	result := parseTendOutput(output, "literal-scenario")
+	assert.False(t, result.Success)
+	assert.Equal(t, "expected 'ok' in output", result.Error)
+	require.Len(t, result.Steps, 2)
+	assert.Equal(t, tendRunStep{Name: "inline step", Success: true, Duration: "12ms"}, result.Steps[0])
+	assert.Equal(t, tendRunStep{Name: "shared step", Duration: "1.5s", Error: "expected 'ok' in output"}, result.Steps[1])
+
+	passed := parseTendOutput("[1/1] only\n✓ Scenario completed successfully in 2s\n", "x")
+	assert.True(t, passed.Success)
+	assert.Equal(t, "2s", passed.Duration)
+	assert.True(t, passed.Steps[0].Success, "steps that never fail passed")
+}
+
+func TestResultFromReportMapsSources(t *testing.T) {
+	pkg, err := parseTendPackage(writeTendFixture(t))
+	require.NoError(t, err)
+
+	report := `{"results": [{"name": "grove-constructed", "success": false, "duration": "3s",
+		"failed_step": "Setup Mocks", "error": "mock missing",
+		"steps": [
+			{"name": "shared step", "success": true, "duration": "1s"},
+			{"name": "Setup Mocks", "success": false, "duration": "2s", "error": "mock missing"}
+		]}]}`
+	result, ok := resultFromReport([]byte(report), "grove-constructed")
+	require.True(t, ok)
+	mapTendSources(result, pkg)
+
+	assert.False(t, result.Success)
+	assert.Equal(t, "scenarios.go", filepath.Base(result.File))
+	require.Len(t, result.Steps, 2)
+	assert.Equal(t, "steps.go", filepath.Base(result.Steps[0].File), "helper step maps into the helper")
+	assert.Equal(t, fixtureLine(t, tendFixtureScenarios, "harness.SetupMocks()"), result.Steps[1].Line)
+	assert.Equal(t, "mock missing", result.Steps[1].Error)
+
+	_, ok = resultFromReport([]byte(report), "other")
+	assert.False(t, ok)
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newThemeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "theme",
+		Short: "Build artifacts from grove themes",
+		Long: `Works with the themes in core's registry, resolved the same way as
+'internal theme': GROVE_THEME, then the config's tui.theme, then the default.`,
+	}
+	cmd.AddCommand(newThemeCompileCmd())
+	cmd.AddCommand(newThemeExportCmd())
+	cmd.AddCommand(newThemeAuditCmd())
+	return cmd
+}
+
+// themePayload mirrors the theme_changed wire shape that 'internal theme'
+// prints and the Lua engine consumes.
+type themePayload struct {
+	Name   string        `json:"name"`
+	Family string        `json:"family"`
+	Mode   string        `json:"mode"` // "hex" or "ansi"
+	Dark   *themePalette `json:"dark,omitempty"`
+	Light  *themePalette `json:"light,omitempty"`
+}
+
+// themePalette is one appearance slot of a payload, with the role names the
+// Lua highlight groups read.
+type themePalette struct {
+	Name        string `json:"name"`
+	Appearance  string `json:"appearance"`
+	Bg          string `json:"bg"`
+	BgDark      string `json:"bg_dark"`
+	BgHighlight string `json:"bg_highlight"`
+	BgVisual    string `json:"bg_visual"`
+	Fg          string `json:"fg"`
+	FgDark      string `json:"fg_dark"`
+	FgGutter    string `json:"fg_gutter"`
+	Border      string `json:"border"`
+	Comment     string `json:"comment"`
+	Red         string `json:"red"`
+	Orange      string `json:"orange"`
+	Yellow      string `json:"yellow"`
+	Green       string `json:"green"`
+	Cyan        string `json:"cyan"`
+	Blue        string `json:"blue"`
+	Purple      string `json:"purple"`
+	Magenta     string `json:"magenta"`
+	Git         struct {
+		Add    string `json:"add"`
+		Change string `json:"change"`
+		Delete string `json:"delete"`
+	} `json:"git"`
+	Diagnostics struct {
+		Error   string `json:"error"`
+		Warning string `json:"warning"`
+		Info    string `json:"info"`
+		Hint    string `json:"hint"`
+	} `json:"diagnostics"`
+	Terminal map[string]string `json:"terminal"`
+
+	// raw is the slot as received, roles this mirror does not name included.
+	raw json.RawMessage
+}
+
+func (p *themePalette) UnmarshalJSON(data []byte) error {
+	type plain themePalette
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+	p.raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// terminalSlots orders a palette's terminal colors as ANSI indices 0-15.
+var terminalSlots = []string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"black_bright", "red_bright", "green_bright", "yellow_bright",
+	"blue_bright", "magenta_bright", "cyan_bright", "white_bright",
+}
+
+// appearances returns the payload's palettes, dark first. A non-empty only
+// selects a single appearance, which the theme must have.
+func (p *themePayload) appearances(only string) ([]*themePalette, error) {
+	switch only {
+	case "":
+	case "dark", "light":
+		slot := p.Dark
+		if only == "light" {
+			slot = p.Light
+		}
+		if slot == nil {
+			return nil, fmt.Errorf("theme %q has no %s appearance", p.Name, only)
+		}
+		return []*themePalette{slot}, nil
+	default:
+		return nil, fmt.Errorf("invalid appearance %q (want dark or light)", only)
+	}
+
+	var out []*themePalette
+	if p.Dark != nil {
+		out = append(out, p.Dark)
+	}
+	if p.Light != nil {
+		out = append(out, p.Light)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("theme %q has no palettes", p.Name)
+	}
+	return out, nil
+}
+
+// resolved fills the derived roles the Lua engine's colors.setup fills, so
+// consumers outside the editor see the same colors.
+func (p *themePalette) resolved() *themePalette {
+	c := *p
+	c.Git.Add = firstNonEmpty(c.Git.Add, c.Green)
+	c.Git.Change = firstNonEmpty(c.Git.Change, c.Blue)
+	c.Git.Delete = firstNonEmpty(c.Git.Delete, c.Red)
+	c.Diagnostics.Error = firstNonEmpty(c.Diagnostics.Error, c.Red)
+	c.Diagnostics.Warning = firstNonEmpty(c.Diagnostics.Warning, c.Yellow)
+	c.Diagnostics.Info = firstNonEmpty(c.Diagnostics.Info, c.Blue)
+	c.Diagnostics.Hint = firstNonEmpty(c.Diagnostics.Hint, c.Cyan)
+	c.BgDark = firstNonEmpty(c.BgDark, c.Bg)
+	c.FgDark = firstNonEmpty(c.FgDark, c.Fg)
+	c.BgHighlight = firstNonEmpty(c.BgHighlight, c.BgDark)
+	c.BgVisual = firstNonEmpty(c.BgVisual, c.BgHighlight)
+	c.Border = firstNonEmpty(c.Border, c.FgGutter, c.Comment)
+	return &c
+}
+
+// ansiColors returns the 16 terminal colors, falling back to the accent
+// roles for a palette that carries no terminal slots.
+func (p *themePalette) ansiColors() []string {
+	accents := []string{p.BgDark, p.Red, p.Green, p.Yellow, p.Blue, firstNonEmpty(p.Magenta, p.Purple), p.Cyan, p.FgDark}
+	colors := make([]string, len(terminalSlots))
+	for i, slot := range terminalSlots {
+		colors[i] = firstNonEmpty(p.Terminal[slot], accents[i%8])
+	}
+	return colors
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// blendHex mixes fg over bg; alpha 0 is bg and 1 is fg. It matches the Lua
+// engine's util.blend, which derives the diff backgrounds.
+func blendHex(fg string, alpha float64, bg string) string {
+	f, okF := parseHex(fg)
+	b, okB := parseHex(bg)
+	if !okF || !okB {
+		return fg
+	}
+	var out [3]int
+	for i := range out {
+		v := alpha*float64(f[i]) + (1-alpha)*float64(b[i])
+		out[i] = int(math.Floor(math.Min(math.Max(0, v), 255) + 0.5))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", out[0], out[1], out[2])
+}
+
+// parseHex parses "#rrggbb".
+func parseHex(c string) ([3]int, bool) {
+	var rgb [3]int
+	if len(c) != 7 || c[0] != '#' {
+		return rgb, false
+	}
+	for i := range rgb {
+		v, err := strconv.ParseUint(c[1+2*i:3+2*i], 16, 8)
+		if err != nil {
+			return rgb, false
+		}
+		rgb[i] = int(v)
+	}
+	return rgb, true
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// WCAG 2.x contrast minimums: AA for body text, and the large-text/UI
+// component threshold for chrome that is read at a glance.
+const (
+	contrastText = 4.5
+	contrastUI   = 3.0
+)
+
+// contrastPair is a foreground/background combination some highlight group
+// renders.
+type contrastPair struct {
+	Group    string
+	Fg, Bg   string
+	Required float64
+}
+
+// auditPairs lists the pairs grove.nvim's highlight groups use, with the
+// roles theme/groups/base.lua, chat_ui.lua and the status bar assign them.
+func auditPairs(p *themePalette) []contrastPair {
+	text := func(group, fg, bg string) contrastPair { return contrastPair{group, fg, bg, contrastText} }
+	ui := func(group, fg, bg string) contrastPair { return contrastPair{group, fg, bg, contrastUI} }
+	return []contrastPair{
+		text("Normal", p.Fg, p.Bg),
+		text("Comment", p.Comment, p.Bg),
+		text("NormalFloat", p.Fg, p.BgDark),
+		text("Pmenu", p.Fg, p.BgDark),
+		text("CursorLine", p.Fg, p.BgHighlight),
+		text("Visual", p.BgDark, p.BgVisual),
+		text("Search", p.BgDark, p.Yellow),
+		text("IncSearch", p.Bg, p.Orange),
+		text("TabLineSel", p.Fg, p.Blue),
+		ui("LineNr", p.FgGutter, p.Bg),
+
+		// Diagnostics: signs and virtual text sit on the editor background.
+		text("DiagnosticError", p.Diagnostics.Error, p.Bg),
+		text("DiagnosticWarn", p.Diagnostics.Warning, p.Bg),
+		text("DiagnosticInfo", p.Diagnostics.Info, p.Bg),
+		text("DiagnosticHint", p.Diagnostics.Hint, p.Bg),
+
+		// Chat turn dividers link to Comment and the diagnostic groups.
+		ui("GroveChatDivider", p.Comment, p.Bg),
+		ui("GroveChatUserTurn", p.Diagnostics.Warning, p.Bg),
+		ui("GroveChatLLMTurn", p.Green, p.Bg),
+		ui("GroveChatLLMRunning", p.Diagnostics.Info, p.Bg),
+
+		// Status bar (theme.ui_colors) on the StatusLine background.
+		text("StatusLine", p.FgDark, p.Bg),
+		ui("GroveStatusMuted", p.Comment, p.Bg),
+		ui("GroveStatusSeparator", p.FgGutter, p.Bg),
+		ui("GroveStatusGitAhead", p.Diagnostics.Info, p.Bg),
+		ui("GroveStatusGitBehind", p.Diagnostics.Error, p.Bg),
+		ui("GroveStatusGitModified", p.Orange, p.Bg),
+		ui("GroveStatusGitAdded", p.Git.Add, p.Bg),
+		ui("GroveStatusGitDeleted", p.Git.Delete, p.Bg),
+	}
+}
+
+// contrastFailure is a pair below its required ratio.
+type contrastFailure struct {
+	Group    string  `json:"group"`
+	Fg       string  `json:"fg"`
+	Bg       string  `json:"bg"`
+	Ratio    float64 `json:"ratio"`
+	Required float64 `json:"required"`
+}
+
+type appearanceAudit struct {
+	Appearance string            `json:"appearance"`
+	Palette    string            `json:"palette"`
+	Checked    int               `json:"checked"`
+	Failures   []contrastFailure `json:"failures"`
+}
+
+type themeAudit struct {
+	Name        string            `json:"name"`
+	Skipped     string            `json:"skipped,omitempty"`
+	Appearances []appearanceAudit `json:"appearances,omitempty"`
+}
+
+func newThemeAuditCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "audit [name]",
+		Short: "Check a theme's highlight colors against WCAG contrast ratios",
+		Long: `Computes WCAG contrast ratios for the foreground/background pairs grove.nvim's
+highlight groups use — editor text, diagnostics, chat turn dividers and the
+status bar — and prints the failing pairs per appearance as JSON. Body text
+must reach 4.5:1 and chrome 3:1.
+
+Audits the resolved current theme, the named one, or with --all every theme
+in core's theme registry. Exits non-zero when any pair fails, or when --all
+finds no palette to audit.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var names []string
+			switch {
+			case all && len(args) > 0:
+				return fmt.Errorf("pass a theme name or --all, not both")
+			case all:
+				names = themeRegistryNames()
+			case len(args) > 0:
+				names = args
+			default:
+				names = []string{resolveThemeName()}
+			}
+
+			audits := []themeAudit{}
+			seen := make(map[string]bool)
+			failed := 0
+			for _, name := range names {
+				payload, ok := lookupThemePayload(name)
+				if !ok {
+					if !all {
+						return fmt.Errorf("theme registry has no palette for %q", name)
+					}
+					continue
+				}
+				a := auditTheme(name, payload, seen)
+				if a.Skipped == "" && len(a.Appearances) == 0 {
+					continue // every palette already audited under another name
+				}
+				for _, app := range a.Appearances {
+					failed += len(app.Failures)
+				}
+				audits = append(audits, a)
+			}
+
+			if all && len(audits) == 0 {
+				return fmt.Errorf("core's theme registry has no palettes to audit")
+			}
+			if err := json.NewEncoder(os.Stdout).Encode(audits); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d highlight pair(s) below the WCAG contrast minimum", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Audit every theme in core's theme registry")
+
+	return cmd
+}
+
+// auditTheme checks each of the payload's palettes not already in seen.
+func auditTheme(name string, payload *themePayload, seen map[string]bool) themeAudit {
+	a := themeAudit{Name: name}
+	if payload.Mode == "ansi" {
+		a.Skipped = "ansi palette; the terminal decides the colors"
+		return a
+	}
+	palettes, _ := payload.appearances("")
+	for _, raw := range palettes {
+		if seen[raw.Name] {
+			continue
+		}
+		seen[raw.Name] = true
+
+		p := raw.resolved()
+		pairs := auditPairs(p)
+		app := appearanceAudit{Appearance: p.Appearance, Palette: p.Name, Failures: []contrastFailure{}}
+		for _, pair := range pairs {
+			ratio, ok := contrastRatio(pair.Fg, pair.Bg)
+			if !ok {
+				continue
+			}
+			app.Checked++
+			if ratio < pair.Required {
+				app.Failures = append(app.Failures, contrastFailure{
+					Group:    pair.Group,
+					Fg:       pair.Fg,
+					Bg:       pair.Bg,
+					Ratio:    math.Round(ratio*100) / 100,
+					Required: pair.Required,
+				})
+			}
+		}
+		a.Appearances = append(a.Appearances, app)
+	}
+	return a
+}
+
+// contrastRatio is the WCAG contrast ratio of two "#rrggbb" colors.
+func contrastRatio(fg, bg string) (float64, bool) {
+	f, okF := parseHex(fg)
+	b, okB := parseHex(bg)
+	if !okF || !okB {
+		return 0, false
+	}
+	l1, l2 := relativeLuminance(f), relativeLuminance(b)
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05), true
+}
+
+// relativeLuminance follows the WCAG 2.x definition for sRGB.
+func relativeLuminance(rgb [3]int) float64 {
+	var lin [3]float64
+	for i, c := range rgb {
+		v := float64(c) / 255
+		if v <= 0.03928 {
+			lin[i] = v / 12.92
+		} else {
+			lin[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return 0.2126*lin[0] + 0.7152*lin[1] + 0.0722*lin[2]
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContrastRatio(t *testing.T) {
+	r, ok := contrastRatio("#000000", "#ffffff")
+	require.True(t, ok)
+	assert.InDelta(t, 21.0, r, 0.001)
+
+	r, _ = contrastRatio("#777777", "#ffffff")
+	assert.InDelta(t, 4.48, r, 0.01, "the classic just-fails-AA grey")
+
+	r2, _ := contrastRatio("#ffffff", "#777777")
+	assert.Equal(t, r, r2, "order does not matter")
+
+	_, ok = contrastRatio("1", "#ffffff")
+	assert.False(t, ok, "ANSI indices are not audited")
+}
+
+func TestAuditTheme(t *testing.T) {
+	dark := samplePalette()
+	dark.Comment = "#2a2a37" // nearly invisible on the background
+	payload := &themePayload{Name: "sample", Mode: "hex", Dark: dark}
+
+	a := auditTheme("sample", payload, map[string]bool{})
+	require.Len(t, a.Appearances, 1)
+	app := a.Appearances[0]
+	assert.Equal(t, "sample-dark", app.Palette)
+	assert.Positive(t, app.Checked)
+
+	groups := map[string]contrastFailure{}
+	for _, f := range app.Failures {
+		groups[f.Group] = f
+	}
+	require.Contains(t, groups, "Comment")
+	assert.Equal(t, contrastText, groups["Comment"].Required)
+	assert.Contains(t, groups, "GroveChatDivider", "the chat divider links to Comment")
+	assert.NotContains(t, groups, "Normal")
+
+	seen := map[string]bool{"sample-dark": true}
+	assert.Empty(t, auditTheme("alias", payload, seen).Appearances, "palettes are audited once")
+
+	ansi := auditTheme("terminal", &themePayload{Name: "terminal", Mode: "ansi"}, map[string]bool{})
+	assert.NotEmpty(t, ansi.Skipped)
+}
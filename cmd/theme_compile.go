@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// compileGroupsScript evaluates the engine's highlight groups for one
+// palette in a headless Neovim, so the compiled colorscheme comes from the
+// same theme/groups/* definitions the live engine applies. Every plugin set
+// is enabled: the output has to serve configurations we cannot inspect.
+const compileGroupsScript = `
+local runtime, input = arg[1], arg[2]
+vim.opt.rtp:prepend(runtime)
+local palette = vim.json.decode(table.concat(vim.fn.readfile(input), "\n"))
+local opts = vim.deepcopy(require("grove-nvim.config").options.ui.theme)
+opts.plugins = { all = true }
+local c = require("grove-nvim.theme.colors").setup(palette, opts)
+local groups = require("grove-nvim.theme.groups").setup(c, opts)
+io.stdout:write(vim.json.encode({ groups = groups, terminal = c.terminal or vim.empty_dict() }))
+`
+
+func newThemeCompileCmd() *cobra.Command {
+	var (
+		name       string
+		appearance string
+		out        string
+		runtimeDir string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "compile",
+		Short: "Compile a grove theme into a static Neovim colorscheme",
+		Long: `Resolves every highlight group from lua/grove-nvim/theme/groups/* for the
+theme and writes a self-contained colorscheme file. Loading it runs no
+subprocess and needs neither grove nor grove.nvim installed.
+
+Without --appearance both appearances are compiled and 'background' picks
+between them. Compiling evaluates the groups in a headless nvim against the
+grove.nvim checkout found via --runtime, $GROVE_NVIM_RUNTIME, the current
+directory, or the usual plugin manager locations.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			payload, err := themePayloadForFlag(name)
+			if err != nil {
+				return err
+			}
+			if payload.Mode == "ansi" {
+				return fmt.Errorf("theme %q passes terminal colors through and has no palette to compile", payload.Name)
+			}
+			palettes, err := payload.appearances(appearance)
+			if err != nil {
+				return err
+			}
+
+			if runtimeDir == "" {
+				if runtimeDir, err = findPluginRuntime(); err != nil {
+					return err
+				}
+			}
+			nvim, err := exec.LookPath("nvim")
+			if err != nil {
+				return fmt.Errorf("'nvim' not found in PATH; it is needed to evaluate the highlight groups")
+			}
+
+			variants := make([]compiledVariant, 0, len(palettes))
+			for _, p := range palettes {
+				v, err := evaluateThemeGroups(nvim, runtimeDir, p)
+				if err != nil {
+					return err
+				}
+				variants = append(variants, v)
+			}
+
+			if out == "" {
+				out = filepath.Join("colors", "grove-"+payload.Name+".lua")
+			}
+			if err := os.MkdirAll(filepath.Dir(out), 0o750); err != nil {
+				return err
+			}
+			if err := os.WriteFile(out, []byte(renderColorscheme(payload.Name, variants)), 0o600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", out, err)
+			}
+			fmt.Println(out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Theme to compile (default: the resolved current theme)")
+	cmd.Flags().StringVar(&appearance, "appearance", "", "Compile only this appearance: dark or light")
+	cmd.Flags().StringVar(&out, "out", "", "Output file (default: colors/grove-<name>.lua)")
+	cmd.Flags().StringVar(&runtimeDir, "runtime", "", "grove.nvim checkout providing lua/grove-nvim/theme")
+
+	return cmd
+}
+
+// themePayloadForFlag loads the theme a --name flag asks for. An explicit
+// name must exist: falling back to the default would compile or export the
+// wrong theme under the requested name. Without one the resolved current
+// theme is used.
+func themePayloadForFlag(name string) (*themePayload, error) {
+	if name == "" {
+		return loadThemePayload(resolveThemeName())
+	}
+	if p, ok := lookupThemePayload(name); ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("theme registry has no palette for %q", name)
+}
+
+// findPluginRuntime locates a grove.nvim checkout: $GROVE_NVIM_RUNTIME, the
+// current directory or one of its parents, then lazy.nvim's and packpath's
+// install locations.
+func findPluginRuntime() (string, error) {
+	var candidates []string
+	if env := os.Getenv("GROVE_NVIM_RUNTIME"); env != "" {
+		candidates = append(candidates, env)
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		for dir := cwd; ; dir = filepath.Dir(dir) {
+			candidates = append(candidates, dir)
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+	}
+	if dataHome != "" {
+		candidates = append(candidates, filepath.Join(dataHome, "nvim", "lazy", "grove.nvim"))
+		packs, _ := filepath.Glob(filepath.Join(dataHome, "nvim", "site", "pack", "*", "*", "grove.nvim"))
+		candidates = append(candidates, packs...)
+	}
+
+	for _, dir := range candidates {
+		if isPluginRuntime(dir) {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("no grove.nvim checkout found; pass --runtime")
+}
+
+func isPluginRuntime(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "lua", "grove-nvim", "theme", "groups"))
+	return err == nil && info.IsDir()
+}
+
+// compiledVariant is one appearance's resolved highlight groups.
+type compiledVariant struct {
+	Appearance string
+	Palette    string
+	Groups     map[string]map[string]any
+	Terminal   []string // ANSI 0-15; empty when the palette has none
+}
+
+// evaluateThemeGroups runs compileGroupsScript for one palette.
+func evaluateThemeGroups(nvim, runtimeDir string, p *themePalette) (compiledVariant, error) {
+	tmp, err := os.MkdirTemp("", "grove-theme-compile-")
+	if err != nil {
+		return compiledVariant{}, err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	script := filepath.Join(tmp, "compile.lua")
+	input := filepath.Join(tmp, "palette.json")
+	if err := os.WriteFile(script, []byte(compileGroupsScript), 0o600); err != nil {
+		return compiledVariant{}, err
+	}
+	if err := os.WriteFile(input, p.raw, 0o600); err != nil {
+		return compiledVariant{}, err
+	}
+
+	var stderr bytes.Buffer
+	// #nosec G204 -- nvim from PATH running our own script
+	c := exec.Command(nvim, "--clean", "-l", script, runtimeDir, input)
+	c.Stderr = &stderr
+	stdout, err := c.Output()
+	if err != nil {
+		return compiledVariant{}, fmt.Errorf("evaluating %s highlight groups failed: %w: %s", p.Appearance, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var raw struct {
+		Groups   map[string]json.RawMessage `json:"groups"`
+		Terminal map[string]string          `json:"terminal"`
+	}
+	if err := json.Unmarshal(stdout, &raw); err != nil {
+		return compiledVariant{}, fmt.Errorf("decode highlight groups: %w", err)
+	}
+	return compiledVariant{
+		Appearance: p.Appearance,
+		Palette:    p.Name,
+		Groups:     decodeHighlightGroups(raw.Groups),
+		Terminal:   terminalColors(raw.Terminal),
+	}, nil
+}
+
+// decodeHighlightGroups normalizes group definitions as the engine applies
+// them: a string is a link, and an empty Lua table arrives as a JSON array.
+func decodeHighlightGroups(raw map[string]json.RawMessage) map[string]map[string]any {
+	groups := make(map[string]map[string]any, len(raw))
+	for name, data := range raw {
+		var link string
+		if json.Unmarshal(data, &link) == nil {
+			groups[name] = map[string]any{"link": link}
+			continue
+		}
+		hl := map[string]any{}
+		_ = json.Unmarshal(data, &hl) // "[]" (an empty table) leaves it empty
+		groups[name] = hl
+	}
+	return groups
+}
+
+func terminalColors(t map[string]string) []string {
+	if len(t) == 0 {
+		return nil
+	}
+	colors := make([]string, len(terminalSlots))
+	for i, slot := range terminalSlots {
+		colors[i] = t[slot]
+	}
+	return colors
+}
+
+// renderColorscheme writes the colorscheme file. Output is sorted so a
+// recompile of an unchanged theme is byte-identical.
+func renderColorscheme(name string, variants []compiledVariant) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- grove-%s: compiled from the grove %q theme by `grove-nvim theme compile`.\n", name, name)
+	b.WriteString("-- Self-contained; regenerate rather than edit.\n\n")
+
+	b.WriteString("local variants = {\n")
+	for _, v := range variants {
+		fmt.Fprintf(&b, "  %s = {\n", v.Appearance)
+		fmt.Fprintf(&b, "    palette = %s,\n", strconv.Quote(v.Palette))
+		b.WriteString("    groups = {\n")
+		names := make([]string, 0, len(v.Groups))
+		for group := range v.Groups {
+			names = append(names, group)
+		}
+		sort.Strings(names)
+		for _, group := range names {
+			fmt.Fprintf(&b, "      %s = %s,\n", luaKey(group), luaValue(v.Groups[group]))
+		}
+		b.WriteString("    },\n")
+		b.WriteString("    terminal = {")
+		for i, c := range v.Terminal {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(" " + strconv.Quote(c))
+		}
+		if len(v.Terminal) > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString("},\n")
+		b.WriteString("  },\n")
+	}
+	b.WriteString("}\n\n")
+
+	if len(variants) == 1 {
+		fmt.Fprintf(&b, "vim.o.background = %q\n", variants[0].Appearance)
+		fmt.Fprintf(&b, "local v = variants.%s\n", variants[0].Appearance)
+	} else {
+		b.WriteString("local v = variants[vim.o.background] or variants.dark or variants.light\n")
+	}
+	b.WriteString(`
+if vim.g.colors_name then
+  vim.cmd("hi clear")
+end
+vim.o.termguicolors = true
+`)
+	fmt.Fprintf(&b, "vim.g.colors_name = %q\n", "grove-"+name)
+	b.WriteString(`
+for group, hl in pairs(v.groups) do
+  vim.api.nvim_set_hl(0, group, hl)
+end
+for i, color in ipairs(v.terminal) do
+  vim.g["terminal_color_" .. (i - 1)] = color
+end
+`)
+	return b.String()
+}
+
+var luaIdentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func luaKey(k string) string {
+	if luaIdentRe.MatchString(k) {
+		return k
+	}
+	return "[" + strconv.Quote(k) + "]"
+}
+
+// luaValue renders a decoded JSON value as a Lua literal, keys sorted.
+func luaValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any:
+		if len(v) == 0 {
+			return "{}"
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = luaKey(k) + " = " + luaValue(v[k])
+		}
+		return "{ " + strings.Join(parts, ", ") + " }"
+	case []any:
+		if len(v) == 0 {
+			return "{}"
+		}
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = luaValue(e)
+		}
+		return "{ " + strings.Join(parts, ", ") + " }"
+	default:
+		return "nil"
+	}
+}
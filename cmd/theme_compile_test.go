@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeHighlightGroups(t *testing.T) {
+	groups := decodeHighlightGroups(map[string]json.RawMessage{
+		"Normal":      json.RawMessage(`{"fg":"#ffffff","bg":"#000000"}`),
+		"Comment":     json.RawMessage(`{"fg":"#777777","italic":true}`),
+		"@variable":   json.RawMessage(`"Identifier"`),
+		"GroveStatus": json.RawMessage(`[]`),
+	})
+	assert.Equal(t, map[string]any{"link": "Identifier"}, groups["@variable"])
+	assert.Equal(t, true, groups["Comment"]["italic"])
+	assert.Empty(t, groups["GroveStatus"], "an empty Lua table clears the group")
+}
+
+func TestRenderColorscheme(t *testing.T) {
+	v := compiledVariant{
+		Appearance: "dark",
+		Palette:    "kanagawa-wave",
+		Groups: map[string]map[string]any{
+			"Normal":    {"fg": "#dcd7ba", "bg": "#1f1f28"},
+			"@variable": {"link": "Identifier"},
+			"LineNr":    {"fg": "#54546d", "ctermfg": float64(243)},
+		},
+		Terminal: []string{"#000000", "#ff0000"},
+	}
+	out := renderColorscheme("kanagawa", []compiledVariant{v})
+
+	assert.Contains(t, out, `vim.g.colors_name = "grove-kanagawa"`)
+	assert.Contains(t, out, `vim.o.background = "dark"`, "a single appearance pins 'background'")
+	assert.Contains(t, out, `      ["@variable"] = { link = "Identifier" },`)
+	assert.Contains(t, out, `      LineNr = { ctermfg = 243, fg = "#54546d" },`)
+	assert.Contains(t, out, `terminal = { "#000000", "#ff0000" },`)
+	assert.Less(t, strings.Index(out, "@variable"), strings.Index(out, "LineNr"), "groups are sorted")
+
+	light := v
+	light.Appearance = "light"
+	both := renderColorscheme("kanagawa", []compiledVariant{v, light})
+	assert.NotContains(t, both, "vim.o.background =")
+	assert.Contains(t, both, "variants[vim.o.background]")
+	assert.Equal(t, both, renderColorscheme("kanagawa", []compiledVariant{v, light}), "output is deterministic")
+}
+
+func TestThemePayloadAppearances(t *testing.T) {
+	var p themePayload
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"solo","dark":{"name":"solo","appearance":"dark","bg":"#000000","extra_role":"#123456"}}`), &p))
+	assert.Contains(t, string(p.Dark.raw), "extra_role", "unmirrored roles survive for the Lua side")
+
+	got, err := p.appearances("")
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	_, err = p.appearances("light")
+	assert.Error(t, err)
+	_, err = p.appearances("dim")
+	assert.Error(t, err)
+}
+
+func TestFindPluginRuntime(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "lua", "grove-nvim", "theme", "groups"), 0o750))
+	t.Setenv("GROVE_NVIM_RUNTIME", root)
+
+	got, err := findPluginRuntime()
+	require.NoError(t, err)
+	assert.Equal(t, root, got)
+}
+
+func TestThemePayloadForFlagRejectsUnknownName(t *testing.T) {
+	_, err := themePayloadForFlag("no-such-theme")
+	assert.ErrorContains(t, err, `"no-such-theme"`, "an explicit name never falls back to the default theme")
+}
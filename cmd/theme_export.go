@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// themeExporter renders one palette in a tool's config format.
+type themeExporter func(name string, p *themePalette) string
+
+// themeExporters is keyed by the --format value.
+var themeExporters = map[string]themeExporter{
+	"kitty":     exportKitty,
+	"alacritty": exportAlacritty,
+	"wezterm":   exportWezterm,
+	"ghostty":   exportGhostty,
+	"tmux":      exportTmux,
+	"fzf":       exportFzf,
+	"bat":       exportTmTheme,
+	"delta":     exportDelta,
+}
+
+func themeExportFormats() []string {
+	formats := make([]string, 0, len(themeExporters))
+	for f := range themeExporters {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+func newThemeExportCmd() *cobra.Command {
+	var (
+		name       string
+		appearance string
+		format     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a grove theme for terminals and tools",
+		Long: `Prints the theme's palette in another tool's config format, so terminals,
+pagers and diffs match the editor:
+
+  kitty      kitty.conf color settings (include it from kitty.conf)
+  alacritty  TOML [colors] tables
+  wezterm    TOML color scheme for the colors/ directory
+  ghostty    theme file for the themes/ directory
+  tmux       status, pane border and message styles
+  fzf        a --color option for FZF_DEFAULT_OPTS
+  bat        a .tmTheme (add it with 'bat cache --build')
+  delta      a [delta] gitconfig section using the bat theme
+
+The dark appearance is exported unless --appearance picks light (or the
+theme only has light).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			export, ok := themeExporters[format]
+			if !ok {
+				return fmt.Errorf("unknown format %q (want one of: %s)", format, strings.Join(themeExportFormats(), ", "))
+			}
+			payload, err := themePayloadForFlag(name)
+			if err != nil {
+				return err
+			}
+			if payload.Mode == "ansi" {
+				return fmt.Errorf("theme %q passes terminal colors through and has no palette to export", payload.Name)
+			}
+			palettes, err := payload.appearances(appearance)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprint(os.Stdout, export(payload.Name, palettes[0].resolved()))
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Theme to export (default: the resolved current theme)")
+	cmd.Flags().StringVar(&appearance, "appearance", "", "Appearance to export: dark or light")
+	cmd.Flags().StringVar(&format, "format", "", "Output format: "+strings.Join(themeExportFormats(), ", "))
+	_ = cmd.MarkFlagRequired("format")
+
+	return cmd
+}
+
+func exportHeader(comment, name string, p *themePalette) string {
+	return fmt.Sprintf("%s grove %s (%s), exported by `grove-nvim theme export`\n", comment, p.Name, name)
+}
+
+func exportKitty(name string, p *themePalette) string {
+	var b strings.Builder
+	b.WriteString(exportHeader("#", name, p))
+	fmt.Fprintf(&b, "foreground %s\n", p.Fg)
+	fmt.Fprintf(&b, "background %s\n", p.Bg)
+	fmt.Fprintf(&b, "selection_foreground %s\n", p.Fg)
+	fmt.Fprintf(&b, "selection_background %s\n", p.BgVisual)
+	fmt.Fprintf(&b, "cursor %s\n", p.Fg)
+	fmt.Fprintf(&b, "cursor_text_color %s\n", p.Bg)
+	fmt.Fprintf(&b, "url_color %s\n", p.Blue)
+	fmt.Fprintf(&b, "active_border_color %s\n", p.Blue)
+	fmt.Fprintf(&b, "inactive_border_color %s\n", p.Border)
+	fmt.Fprintf(&b, "active_tab_foreground %s\n", p.Fg)
+	fmt.Fprintf(&b, "active_tab_background %s\n", p.BgHighlight)
+	fmt.Fprintf(&b, "inactive_tab_foreground %s\n", p.Comment)
+	fmt.Fprintf(&b, "inactive_tab_background %s\n", p.BgDark)
+	for i, c := range p.ansiColors() {
+		fmt.Fprintf(&b, "color%d %s\n", i, c)
+	}
+	return b.String()
+}
+
+func exportAlacritty(name string, p *themePalette) string {
+	ansi := p.ansiColors()
+	var b strings.Builder
+	b.WriteString(exportHeader("#", name, p))
+	fmt.Fprintf(&b, "\n[colors.primary]\nbackground = %q\nforeground = %q\n", p.Bg, p.Fg)
+	fmt.Fprintf(&b, "\n[colors.cursor]\ntext = %q\ncursor = %q\n", p.Bg, p.Fg)
+	fmt.Fprintf(&b, "\n[colors.selection]\ntext = %q\nbackground = %q\n", p.Fg, p.BgVisual)
+	for i, table := range []string{"normal", "bright"} {
+		fmt.Fprintf(&b, "\n[colors.%s]\n", table)
+		for j, slot := range terminalSlots[:8] {
+			fmt.Fprintf(&b, "%s = %q\n", slot, ansi[i*8+j])
+		}
+	}
+	return b.String()
+}
+
+func exportWezterm(name string, p *themePalette) string {
+	ansi := p.ansiColors()
+	quoted := func(colors []string) string {
+		q := make([]string, len(colors))
+		for i, c := range colors {
+			q[i] = fmt.Sprintf("%q", c)
+		}
+		return strings.Join(q, ", ")
+	}
+	var b strings.Builder
+	b.WriteString(exportHeader("#", name, p))
+	b.WriteString("\n[colors]\n")
+	fmt.Fprintf(&b, "foreground = %q\n", p.Fg)
+	fmt.Fprintf(&b, "background = %q\n", p.Bg)
+	fmt.Fprintf(&b, "cursor_bg = %q\n", p.Fg)
+	fmt.Fprintf(&b, "cursor_fg = %q\n", p.Bg)
+	fmt.Fprintf(&b, "cursor_border = %q\n", p.Fg)
+	fmt.Fprintf(&b, "selection_fg = %q\n", p.Fg)
+	fmt.Fprintf(&b, "selection_bg = %q\n", p.BgVisual)
+	fmt.Fprintf(&b, "split = %q\n", p.Border)
+	fmt.Fprintf(&b, "ansi = [%s]\n", quoted(ansi[:8]))
+	fmt.Fprintf(&b, "brights = [%s]\n", quoted(ansi[8:]))
+	fmt.Fprintf(&b, "\n[metadata]\nname = %q\n", "grove-"+p.Name)
+	return b.String()
+}
+
+func exportGhostty(name string, p *themePalette) string {
+	var b strings.Builder
+	b.WriteString(exportHeader("#", name, p))
+	fmt.Fprintf(&b, "background = %s\n", p.Bg)
+	fmt.Fprintf(&b, "foreground = %s\n", p.Fg)
+	fmt.Fprintf(&b, "cursor-color = %s\n", p.Fg)
+	fmt.Fprintf(&b, "cursor-text = %s\n", p.Bg)
+	fmt.Fprintf(&b, "selection-background = %s\n", p.BgVisual)
+	fmt.Fprintf(&b, "selection-foreground = %s\n", p.Fg)
+	for i, c := range p.ansiColors() {
+		fmt.Fprintf(&b, "palette = %d=%s\n", i, c)
+	}
+	return b.String()
+}
+
+// exportTmux styles the status line like the editor's status bar: the
+// statusline background, muted labels, and the blue accent for the current
+// window.
+func exportTmux(name string, p *themePalette) string {
+	var b strings.Builder
+	b.WriteString(exportHeader("#", name, p))
+	fmt.Fprintf(&b, "set -g status-style \"fg=%s,bg=%s\"\n", p.FgDark, p.BgDark)
+	fmt.Fprintf(&b, "set -g status-left-style \"fg=%s,bg=%s,bold\"\n", p.Blue, p.BgDark)
+	fmt.Fprintf(&b, "set -g status-right-style \"fg=%s,bg=%s\"\n", p.Comment, p.BgDark)
+	fmt.Fprintf(&b, "set -g window-status-style \"fg=%s,bg=%s\"\n", p.Comment, p.BgDark)
+	fmt.Fprintf(&b, "set -g window-status-current-style \"fg=%s,bg=%s,bold\"\n", p.Fg, p.BgHighlight)
+	fmt.Fprintf(&b, "set -g window-status-activity-style \"fg=%s,bg=%s\"\n", p.Diagnostics.Warning, p.BgDark)
+	fmt.Fprintf(&b, "set -g pane-border-style \"fg=%s\"\n", p.Border)
+	fmt.Fprintf(&b, "set -g pane-active-border-style \"fg=%s\"\n", p.Blue)
+	fmt.Fprintf(&b, "set -g message-style \"fg=%s,bg=%s\"\n", p.Fg, p.BgHighlight)
+	fmt.Fprintf(&b, "set -g message-command-style \"fg=%s,bg=%s\"\n", p.Fg, p.BgHighlight)
+	fmt.Fprintf(&b, "set -g mode-style \"fg=%s,bg=%s\"\n", p.Fg, p.BgVisual)
+	return b.String()
+}
+
+func exportFzf(name string, p *themePalette) string {
+	pairs := []string{
+		"fg:" + p.Fg,
+		"bg:" + p.Bg,
+		"hl:" + p.Orange,
+		"fg+:" + p.Fg,
+		"bg+:" + p.BgHighlight,
+		"hl+:" + p.Orange,
+		"info:" + p.Comment,
+		"border:" + p.Border,
+		"prompt:" + p.Blue,
+		"pointer:" + p.Magenta,
+		"marker:" + p.Green,
+		"spinner:" + p.Purple,
+		"header:" + p.Comment,
+		"gutter:" + p.Bg,
+	}
+	return exportHeader("#", name, p) + "--color=" + strings.Join(pairs, ",") + "\n"
+}
+
+// exportDelta configures delta's diff colors with the same blends the
+// editor uses for DiffAdd/DiffDelete, on top of the exported bat theme.
+func exportDelta(name string, p *themePalette) string {
+	var b strings.Builder
+	b.WriteString(exportHeader("#", name, p))
+	b.WriteString("[delta]\n")
+	fmt.Fprintf(&b, "\tsyntax-theme = grove-%s\n", p.Name)
+	fmt.Fprintf(&b, "\tminus-style = syntax \"%s\"\n", blendHex(p.Git.Delete, 0.2, p.Bg))
+	fmt.Fprintf(&b, "\tminus-emph-style = syntax \"%s\"\n", blendHex(p.Git.Delete, 0.5, p.Bg))
+	fmt.Fprintf(&b, "\tplus-style = syntax \"%s\"\n", blendHex(p.Git.Add, 0.2, p.Bg))
+	fmt.Fprintf(&b, "\tplus-emph-style = syntax \"%s\"\n", blendHex(p.Git.Add, 0.5, p.Bg))
+	fmt.Fprintf(&b, "\tline-numbers-minus-style = \"%s\"\n", p.Git.Delete)
+	fmt.Fprintf(&b, "\tline-numbers-plus-style = \"%s\"\n", p.Git.Add)
+	fmt.Fprintf(&b, "\tline-numbers-zero-style = \"%s\"\n", p.FgGutter)
+	fmt.Fprintf(&b, "\tfile-style = \"%s\" bold\n", p.Blue)
+	fmt.Fprintf(&b, "\thunk-header-style = \"%s\"\n", p.Comment)
+	return b.String()
+}
+
+// tmScopes maps TextMate scopes to palette roles following the editor's
+// base highlight groups (String green, Function blue, Keyword purple, ...).
+func tmScopes(p *themePalette) [][3]string {
+	return [][3]string{
+		{"Comment", "comment", p.Comment},
+		{"String", "string", p.Green},
+		{"Number", "constant.numeric", p.Orange},
+		{"Constant", "constant, support.constant, variable.other.constant", p.Orange},
+		{"Keyword", "keyword, storage.modifier", p.Purple},
+		{"Storage", "storage.type", p.Blue},
+		{"Function", "entity.name.function, support.function", p.Blue},
+		{"Type", "entity.name.type, entity.name.class, support.type, support.class", p.Blue},
+		{"Variable", "variable.parameter, variable.other", p.Magenta},
+		{"Operator", "keyword.operator, punctuation.accessor", p.Cyan},
+		{"Tag", "entity.name.tag, entity.other.attribute-name", p.Magenta},
+		{"Punctuation", "punctuation", p.FgDark},
+		{"Heading", "markup.heading", p.Blue},
+		{"Inserted", "markup.inserted", p.Git.Add},
+		{"Deleted", "markup.deleted", p.Git.Delete},
+		{"Changed", "markup.changed", p.Git.Change},
+		{"Invalid", "invalid", p.Diagnostics.Error},
+	}
+}
+
+// exportTmTheme writes a TextMate theme, which bat and delta both read.
+func exportTmTheme(name string, p *themePalette) string {
+	esc := html.EscapeString
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	fmt.Fprintf(&b, "<!-- grove %s (%s), exported by `grove-nvim theme export` -->\n", esc(p.Name), esc(name))
+	b.WriteString("<plist version=\"1.0\">\n<dict>\n")
+	fmt.Fprintf(&b, "  <key>name</key>\n  <string>grove-%s</string>\n", esc(p.Name))
+	b.WriteString("  <key>settings</key>\n  <array>\n")
+
+	b.WriteString("    <dict>\n      <key>settings</key>\n      <dict>\n")
+	for _, kv := range [][2]string{
+		{"background", p.Bg},
+		{"foreground", p.Fg},
+		{"caret", p.Fg},
+		{"selection", p.BgVisual},
+		{"lineHighlight", p.BgHighlight},
+		{"gutterForeground", p.FgGutter},
+	} {
+		fmt.Fprintf(&b, "        <key>%s</key>\n        <string>%s</string>\n", kv[0], esc(kv[1]))
+	}
+	b.WriteString("      </dict>\n    </dict>\n")
+
+	for _, s := range tmScopes(p) {
+		b.WriteString("    <dict>\n")
+		fmt.Fprintf(&b, "      <key>name</key>\n      <string>%s</string>\n", s[0])
+		fmt.Fprintf(&b, "      <key>scope</key>\n      <string>%s</string>\n", s[1])
+		fmt.Fprintf(&b, "      <key>settings</key>\n      <dict>\n        <key>foreground</key>\n        <string>%s</string>\n      </dict>\n", esc(s[2]))
+		b.WriteString("    </dict>\n")
+	}
+	b.WriteString("  </array>\n</dict>\n</plist>\n")
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func samplePalette() *themePalette {
+	p := &themePalette{
+		Name: "sample-dark", Appearance: "dark",
+		Bg: "#1f1f28", Fg: "#dcd7ba", Comment: "#727169", FgGutter: "#54546d",
+		Red: "#c34043", Orange: "#ffa066", Yellow: "#c0a36e", Green: "#76946a",
+		Cyan: "#6a9589", Blue: "#7e9cd8", Purple: "#957fb8", Magenta: "#d27e99",
+	}
+	return p.resolved()
+}
+
+func TestThemePaletteResolved(t *testing.T) {
+	p := samplePalette()
+	assert.Equal(t, "#76946a", p.Git.Add, "git.add falls back to green like colors.setup")
+	assert.Equal(t, "#c34043", p.Diagnostics.Error)
+	assert.Equal(t, "#1f1f28", p.BgDark)
+
+	ansi := p.ansiColors()
+	require.Len(t, ansi, 16)
+	assert.Equal(t, "#c34043", ansi[1], "no terminal slots: accents fill in")
+	assert.Equal(t, "#c34043", ansi[9])
+
+	p.Terminal = map[string]string{"red_bright": "#e82424"}
+	assert.Equal(t, "#e82424", p.ansiColors()[9])
+}
+
+func TestBlendHex(t *testing.T) {
+	assert.Equal(t, "#000000", blendHex("#ffffff", 0, "#000000"))
+	assert.Equal(t, "#ffffff", blendHex("#ffffff", 1, "#000000"))
+	assert.Equal(t, "#808080", blendHex("#ffffff", 0.5, "#000000"), "rounds like util.blend")
+	assert.Equal(t, "nope", blendHex("nope", 0.5, "#000000"))
+}
+
+func TestThemeExporters(t *testing.T) {
+	p := samplePalette()
+	cases := map[string][]string{
+		"kitty":     {"background #1f1f28", "color1 #c34043"},
+		"alacritty": {"[colors.primary]", "[colors.bright]", `red = "#c34043"`},
+		"wezterm":   {`background = "#1f1f28"`, `ansi = ["#1f1f28", "#c34043"`, `name = "grove-sample-dark"`},
+		"ghostty":   {"background = #1f1f28", "palette = 1=#c34043"},
+		"tmux":      {`set -g status-style "fg=#dcd7ba,bg=#1f1f28"`},
+		"fzf":       {"--color=fg:#dcd7ba,bg:#1f1f28,"},
+		"delta":     {"syntax-theme = grove-sample-dark", "plus-style = syntax"},
+		"bat":       {"<string>grove-sample-dark</string>", "<string>markup.inserted</string>"},
+	}
+	require.ElementsMatch(t, themeExportFormats(), keysOf(cases), "every format is covered")
+	for format, want := range cases {
+		out := themeExporters[format]("sample", p)
+		for _, w := range want {
+			assert.Contains(t, out, w, format)
+		}
+	}
+
+	var plist struct{ XMLName xml.Name }
+	require.NoError(t, xml.NewDecoder(strings.NewReader(themeExporters["bat"]("sample", p))).Decode(&plist))
+	assert.Equal(t, "plist", plist.XMLName.Local)
+}
+
+func keysOf(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -12,6 +12,22 @@ vim.opt_local.isfname:append('{')
 -- Enable virtual text for per-rule statistics.
 require('grove-nvim.virtual_text').setup()
 
+-- Report rules lint findings as diagnostics when enabled.
+if require('grove-nvim.config').options.rules.lint then
+  require('grove-nvim.rules').setup_lint()
+end
+
+-- Canonicalize the rules file on save when enabled.
+if require('grove-nvim.config').options.rules.format_on_save then
+  vim.api.nvim_create_autocmd('BufWritePre', {
+    buffer = 0,
+    group = vim.api.nvim_create_augroup('GroveRulesFormat' .. vim.api.nvim_get_current_buf(), { clear = true }),
+    callback = function(args)
+      require('grove-nvim.rules').format_buffer(args.buf)
+    end,
+  })
+end
+
 -- Keymap for previewing files resolved by the rule under the cursor.
 vim.keymap.set('n', '<leader>f?', function()
   require('grove-nvim.grove').preview_rule_files()
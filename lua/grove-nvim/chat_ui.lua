@@ -121,6 +121,54 @@ local function update(bufnr)
 	end
 end
 
+--- Returns the 1-based turn containing line `lnum`, numbered as `grove-nvim
+--- chat` numbers them: the opening prompt (if any) is turn 1, then every
+--- directive that opens a user turn or carries a response id starts the next.
+--- @param bufnr number|nil
+--- @param lnum number|nil 1-based line, defaults to the cursor line
+--- @return number|nil turn, nil when the line is in the frontmatter
+function M.turn_at_line(bufnr, lnum)
+	bufnr = bufnr or api.nvim_get_current_buf()
+	lnum = lnum or api.nvim_win_get_cursor(0)[1]
+	local lines = api.nvim_buf_get_lines(bufnr, 0, -1, false)
+
+	local body_start = 1
+	if lines[1] == "---" then
+		for i = 2, #lines do
+			if lines[i] == "---" then
+				body_start = i + 1
+				break
+			end
+		end
+	end
+	if lnum < body_start then
+		return nil
+	end
+
+	local turn = 0
+	local opening = false
+	for i = body_start, #lines do
+		local json_str = lines[i]:match("^%s*<!%-%- grove: (.*) %-%->%s*$")
+		local ok, data = false, nil
+		if json_str then
+			ok, data = pcall(vim.json.decode, json_str)
+		end
+		if ok and type(data) == "table" and (data.template ~= nil or (type(data.id) == "string" and data.id ~= "")) then
+			if i > lnum then
+				break
+			end
+			turn = turn + 1
+		elseif turn == 0 and not opening and lines[i]:match("%S") then
+			opening = true
+			turn = 1
+		end
+		if i == lnum and turn == 0 then
+			return 1
+		end
+	end
+	return math.max(turn, 1)
+end
+
 --- Sets up autocommands and highlighting for the current buffer.
 function M.setup(bufnr)
 	bufnr = bufnr or api.nvim_get_current_buf()
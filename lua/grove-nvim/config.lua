@@ -18,6 +18,7 @@ M.options = {
     theme = {
       enable = false,          -- Opt-in.
       live_updates = true,     -- Re-apply when the daemon broadcasts a theme change.
+      watch_config = false,    -- Also watch grove config files directly (live updates without groved).
       transparent = false,     -- Don't paint the main background.
       terminal_colors = true,  -- Set vim.g.terminal_color_* from the palette.
       styles = {
@@ -63,6 +64,12 @@ M.options = {
       },
     },
   },
+  rules = {
+    -- Run `grove-nvim rules fmt` on groverules buffers before writing them.
+    format_on_save = false,
+    -- Show `grove-nvim rules lint` findings as diagnostics while editing.
+    lint = true,
+  },
   test_runner = {
     -- %s will be replaced with the scenario name under the cursor
     command_template = "tend run --debug-session %s",
@@ -108,6 +108,72 @@ function M.get_skills(callback)
   end)
 end
 
+-- Get the job frontmatter and chat directive schema from `grove-nvim schema
+-- chat --json`. It only changes with the binary, so it is cached for the
+-- session like the skill list.
+M._chat_schema_cache = nil
+function M.get_chat_schema(callback)
+  if M._chat_schema_cache then
+    callback(M._chat_schema_cache)
+    return
+  end
+
+  local grove_nvim_path = utils.get_grove_nvim_binary()
+  if not grove_nvim_path then
+    callback(nil)
+    return
+  end
+
+  utils.run_command({ grove_nvim_path, 'schema', 'chat', '--json' }, function(stdout, stderr, exit_code)
+    if exit_code ~= 0 or stdout == "" then
+      callback(nil)
+      return
+    end
+    local ok, schema = pcall(vim.json.decode, stdout)
+    if not ok or type(schema) ~= 'table' then
+      callback(nil)
+      return
+    end
+    M._chat_schema_cache = schema
+    callback(schema)
+  end)
+end
+
+-- Describe a catalog entry for completion details, e.g.
+-- "anthropic · 200k ctx · 64k out · tools, vision · $3/$15 per MTok".
+function M.model_detail(model)
+  local parts = { model.provider or "unknown" }
+  local function k(n)
+    if not n or n == 0 then
+      return nil
+    end
+    if n >= 1000000 then
+      return string.format("%gM", math.floor(n / 100000) / 10)
+    end
+    return string.format("%dk", math.floor(n / 1000))
+  end
+  if k(model.contextWindow) then
+    table.insert(parts, k(model.contextWindow) .. " ctx")
+  end
+  if k(model.maxOutput) then
+    table.insert(parts, k(model.maxOutput) .. " out")
+  end
+  local caps = {}
+  if model.supportsTools then
+    table.insert(caps, "tools")
+  end
+  if model.supportsVision then
+    table.insert(caps, "vision")
+  end
+  if #caps > 0 then
+    table.insert(parts, table.concat(caps, ", "))
+  end
+  if model.pricing then
+    table.insert(parts, string.format("$%g/$%g per MTok", model.pricing.inputPerMTok, model.pricing.outputPerMTok))
+  end
+  return table.concat(parts, " · ")
+end
+
 -- Get available models
 function M.get_models(callback)
   local grove_nvim_path = vim.fn.exepath('grove-nvim')
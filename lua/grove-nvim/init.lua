@@ -137,6 +137,24 @@ function M.setup(opts)
     desc = "grove: re-point the daemon state stream at the new cwd",
   })
 
+  -- Keep the status bar's spend current for chat jobs as they are opened
+  -- and edited; completed responses refresh it from the provider. Entering
+  -- a chat whose usage is already known reuses it.
+  vim.api.nvim_create_autocmd({ "BufEnter", "BufWritePost" }, {
+    group = vim.api.nvim_create_augroup("GroveNvimChatUsage", { clear = true }),
+    pattern = "*.md",
+    callback = function(args)
+      local path = vim.api.nvim_buf_get_name(args.buf)
+      if args.event == "BufEnter" and provider.state.usage[path] then
+        return
+      end
+      if provider.get_current_job() then
+        provider.refresh_usage(path)
+      end
+    end,
+    desc = "grove: recompute token usage and spend for the chat",
+  })
+
   -- Set up spatial navigation keymaps (Ctrl+h/j/k/l) for grove terminal
   -- host pane traversal (groveterm and tuimux/treemux). Works as normal
   -- wincmd navigation outside a grove host.
@@ -206,34 +224,49 @@ function M.setup(opts)
   end
 end
 
---- Opens a floating terminal and runs the `grove-nvim chat` command for the current buffer.
---- @param args table|nil The arguments object from `nvim_create_user_command`.
---- args.args can contain "silent", "vertical", "horizontal", "fullscreen", "float".
-function M.chat_run(args)
-  args = args or {}
-  local opts = {
-    silent = false,
-    layout = 'float', -- New default layout: floating window
-  }
-
-  -- Parse string arguments into the opts table
-  if args.args and args.args ~= '' then
-    for arg in string.gmatch(args.args, "%S+") do
-      if arg == 'silent' then
-        opts.silent = true
-      elseif arg == 'vertical' or arg == 'horizontal' or arg == 'fullscreen' or arg == 'float' then
-        opts.layout = arg
-      end
-    end
-  end
-
-  local bufnr = vim.api.nvim_get_current_buf()
+local job_ns = vim.api.nvim_create_namespace("grove_job_validate")
+
+--- Checks the frontmatter of a job buffer with `grove-nvim job validate` and
+--- shows the findings as diagnostics. Validates the file on disk, so callers
+--- save first. Runs in the background: validation lists templates and skills
+--- through flow and the skills tool, which is too slow to wait on.
+--- @param bufnr number|nil Buffer to validate, the current one by default.
+--- @param callback function|nil Called with the number of errors and the
+--- diagnostics; 0 and none when the binary is unavailable or the check could
+--- not run.
+function M.job_validate(bufnr, callback)
+  bufnr = bufnr or vim.api.nvim_get_current_buf()
+  callback = callback or function() end
+  local utils = require('grove-nvim.utils')
   local buf_path = vim.api.nvim_buf_get_name(bufnr)
-  if buf_path == '' or buf_path == nil then
-    vim.notify("Grove: No file name for the current buffer.", vim.log.levels.ERROR)
+  local grove_nvim_path = utils.get_grove_nvim_binary()
+  if buf_path == '' or not grove_nvim_path then
+    callback(0, {})
     return
   end
 
+  utils.run_command({ grove_nvim_path, 'job', 'validate', buf_path }, function(stdout, _, code)
+    vim.schedule(function()
+      local ok, diags = pcall(vim.json.decode, stdout)
+      if code ~= 0 or not ok or type(diags) ~= 'table' then
+        callback(0, {})
+        return
+      end
+      local items, errors = utils.to_vim_diagnostics(diags)
+      if vim.api.nvim_buf_is_valid(bufnr) then
+        vim.diagnostic.set(job_ns, bufnr, items)
+      end
+      callback(errors, items)
+    end)
+  end)
+end
+
+-- Validation codes that stop :GroveChatRun from submitting.
+local blocking_job_codes = { yaml = true, type = true, ['missing-dependency'] = true }
+
+--- Marks the chat as running and submits it with `grove-nvim chat`, in the
+--- background or in a terminal depending on opts.
+local function submit_chat(bufnr, buf_path, grove_nvim_path, opts)
   -- Enable chat UI if not already enabled
   local chat_ui = require("grove-nvim.chat_ui")
   if not vim.b[bufnr].grove_chat_ui_enabled then
@@ -277,15 +310,17 @@ function M.chat_run(args)
   end
 
   -- Save the file before running
-  vim.cmd('silent write')
-
-  -- Find the grove-nvim binary using XDG paths
-  local utils = require('grove-nvim.utils')
-  local grove_nvim_path = utils.get_grove_nvim_binary()
-  if not grove_nvim_path then
-    vim.notify("Grove: grove-nvim not found. Check that it's installed in " .. utils.get_grove_bin_dir(), vim.log.levels.ERROR)
-    return
+  vim.api.nvim_buf_call(bufnr, function()
+    vim.cmd('silent write')
+  end)
+
+  -- --force submits even when the prompt is estimated to exceed the
+  -- model's context window.
+  local chat_cmd = { grove_nvim_path, 'chat', buf_path }
+  if opts.force then
+    table.insert(chat_cmd, '--force')
   end
+  local chat_shell_cmd = table.concat(vim.tbl_map(vim.fn.shellescape, chat_cmd), ' ')
 
   if opts.silent then
     -- Kill any existing job
@@ -293,14 +328,15 @@ function M.chat_run(args)
       vim.fn.jobstop(running_job)
     end
 
-    -- Collect stderr for error reporting
+    -- Collect stdout and stderr for error reporting
+    local stdout_output = {}
     local stderr_output = {}
 
     -- Run in background via daemon (fire-and-forget submission).
     -- The daemon handles execution asynchronously — the process exits immediately
     -- after submitting the job. The "running" directive stays in the buffer until
     -- the daemon completes the job and flow writes the response.
-    running_job = vim.fn.jobstart({grove_nvim_path, 'chat', buf_path}, {
+    running_job = vim.fn.jobstart(chat_cmd, {
       on_exit = function(_, exit_code)
         vim.cmd('silent! redrawstatus')
 
@@ -332,6 +368,19 @@ function M.chat_run(args)
               end
             end
 
+            -- An oversized prompt is refused with a JSON breakdown on stdout.
+            local budget_ok, budget = pcall(vim.json.decode, table.concat(stdout_output, ""))
+            if budget_ok and type(budget) == "table" and budget.error == "context_window_exceeded" then
+              local msg = { "Grove: " .. budget.message .. ". Largest contributors:" }
+              for i, c in ipairs(budget.contributors or {}) do
+                if i > 5 then break end
+                table.insert(msg, string.format("  %7d  %s", c.tokens, vim.fn.fnamemodify(c.path, ':~:.')))
+              end
+              table.insert(msg, "Run :GroveChatRun silent force to submit anyway.")
+              vim.notify(table.concat(msg, "\n"), vim.log.levels.ERROR)
+              return
+            end
+
             -- Show error with stderr output if available
             local error_msg = "Grove: Daemon submission failed (exit " .. exit_code .. ")"
             local stderr_text = table.concat(stderr_output, "")
@@ -344,6 +393,11 @@ function M.chat_run(args)
         end)
         running_job = nil
       end,
+      on_stdout = function(_, data)
+        if data then
+          table.insert(stdout_output, table.concat(data, "\n"))
+        end
+      end,
       on_stderr = function(_, data)
         -- Collect stderr output for error reporting
         if data then
@@ -357,7 +411,7 @@ function M.chat_run(args)
     })
   else
     -- Store the original buffer to refresh it later
-    local orig_buf = vim.api.nvim_get_current_buf()
+    local orig_buf = bufnr
 
     -- Open chat in a terminal with specified layout
     if opts.layout == 'float' then
@@ -386,7 +440,7 @@ function M.chat_run(args)
       vim.wo[win].relativenumber = false
       vim.wo[win].signcolumn = 'no'
 
-      vim.fn.termopen(grove_nvim_path .. ' chat ' .. vim.fn.shellescape(buf_path), {
+      vim.fn.termopen(chat_shell_cmd, {
         on_exit = function()
           vim.schedule(function()
             if vim.api.nvim_win_is_valid(win) then
@@ -410,7 +464,7 @@ function M.chat_run(args)
       vim.cmd('startinsert')
     elseif opts.layout == 'fullscreen' then
       vim.cmd('tabnew')
-      vim.fn.termopen(grove_nvim_path .. ' chat ' .. vim.fn.shellescape(buf_path), {
+      vim.fn.termopen(chat_shell_cmd, {
         on_exit = function()
           vim.schedule(function()
             -- Refresh the original buffer
@@ -425,7 +479,7 @@ function M.chat_run(args)
       vim.cmd('startinsert')
     elseif opts.layout == 'horizontal' then
       vim.cmd('new')
-      vim.fn.termopen(grove_nvim_path .. ' chat ' .. vim.fn.shellescape(buf_path), {
+      vim.fn.termopen(chat_shell_cmd, {
         on_exit = function()
           vim.schedule(function()
             -- Refresh the original buffer
@@ -440,7 +494,7 @@ function M.chat_run(args)
       vim.cmd('startinsert')
     else -- 'vertical'
       vim.cmd('vnew')
-      vim.fn.termopen(grove_nvim_path .. ' chat ' .. vim.fn.shellescape(buf_path), {
+      vim.fn.termopen(chat_shell_cmd, {
         on_exit = function()
           vim.schedule(function()
             -- Refresh the original buffer
@@ -457,6 +511,331 @@ function M.chat_run(args)
   end
 end
 
+--- Opens a floating terminal and runs the `grove-nvim chat` command for the current buffer.
+--- @param args table|nil The arguments object from `nvim_create_user_command`.
+--- args.args can contain "silent", "force" (submit past the context window
+--- guard), "novalidate" (skip the frontmatter check), "vertical",
+--- "horizontal", "fullscreen", "float".
+function M.chat_run(args)
+  args = args or {}
+  local opts = {
+    silent = false,
+    layout = 'float', -- New default layout: floating window
+  }
+
+  -- Parse string arguments into the opts table
+  if args.args and args.args ~= '' then
+    for arg in string.gmatch(args.args, "%S+") do
+      if arg == 'silent' then
+        opts.silent = true
+      elseif arg == 'force' then
+        opts.force = true
+      elseif arg == 'novalidate' then
+        opts.novalidate = true
+      elseif arg == 'vertical' or arg == 'horizontal' or arg == 'fullscreen' or arg == 'float' then
+        opts.layout = arg
+      end
+    end
+  end
+
+  local bufnr = vim.api.nvim_get_current_buf()
+  local buf_path = vim.api.nvim_buf_get_name(bufnr)
+  if buf_path == '' or buf_path == nil then
+    vim.notify("Grove: No file name for the current buffer.", vim.log.levels.ERROR)
+    return
+  end
+
+  -- Find the grove-nvim binary using XDG paths
+  local utils = require('grove-nvim.utils')
+  local grove_nvim_path = utils.get_grove_nvim_binary()
+  if not grove_nvim_path then
+    vim.notify("Grove: grove-nvim not found. Check that it's installed in " .. utils.get_grove_bin_dir(), vim.log.levels.ERROR)
+    return
+  end
+
+  -- Refuse to submit a job flow would reject: unparseable frontmatter, a
+  -- value of the wrong type or a missing dependency. Unknown names only
+  -- warn, since the lists they are checked against can lag. novalidate
+  -- skips the check; force only overrides the context window guard in
+  -- `grove-nvim chat`.
+  if opts.novalidate then
+    submit_chat(bufnr, buf_path, grove_nvim_path, opts)
+    return
+  end
+  if vim.bo[bufnr].modified then
+    vim.cmd('silent write')
+  end
+  M.job_validate(bufnr, function(_, items)
+    local blocking = 0
+    for _, d in ipairs(items) do
+      if d.severity == vim.diagnostic.severity.ERROR and blocking_job_codes[d.code] then
+        blocking = blocking + 1
+      end
+    end
+    if blocking > 0 then
+      vim.notify("Grove: Not submitting, the job frontmatter has errors. Use :GroveChatRun novalidate to submit anyway.", vim.log.levels.ERROR)
+      return
+    end
+    if vim.api.nvim_buf_is_valid(bufnr) then
+      submit_chat(bufnr, buf_path, grove_nvim_path, opts)
+    end
+  end)
+end
+
+--- Forks the current chat into a new job in the same plan and opens it.
+--- @param args table|nil The arguments object from `nvim_create_user_command`.
+--- args.args is "[turn] [name]"; the turn defaults to the one under the cursor.
+function M.chat_fork(args)
+  args = args or {}
+  local bufnr = vim.api.nvim_get_current_buf()
+  local buf_path = vim.api.nvim_buf_get_name(bufnr)
+  if buf_path == '' then
+    vim.notify("Grove: No file name for the current buffer.", vim.log.levels.ERROR)
+    return
+  end
+
+  local turn, name = (args.args or ''):match("^%s*(%d*)%s*(.-)%s*$")
+  turn = tonumber(turn) or require("grove-nvim.chat_ui").turn_at_line(bufnr)
+  if not turn then
+    vim.notify("Grove: Place the cursor on a chat turn or pass a turn number.", vim.log.levels.ERROR)
+    return
+  end
+
+  local utils = require('grove-nvim.utils')
+  local grove_nvim_path = utils.get_grove_nvim_binary()
+  if not grove_nvim_path then
+    vim.notify("Grove: grove-nvim not found. Check that it's installed in " .. utils.get_grove_bin_dir(), vim.log.levels.ERROR)
+    return
+  end
+
+  if vim.bo[bufnr].modified then
+    vim.cmd('silent write')
+  end
+
+  local cmd = { grove_nvim_path, 'chat', 'fork', buf_path, '--at-turn', tostring(turn), '--json' }
+  if name and name ~= '' then
+    vim.list_extend(cmd, { '--name', name })
+  end
+  local output = vim.fn.system(cmd)
+  if vim.v.shell_error ~= 0 then
+    vim.notify("Grove: Fork failed: " .. vim.trim(output), vim.log.levels.ERROR)
+    return
+  end
+  local ok, result = pcall(vim.json.decode, output)
+  if not ok or type(result) ~= "table" or not result.file then
+    vim.notify("Grove: Unexpected fork output: " .. vim.trim(output), vim.log.levels.ERROR)
+    return
+  end
+  vim.cmd('edit ' .. vim.fn.fnameescape(result.file))
+  vim.notify(string.format("Grove: Forked at turn %d into %s", result.atTurn, vim.fn.fnamemodify(result.file, ':t')))
+end
+
+--- Replaces the last LLM response of the current chat and resubmits it in
+--- the background. The previous response is archived next to the job.
+--- @param args table|nil The arguments object from `nvim_create_user_command`.
+--- args.args is an optional model to answer with.
+function M.chat_regenerate(args)
+  args = args or {}
+  local bufnr = vim.api.nvim_get_current_buf()
+  local buf_path = vim.api.nvim_buf_get_name(bufnr)
+  if buf_path == '' then
+    vim.notify("Grove: No file name for the current buffer.", vim.log.levels.ERROR)
+    return
+  end
+
+  local utils = require('grove-nvim.utils')
+  local grove_nvim_path = utils.get_grove_nvim_binary()
+  if not grove_nvim_path then
+    vim.notify("Grove: grove-nvim not found. Check that it's installed in " .. utils.get_grove_bin_dir(), vim.log.levels.ERROR)
+    return
+  end
+
+  if vim.bo[bufnr].modified then
+    vim.cmd('silent write')
+  end
+
+  local cmd = { grove_nvim_path, 'chat', 'regenerate', buf_path }
+  local model = vim.trim(args.args or '')
+  if model ~= '' then
+    vim.list_extend(cmd, { '--model', model })
+  end
+
+  local stderr_output = {}
+  vim.fn.jobstart(cmd, {
+    on_stderr = function(_, data)
+      for _, line in ipairs(data or {}) do
+        if line ~= '' then table.insert(stderr_output, line) end
+      end
+    end,
+    on_exit = function(_, exit_code)
+      vim.schedule(function()
+        -- The file was rewritten on disk (and restored on failure).
+        if vim.api.nvim_buf_is_valid(bufnr) then
+          vim.api.nvim_buf_call(bufnr, function()
+            vim.cmd('silent! checktime')
+          end)
+        end
+        if exit_code == 0 then
+          vim.api.nvim_echo({{"Grove: Regenerating response" .. (model ~= '' and (" with " .. model) or ""), "Normal"}}, false, {})
+        else
+          vim.notify("Grove: Regenerate failed\n" .. table.concat(stderr_output, "\n"), vim.log.levels.ERROR)
+        end
+      end)
+    end,
+  })
+end
+
+--- Exports the current chat as a shareable transcript.
+--- @param args table|nil The arguments object from `nvim_create_user_command`.
+--- args.args is "[html|json|md] [path]"; the path defaults to the chat's name
+--- with the format's extension in the current directory.
+function M.chat_export(args)
+  args = args or {}
+  local buf_path = vim.api.nvim_buf_get_name(0)
+  if buf_path == '' then
+    vim.notify("Grove: No file name for the current buffer.", vim.log.levels.ERROR)
+    return
+  end
+
+  local format, out = (args.args or ''):match("^%s*(%S*)%s*(.-)%s*$")
+  if format == '' then format = 'md' end
+  if out == '' then
+    local ext = format == 'md' and 'export.md' or format
+    out = vim.fn.fnamemodify(buf_path, ':t:r') .. '.' .. ext
+  end
+
+  local utils = require('grove-nvim.utils')
+  local grove_nvim_path = utils.get_grove_nvim_binary()
+  if not grove_nvim_path then
+    vim.notify("Grove: grove-nvim not found. Check that it's installed in " .. utils.get_grove_bin_dir(), vim.log.levels.ERROR)
+    return
+  end
+
+  if vim.bo.modified then
+    vim.cmd('silent write')
+  end
+  local output = vim.fn.system({ grove_nvim_path, 'chat', 'export', buf_path, '--format', format, '--out', out })
+  if vim.v.shell_error ~= 0 then
+    vim.notify("Grove: Export failed: " .. vim.trim(output), vim.log.levels.ERROR)
+    return
+  end
+  vim.notify("Grove: Exported chat to " .. vim.fn.fnamemodify(out, ':~:.'))
+end
+
+--- Compacts the current chat: older turns are replaced by a summary in the
+--- background, and the buffer is reloaded once the file is rewritten.
+--- @param args table|nil The arguments object from `nvim_create_user_command`.
+--- args.args is "[keep-last] [dry]"; "dry" only reports the savings.
+function M.chat_compact(args)
+  args = args or {}
+  local bufnr = vim.api.nvim_get_current_buf()
+  local buf_path = vim.api.nvim_buf_get_name(bufnr)
+  if buf_path == '' then
+    vim.notify("Grove: No file name for the current buffer.", vim.log.levels.ERROR)
+    return
+  end
+
+  local utils = require('grove-nvim.utils')
+  local grove_nvim_path = utils.get_grove_nvim_binary()
+  if not grove_nvim_path then
+    vim.notify("Grove: grove-nvim not found. Check that it's installed in " .. utils.get_grove_bin_dir(), vim.log.levels.ERROR)
+    return
+  end
+
+  local cmd = { grove_nvim_path, 'chat', 'compact', buf_path }
+  local dry = false
+  for arg in string.gmatch(args.args or '', "%S+") do
+    if arg == 'dry' then
+      dry = true
+      table.insert(cmd, '--dry-run')
+    elseif tonumber(arg) then
+      vim.list_extend(cmd, { '--keep-last', arg })
+    end
+  end
+
+  if vim.bo[bufnr].modified then
+    vim.cmd('silent write')
+  end
+  if not dry then
+    vim.notify("Grove: Summarizing older turns…")
+  end
+
+  utils.run_command(cmd, function(stdout, stderr, exit_code)
+    vim.schedule(function()
+      if exit_code ~= 0 then
+        vim.notify("Grove: Compact failed: " .. vim.trim(stderr), vim.log.levels.ERROR)
+        return
+      end
+      if not dry and vim.api.nvim_buf_is_valid(bufnr) then
+        vim.api.nvim_buf_call(bufnr, function()
+          vim.cmd('silent! checktime')
+        end)
+      end
+      vim.notify("Grove: " .. vim.trim(stdout))
+    end)
+  end)
+end
+
+--- Extracts the code blocks of a chat response. The patch they amount to is
+--- shown in a scratch split, and the files are written once confirmed.
+--- @param args table|nil The arguments object from `nvim_create_user_command`.
+--- args.args is "[turn]"; the default is the last response.
+function M.chat_extract(args)
+  args = args or {}
+  local buf_path = vim.api.nvim_buf_get_name(0)
+  if buf_path == '' then
+    vim.notify("Grove: No file name for the current buffer.", vim.log.levels.ERROR)
+    return
+  end
+
+  local utils = require('grove-nvim.utils')
+  local grove_nvim_path = utils.get_grove_nvim_binary()
+  if not grove_nvim_path then
+    vim.notify("Grove: grove-nvim not found. Check that it's installed in " .. utils.get_grove_bin_dir(), vim.log.levels.ERROR)
+    return
+  end
+
+  local cmd = { grove_nvim_path, 'chat', 'extract', buf_path }
+  local turn = vim.trim(args.args or '')
+  if turn ~= '' then
+    vim.list_extend(cmd, { '--turn', turn })
+  end
+
+  if vim.bo.modified then
+    vim.cmd('silent write')
+  end
+  local patch = vim.fn.system(vim.list_extend(vim.deepcopy(cmd), { '--patch' }))
+  if vim.v.shell_error ~= 0 then
+    vim.notify("Grove: Extract failed: " .. vim.trim(patch), vim.log.levels.ERROR)
+    return
+  end
+  if vim.trim(patch) == '' then
+    vim.notify("Grove: No code blocks with a target file in that response.", vim.log.levels.WARN)
+    return
+  end
+
+  vim.cmd('botright new')
+  local patch_buf = vim.api.nvim_get_current_buf()
+  vim.bo[patch_buf].buftype = 'nofile'
+  vim.bo[patch_buf].bufhidden = 'wipe'
+  vim.bo[patch_buf].filetype = 'diff'
+  vim.api.nvim_buf_set_lines(patch_buf, 0, -1, false, vim.split(patch, "\n", { trimempty = true }))
+  vim.bo[patch_buf].modifiable = false
+  vim.cmd('redraw')
+
+  if vim.fn.confirm("Write these changes?", "&Yes\n&No", 2) ~= 1 then
+    return
+  end
+  local output = vim.fn.system(vim.list_extend(cmd, { '--write', '--yes' }))
+  if vim.v.shell_error ~= 0 then
+    vim.notify("Grove: Extract failed: " .. vim.trim(output), vim.log.levels.ERROR)
+    return
+  end
+  vim.api.nvim_buf_delete(patch_buf, { force = true })
+  vim.cmd('silent! checktime')
+  vim.notify("Grove: " .. vim.trim(output))
+end
+
 --- Get status for statusline integration
 --- @return string Status string, empty if not running
 function M.status()
@@ -117,6 +117,30 @@ local function find_grove_root(start_path)
   return nil
 end
 
+--- Returns the directory of the schemas generated by
+--- `grove-nvim schema grove-config`, grove.yml's and job frontmatter's,
+--- writing them when they are missing or older than the binary, so they work
+--- offline and match the installed core version.
+--- @return string|nil The schema directory, or nil when they cannot be generated
+function M.generated_schema_dir()
+  local bin = require('grove-nvim.utils').get_grove_nvim_binary()
+  if not bin then
+    return nil
+  end
+  local dir = vim.fn.stdpath('cache') .. '/grove-nvim/schemas'
+  local grove_schema = dir .. '/grove.schema.json'
+  local job_schema = dir .. '/job-frontmatter.schema.json'
+  local bin_time = vim.fn.getftime(bin)
+  if vim.fn.getftime(grove_schema) >= bin_time and vim.fn.getftime(job_schema) >= bin_time then
+    return dir
+  end
+  vim.fn.system({ bin, 'schema', 'grove-config', '--out', dir })
+  if vim.v.shell_error ~= 0 or vim.fn.filereadable(grove_schema) == 0 then
+    return nil
+  end
+  return dir
+end
+
 --- Get yamlls settings with Grove schema auto-detection
 --- @return table Settings table to use in yamlls setup
 function M.get_yamlls_config()
@@ -142,9 +166,21 @@ function M.get_yamlls_config()
     end
   end
 
-  -- Fallback to hosted schema
+  -- Fall back to a schema generated by the installed binary, and to the
+  -- hosted one only when that fails.
+  local generated = M.generated_schema_dir()
   if vim.tbl_isempty(schemas) then
-    schemas['https://www.grove-llm.dev/schemas/grove.schema.json'] = 'grove.yml'
+    if generated then
+      schemas[generated .. '/grove.schema.json'] = 'grove.yml'
+    else
+      schemas['https://www.grove-llm.dev/schemas/grove.schema.json'] = 'grove.yml'
+    end
+  end
+
+  -- Job files are the Markdown files of flow plans; their frontmatter is
+  -- checked against the schema `job validate` uses.
+  if generated then
+    schemas[generated .. '/job-frontmatter.schema.json'] = '**/plans/**/*.md'
   end
 
   return {
@@ -9,4 +9,168 @@ function M.browse()
 	utils.run_in_side_term_tui("nb tui", "Notebook Browser")
 end
 
+--- Creates a note with `grove-nvim nb new` and opens it in a split. With a
+--- range, the selected lines become the note's body, fenced with the
+--- buffer's filetype, and the buffer is recorded as its source.
+---@param opts? { title?: string, notebook?: string, range?: integer, line1?: integer, line2?: integer }
+function M.new(opts)
+	opts = opts or {}
+	local bin = utils.get_grove_nvim_binary()
+	if not bin then
+		vim.notify("Grove: grove-nvim binary not found.", vim.log.levels.ERROR)
+		return
+	end
+
+	local bufnr = vim.api.nvim_get_current_buf()
+	local body
+	if opts.range and opts.range > 0 then
+		local lines = vim.api.nvim_buf_get_lines(bufnr, opts.line1 - 1, opts.line2, false)
+		local ft = vim.bo[bufnr].filetype
+		if ft == "markdown" then
+			body = table.concat(lines, "\n")
+		else
+			body = "```" .. ft .. "\n" .. table.concat(lines, "\n") .. "\n```"
+		end
+	end
+
+	local function create(title)
+		if not title or vim.trim(title) == "" then
+			return
+		end
+		local cmd = { bin, "nb", "new", "--title", title, "--json" }
+		if opts.notebook then
+			vim.list_extend(cmd, { "--notebook", opts.notebook })
+		end
+		local source = vim.api.nvim_buf_get_name(bufnr)
+		if body then
+			table.insert(cmd, "--from-selection")
+			if source ~= "" then
+				vim.list_extend(cmd, { "--source", source })
+			end
+		end
+
+		local output = vim.fn.system(cmd, body or "")
+		local ok, note = pcall(vim.json.decode, output)
+		if vim.v.shell_error ~= 0 or not ok or type(note) ~= "table" then
+			vim.notify("Grove: Could not create note: " .. vim.trim(output), vim.log.levels.ERROR)
+			return
+		end
+		vim.cmd("split " .. vim.fn.fnameescape(note.path))
+		vim.notify("Grove: Created " .. note.alias)
+	end
+
+	if opts.title and opts.title ~= "" then
+		create(opts.title)
+	else
+		vim.ui.input({ prompt = "Note title: " }, create)
+	end
+end
+
+--- Runs `grove-nvim nb find` and hands the matching notes to on_notes.
+local function find_notes(query, on_notes)
+	local bin = utils.get_grove_nvim_binary()
+	if not bin then
+		vim.notify("Grove: grove-nvim binary not found.", vim.log.levels.ERROR)
+		return
+	end
+	utils.run_command({ bin, "nb", "find", "--json", "--", query }, function(stdout, stderr, exit_code)
+		vim.schedule(function()
+			if exit_code ~= 0 then
+				vim.notify("Grove: Note search failed: " .. vim.trim(stderr), vim.log.levels.ERROR)
+				return
+			end
+			local ok, notes = pcall(vim.json.decode, stdout)
+			if not ok or type(notes) ~= "table" or #notes == 0 then
+				vim.notify("Grove: No notes match '" .. query .. "'.", vim.log.levels.INFO)
+				return
+			end
+			on_notes(notes)
+		end)
+	end)
+end
+
+--- Shows notes in a picker (vim.ui.select without snacks.nvim).
+local function pick_note(title, notes, on_pick)
+	local has_snacks, snacks = pcall(require, "snacks")
+	if not (has_snacks and snacks.picker) then
+		vim.ui.select(notes, {
+			prompt = title,
+			format_item = function(n)
+				return n.title .. "  " .. n.alias
+			end,
+		}, function(choice)
+			if choice then
+				on_pick(choice)
+			end
+		end)
+		return
+	end
+
+	local items = {}
+	for _, n in ipairs(notes) do
+		table.insert(items, {
+			text = n.title .. "  " .. n.alias .. "  " .. (n.text or ""),
+			file = n.path,
+			pos = { n.line or 1, 0 },
+			note = n,
+		})
+	end
+	snacks.picker({
+		title = title,
+		items = items,
+		format = "text",
+		layout = utils.centered_dropdown(120, math.min(#items + 4, 30)),
+		confirm = function(picker, item)
+			picker:close()
+			if item then
+				on_pick(item.note)
+			end
+		end,
+	})
+end
+
+--- Finds notes containing every word of query and opens the chosen one.
+---@param query string
+function M.find(query)
+	find_notes(query, function(notes)
+		pick_note("Notes: " .. query, notes, function(note)
+			vim.cmd.edit(vim.fn.fnameescape(note.path))
+			vim.api.nvim_win_set_cursor(0, { note.line or 1, 0 })
+		end)
+	end)
+end
+
+--- Finds notes and inserts a reference to the chosen one at the cursor: its
+--- alias in chats and rules files, a Markdown link in other Markdown.
+---@param query string
+function M.link(query)
+	local bufnr = vim.api.nvim_get_current_buf()
+	local win = vim.api.nvim_get_current_win()
+	local buf_path = vim.api.nvim_buf_get_name(bufnr)
+	local lines = vim.api.nvim_buf_get_lines(bufnr, 0, 1, false)
+	local as_alias = vim.bo[bufnr].filetype ~= "markdown" or (lines[1] == "---" and buf_path:match("/plans/") ~= nil)
+
+	find_notes(query, function(notes)
+		pick_note("Link note: " .. query, notes, function(note)
+			local text = note.alias
+			if not as_alias then
+				local cmd = { utils.get_grove_nvim_binary(), "nb", "link", note.path, "--markdown" }
+				if buf_path ~= "" then
+					vim.list_extend(cmd, { "--from", buf_path })
+				end
+				local output = vim.fn.system(cmd)
+				if vim.v.shell_error ~= 0 then
+					vim.notify("Grove: Could not link note: " .. vim.trim(output), vim.log.levels.ERROR)
+					return
+				end
+				text = vim.trim(output)
+			end
+			if vim.api.nvim_win_is_valid(win) then
+				vim.api.nvim_set_current_win(win)
+				vim.api.nvim_put({ text }, "c", true, true)
+			end
+		end)
+	end)
+end
+
 return M
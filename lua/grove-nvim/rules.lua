@@ -394,4 +394,248 @@ function M.goto_file_from_rule()
   end)
 end
 
+--- Rewrites a groverules buffer into canonical form via `grove-nvim rules fmt`.
+--- Synchronous so it can run from BufWritePre; the buffer is left untouched
+--- when the formatter fails or changes nothing.
+---@param bufnr number|nil
+function M.format_buffer(bufnr)
+  bufnr = bufnr or vim.api.nvim_get_current_buf()
+  local bin = utils.get_grove_nvim_binary()
+  if not bin then
+    return
+  end
+
+  local lines = vim.api.nvim_buf_get_lines(bufnr, 0, -1, false)
+  local out = vim.fn.system({ bin, 'rules', 'fmt' }, table.concat(lines, '\n') .. '\n')
+  if vim.v.shell_error ~= 0 then
+    vim.notify("Grove: rules fmt failed: " .. out, vim.log.levels.WARN)
+    return
+  end
+
+  local formatted = vim.split(out, '\n', { plain = true })
+  if formatted[#formatted] == '' then
+    table.remove(formatted)
+  end
+  if vim.deep_equal(lines, formatted) then
+    return
+  end
+
+  local view = vim.fn.winsaveview()
+  vim.api.nvim_buf_set_lines(bufnr, 0, -1, false, formatted)
+  vim.fn.winrestview(view)
+end
+
+local verdict_labels = {
+  included = { "in context", "DiagnosticOk" },
+  excluded = { "excluded", "DiagnosticWarn" },
+  not_included = { "not in context", "DiagnosticError" },
+}
+
+--- Shows, in a floating window, which rules include or exclude a file.
+--- Without a path the current buffer's file is explained; in a groverules
+--- buffer that buffer is the rules file evaluated. <CR> on a rule jumps to it.
+---@param path string|nil
+function M.explain(path)
+  local bin = utils.get_grove_nvim_binary()
+  if not bin then
+    vim.notify("Grove: grove-nvim binary not found", vim.log.levels.ERROR)
+    return
+  end
+
+  local rules_file
+  if vim.bo.filetype == 'groverules' then
+    rules_file = vim.api.nvim_buf_get_name(0)
+    if not path or path == '' then
+      vim.notify("Grove: give the file to explain, e.g. :GroveRulesExplain cmd/root.go", vim.log.levels.WARN)
+      return
+    end
+  end
+  path = (path and path ~= '') and vim.fn.fnamemodify(path, ':p') or vim.api.nvim_buf_get_name(0)
+  if path == '' then
+    vim.notify("Grove: no file to explain", vim.log.levels.WARN)
+    return
+  end
+
+  local cmd = { bin, 'rules', 'explain', path }
+  if rules_file then
+    vim.list_extend(cmd, { '--rules-file', rules_file })
+  end
+
+  utils.run_command(cmd, function(stdout, stderr, exit_code)
+    vim.schedule(function()
+      if exit_code ~= 0 then
+        vim.notify("Grove: rules explain failed: " .. vim.trim(stderr), vim.log.levels.ERROR)
+        return
+      end
+      local ok, ex = pcall(vim.json.decode, stdout)
+      if not ok or type(ex) ~= 'table' then
+        vim.notify("Grove: could not parse rules explain output", vim.log.levels.ERROR)
+        return
+      end
+      M.show_explanation(ex)
+    end)
+  end)
+end
+
+--- Renders a `rules explain` result in a floating window.
+---@param ex table
+function M.show_explanation(ex)
+  local verdict = verdict_labels[ex.verdict] or { ex.verdict, "Normal" }
+  local lines = {
+    vim.fn.fnamemodify(ex.path, ':~:.'),
+    "Verdict: " .. verdict[1],
+    "Rules:   " .. vim.fn.fnamemodify(ex.rulesFile, ':~:.'),
+    "",
+  }
+  local highlights = { { 1, 9, -1, verdict[2] } }
+  local targets = {} -- buffer line -> rules file line
+
+  local function add_step(step, prefix)
+    table.insert(lines, string.format("%s %4d  %s", prefix, step.lineNumber, step.rule))
+    targets[#lines] = step.lineNumber
+  end
+
+  if #ex.steps == 0 then
+    table.insert(lines, "No rule matches this file.")
+  end
+  for _, step in ipairs(ex.steps) do
+    add_step(step, step.action == 'exclude' and '-' or '+')
+    table.insert(highlights, { #lines - 1, 0, 1, step.action == 'exclude' and "DiagnosticWarn" or "DiagnosticOk" })
+    local anchor = step.scope or ''
+    if step.root and step.root ~= '' then
+      anchor = anchor .. " at " .. vim.fn.fnamemodify(step.root, ':~:.')
+    end
+    if anchor ~= '' then
+      table.insert(lines, "         " .. anchor)
+      table.insert(highlights, { #lines - 1, 0, -1, "Comment" })
+    end
+  end
+
+  if ex.unevaluated and #ex.unevaluated > 0 then
+    table.insert(lines, "")
+    table.insert(lines, "Not evaluated (may also match):")
+    for _, step in ipairs(ex.unevaluated) do
+      add_step(step, '?')
+      table.insert(lines, "         " .. step.reason)
+      table.insert(highlights, { #lines - 1, 0, -1, "Comment" })
+    end
+  end
+
+  local width = 40
+  for _, l in ipairs(lines) do
+    width = math.max(width, vim.fn.strdisplaywidth(l) + 2)
+  end
+  width = math.min(width, math.floor(vim.o.columns * 0.8))
+  local height = math.min(#lines, math.floor(vim.o.lines * 0.6))
+
+  local buf = vim.api.nvim_create_buf(false, true)
+  vim.api.nvim_buf_set_lines(buf, 0, -1, false, lines)
+  vim.bo[buf].modifiable = false
+  vim.bo[buf].bufhidden = 'wipe'
+  local ns = vim.api.nvim_create_namespace('grove_rules_explain')
+  for _, h in ipairs(highlights) do
+    vim.api.nvim_buf_add_highlight(buf, ns, h[4], h[1], h[2], h[3])
+  end
+
+  local win = vim.api.nvim_open_win(buf, true, {
+    relative = 'editor',
+    width = width,
+    height = height,
+    row = math.floor((vim.o.lines - height) / 2),
+    col = math.floor((vim.o.columns - width) / 2),
+    style = 'minimal',
+    border = 'rounded',
+    title = ' Rules Explain ',
+    title_pos = 'center',
+  })
+
+  local close = function()
+    if vim.api.nvim_win_is_valid(win) then
+      vim.api.nvim_win_close(win, true)
+    end
+  end
+  vim.keymap.set('n', 'q', close, { buffer = buf, nowait = true })
+  vim.keymap.set('n', '<Esc>', close, { buffer = buf, nowait = true })
+  vim.keymap.set('n', '<CR>', function()
+    local target = targets[vim.api.nvim_win_get_cursor(win)[1]]
+    if not target then
+      return
+    end
+    close()
+    vim.cmd('edit ' .. vim.fn.fnameescape(ex.rulesFile))
+    vim.api.nvim_win_set_cursor(0, { target, 0 })
+  end, { buffer = buf, nowait = true })
+end
+
+local lint_ns = vim.api.nvim_create_namespace('grove_rules_lint')
+-- One debouncer per buffer, so editing one rules file does not swallow a
+-- pending lint of another.
+local debounced_lint = {}
+
+--- Publishes `grove-nvim rules lint` findings for a groverules buffer with
+--- vim.diagnostic. The buffer content is linted, not the file on disk.
+---@param bufnr number|nil
+function M.lint(bufnr)
+  bufnr = bufnr or vim.api.nvim_get_current_buf()
+  if not vim.api.nvim_buf_is_valid(bufnr) then
+    return
+  end
+  local bin = utils.get_grove_nvim_binary()
+  local buf_path = vim.api.nvim_buf_get_name(bufnr)
+  if not bin or buf_path == '' then
+    return
+  end
+
+  local lines = vim.api.nvim_buf_get_lines(bufnr, 0, -1, false)
+  local changedtick = vim.api.nvim_buf_get_changedtick(bufnr)
+  local cmd = { bin, 'rules', 'lint', '-', '--rules-file', buf_path }
+  utils.run_command(cmd, function(stdout, stderr, exit_code)
+    vim.schedule(function()
+      -- Drop results for content that has changed since.
+      if not vim.api.nvim_buf_is_valid(bufnr) or vim.api.nvim_buf_get_changedtick(bufnr) ~= changedtick then
+        return
+      end
+      local ok, diags = pcall(vim.json.decode, stdout)
+      if exit_code ~= 0 or not ok or type(diags) ~= 'table' then
+        vim.notify("Grove: rules lint failed: " .. vim.trim(stderr), vim.log.levels.DEBUG)
+        return
+      end
+      local items = utils.to_vim_diagnostics(diags)
+      vim.diagnostic.set(lint_ns, bufnr, items)
+    end)
+  end, table.concat(lines, '\n') .. '\n')
+end
+
+--- Lints a groverules buffer on write and, debounced, as it changes.
+---@param bufnr number|nil
+function M.setup_lint(bufnr)
+  bufnr = bufnr or vim.api.nvim_get_current_buf()
+  if not debounced_lint[bufnr] then
+    debounced_lint[bufnr] = utils.debounce(500, M.lint)
+  end
+  local group = vim.api.nvim_create_augroup('GroveRulesLint' .. bufnr, { clear = true })
+  vim.api.nvim_create_autocmd('BufWritePost', {
+    group = group,
+    buffer = bufnr,
+    callback = function(args)
+      M.lint(args.buf)
+    end,
+  })
+  vim.api.nvim_create_autocmd({ 'TextChanged', 'TextChangedI' }, {
+    group = group,
+    buffer = bufnr,
+    callback = function(args)
+      debounced_lint[args.buf](args.buf)
+    end,
+  })
+  vim.api.nvim_create_autocmd('BufWipeout', {
+    group = group,
+    buffer = bufnr,
+    callback = function(args)
+      debounced_lint[args.buf] = nil
+    end,
+  })
+  M.lint(bufnr)
+end
+
 return M
@@ -0,0 +1,88 @@
+-- lua/grove-nvim/search.lua
+-- Search across chats and notes with `grove-nvim search`.
+
+local M = {}
+local utils = require("grove-nvim.utils")
+
+local function hit_label(hit)
+	local where = hit.plan ~= nil and hit.plan ~= "" and hit.plan or vim.fn.fnamemodify(hit.file, ":h:t")
+	local name = hit.title ~= nil and hit.title ~= "" and hit.title or vim.fn.fnamemodify(hit.file, ":t:r")
+	return string.format("%s/%s  #%d %s  %s", where, name, hit.turn, hit.role, hit.text)
+end
+
+--- Sends hits to the quickfix list, for when snacks.nvim is not installed.
+local function to_quickfix(query, hits)
+	local items = {}
+	for _, hit in ipairs(hits) do
+		table.insert(items, { filename = hit.file, lnum = hit.line, col = hit.col, text = hit_label(hit) })
+	end
+	vim.fn.setqflist({}, " ", { title = "Grove search: " .. query, items = items })
+	vim.cmd("copen")
+end
+
+--- Searches chats and notes and opens the hits in a picker.
+---@param query string
+---@param opts? { workspace?: string, plan?: string, role?: "user"|"llm" }
+function M.search(query, opts)
+	opts = opts or {}
+	if query == nil or vim.trim(query) == "" then
+		vim.notify("Grove: Nothing to search for.", vim.log.levels.WARN)
+		return
+	end
+	local bin = utils.get_grove_nvim_binary()
+	if not bin then
+		vim.notify("Grove: grove-nvim binary not found.", vim.log.levels.ERROR)
+		return
+	end
+
+	local args = { bin, "search", "--json" }
+	for _, key in ipairs({ "workspace", "plan", "role" }) do
+		if opts[key] then
+			vim.list_extend(args, { "--" .. key, opts[key] })
+		end
+	end
+	vim.list_extend(args, { "--", query })
+
+	utils.run_command(args, function(stdout, stderr, exit_code)
+		vim.schedule(function()
+			if exit_code ~= 0 then
+				vim.notify("Grove: Search failed: " .. vim.trim(stderr), vim.log.levels.ERROR)
+				return
+			end
+			local ok, hits = pcall(vim.json.decode, stdout)
+			if not ok or type(hits) ~= "table" or #hits == 0 then
+				vim.notify("Grove: No chats or notes match '" .. query .. "'.", vim.log.levels.INFO)
+				return
+			end
+
+			local has_snacks, snacks = pcall(require, "snacks")
+			if not (has_snacks and snacks.picker) then
+				to_quickfix(query, hits)
+				return
+			end
+			local items = {}
+			for _, hit in ipairs(hits) do
+				table.insert(items, {
+					text = hit_label(hit),
+					file = hit.file,
+					pos = { hit.line, math.max(hit.col - 1, 0) },
+				})
+			end
+			snacks.picker({
+				title = "Grove search: " .. query,
+				items = items,
+				format = "text",
+				layout = utils.centered_dropdown(140, math.min(#items + 4, 30)),
+				confirm = function(picker, item)
+					picker:close()
+					if item then
+						vim.cmd.edit(vim.fn.fnameescape(item.file))
+						vim.api.nvim_win_set_cursor(0, { item.pos[1], item.pos[2] })
+					end
+				end,
+			})
+		end)
+	end)
+end
+
+return M
@@ -7,6 +7,12 @@ local source = {}
 
 local data = require('grove-nvim.data')
 
+-- Directive keys offered when the binary cannot provide its schema.
+local fallback_keys = {
+  { name = 'template', type = 'string' },
+  { name = 'model', type = 'string' },
+}
+
 function source.new(opts)
   local self = setmetatable({}, { __index = source })
   self.opts = opts or {}
@@ -38,12 +44,24 @@ function source:get_completions(ctx, callback)
 
   -- Case 1: Completing a key (after '{' or ',' with optional whitespace)
   if directive_match:match('[{,]%s*"?$') or directive_match:match('[{,]%s*$') then
-    local items = {
-      { label = 'template', insertText = '"template": "', kind = vim.lsp.protocol.CompletionItemKind.Property },
-      { label = 'model', insertText = '"model": "', kind = vim.lsp.protocol.CompletionItemKind.Property },
-      { label = 'id', insertText = '"id": "', kind = vim.lsp.protocol.CompletionItemKind.Property },
-    }
-    return callback({ items = items, is_incomplete_forward = true })
+    data.get_chat_schema(function(schema)
+      local keys = schema and schema.directive or fallback_keys
+      local items = {}
+      for _, key in ipairs(keys) do
+        -- Keys tools write (id, state, ...) stay out of the menu.
+        if not key.internal then
+          local insert = key.type == 'string' and ('"' .. key.name .. '": "') or ('"' .. key.name .. '": ')
+          table.insert(items, {
+            label = key.name,
+            insertText = insert,
+            detail = key.description,
+            kind = vim.lsp.protocol.CompletionItemKind.Property,
+          })
+        end
+      end
+      callback({ items = items, is_incomplete_forward = true })
+    end)
+    return
   end
 
   -- Case 2: Completing a value (after "key": ")
@@ -89,18 +107,32 @@ function source:get_completions(ctx, callback)
           table.insert(items, {
             label = id,
             insertText = id,
-            detail = "Provider: " .. (model.provider or "unknown"),
+            detail = data.model_detail(model),
             kind = vim.lsp.protocol.CompletionItemKind.EnumMember,
           })
         end
       end
       callback({ items = items })
     end)
-  elseif key_match == "id" then
-    -- For ID, we don't provide suggestions (user-defined)
-    callback({ items = {} })
   else
-    callback({ items = {} })
+    -- Keys with fixed values (e.g. state) complete from the schema; others,
+    -- like id, are free-form.
+    data.get_chat_schema(function(schema)
+      local items = {}
+      for _, key in ipairs(schema and schema.directive or {}) do
+        if key.name == key_match then
+          for _, value in ipairs(key.enum or {}) do
+            table.insert(items, {
+              label = value.value,
+              insertText = value.value,
+              detail = value.description,
+              kind = vim.lsp.protocol.CompletionItemKind.EnumMember,
+            })
+          end
+        end
+      end
+      callback({ items = items })
+    end)
   end
 end
 
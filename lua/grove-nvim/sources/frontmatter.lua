@@ -137,7 +137,7 @@ function source:get_completions(ctx, callback)
           table.insert(items, {
             label = id,
             insertText = id,
-            detail = "Provider: " .. (model.provider or "unknown"),
+            detail = data.model_detail(model),
             kind = vim.lsp.protocol.CompletionItemKind.EnumMember,
           })
         end
@@ -213,6 +213,58 @@ function source:get_completions(ctx, callback)
     end
   end
 
+  -- Case 6: Completing a top-level key, from the schema the binary serves.
+  -- Keys already set and keys tools write themselves are left out.
+  if current_line:match('^[%w_]*$') then
+    data.get_chat_schema(function(schema)
+      local present = {}
+      for _, l in ipairs(vim.api.nvim_buf_get_lines(bufnr, 1, -1, false)) do
+        if l == '---' then break end
+        local key = l:match('^([%w_]+)%s*:')
+        if key then present[key] = true end
+      end
+      local items = {}
+      for _, field in ipairs(schema and schema.frontmatter or {}) do
+        if not field.internal and not present[field.name] then
+          local insert = field.name .. ': '
+          if field.type == 'list' then
+            insert = field.name .. ':\n  - '
+          end
+          table.insert(items, {
+            label = field.name,
+            insertText = insert,
+            detail = field.description,
+            kind = vim.lsp.protocol.CompletionItemKind.Property,
+          })
+        end
+      end
+      callback({ items = items })
+    end)
+    return
+  end
+
+  -- Case 7: Completing fixed values (type, status, ...) from the schema.
+  local value_key = current_line:match('^([%w_]+):%s*[%w_]*$')
+  if value_key then
+    data.get_chat_schema(function(schema)
+      local items = {}
+      for _, field in ipairs(schema and schema.frontmatter or {}) do
+        if field.name == value_key then
+          for _, value in ipairs(field.enum or {}) do
+            table.insert(items, {
+              label = value.value,
+              insertText = value.value,
+              detail = value.description,
+              kind = vim.lsp.protocol.CompletionItemKind.EnumMember,
+            })
+          end
+        end
+      end
+      callback({ items = items })
+    end)
+    return
+  end
+
   -- No applicable completion
   callback({ items = {} })
 end
@@ -96,6 +96,11 @@ local function get_bar_content()
       job_part = job_part .. " 󰚩 " .. current_job_status.model
     end
 
+    local spend = provider.format_usage(provider.get_current_usage())
+    if spend then
+      job_part = job_part .. "  " .. spend.display
+    end
+
     table.insert(all_parts, job_part)
   end
 
@@ -228,6 +233,7 @@ local function do_refresh()
   local current_job = provider.get_current_job()
   local current_job_status = provider.format_job_status(current_job)
   local context_size = ws and provider.format_context_size(ws.cx_stats)
+  local spend = current_job_status and provider.format_usage(provider.get_current_usage())
 
   for line_idx, padded_content in ipairs(padded_content_lines) do
     local current_line_num = line_idx - 1
@@ -316,6 +322,14 @@ local function do_refresh()
       end
     end
 
+    -- Mute the plan-wide spend next to the chat's own
+    if spend and spend.plan then
+      local plan_start = vim.fn.stridx(padded_content, spend.plan)
+      if plan_start >= 0 then
+        vim.api.nvim_buf_add_highlight(state.buf, 0, "GroveStatusMuted", current_line_num, plan_start, plan_start + #spend.plan)
+      end
+    end
+
     -- Apply labels
     local labels = { "Plan:", "Job:", "Context:", "Git:" }
     for _, label in ipairs(labels) do
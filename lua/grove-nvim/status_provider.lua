@@ -6,6 +6,7 @@ M.state = {
   context_size = nil,
   rules_file = nil,
   theme = nil,      -- Last theme payload {name, family, mode, dark?, light?}
+  usage = {},       -- Map of chat file path -> `chat usage --plan --json` report
 }
 
 local stream_job_id = nil
@@ -190,6 +191,100 @@ function M.format_context_size(cx_stats)
   }
 end
 
+--- Format a dollar amount: cents precision, with "<$0.01" for dust.
+local function format_cost(cost)
+  if cost > 0 and cost < 0.01 then
+    return "<$0.01"
+  end
+  return string.format("$%.2f", cost)
+end
+
+--- Format a chat usage report into the spend shape used by the status bar.
+--- Returns nil until the chat has a priced response. Estimated totals are
+--- marked with "~".
+function M.format_usage(usage)
+  if not usage or not usage.total or not usage.turns or #usage.turns == 0 then return nil end
+  -- No turn has a known price: there is no spend to show, not $0.00.
+  if (usage.total.unpriced or 0) >= #usage.turns then return nil end
+
+  local function amount(total)
+    return (total.estimated and "~" or "") .. format_cost(total.cost or 0)
+  end
+
+  local display = amount(usage.total)
+  local plan = nil
+  if usage.plan and usage.plan.jobs and usage.plan.jobs > 1 then
+    plan = "(plan " .. amount(usage.plan.total) .. ")"
+    display = display .. " " .. plan
+  end
+  return { display = display, plan = plan }
+end
+
+--- Usage report for the current buffer, if it has been computed.
+function M.get_current_usage()
+  local path = vim.api.nvim_buf_get_name(0)
+  if path == "" then return nil end
+  return M.state.usage[path]
+end
+
+-- `chat usage --plan` reads every chat in the plan, so refreshes are
+-- throttled per plan directory: one run at a time, at most one per interval,
+-- with requests in between folded into a single trailing run.
+local USAGE_REFRESH_INTERVAL_MS = 5000
+local usage_refresh = {} -- plan dir -> { path, last, running, dirty, scheduled }
+
+--- Recompute token usage and spend for a chat file and its plan in the
+--- background.
+function M.refresh_usage(path)
+  if not path or path == "" then return end
+  local utils = require('grove-nvim.utils')
+  local grove_nvim_path = utils.get_grove_nvim_binary()
+  if not grove_nvim_path then return end
+
+  local plan_dir = vim.fn.fnamemodify(path, ':h')
+  local r = usage_refresh[plan_dir]
+  if not r then
+    r = { last = 0 }
+    usage_refresh[plan_dir] = r
+  end
+  r.path = path
+  if r.running then
+    r.dirty = true
+    return
+  end
+  if r.scheduled then return end
+  local wait = r.last + USAGE_REFRESH_INTERVAL_MS - vim.loop.now()
+  if wait > 0 then
+    r.scheduled = true
+    vim.defer_fn(function()
+      r.scheduled = false
+      M.refresh_usage(r.path)
+    end, wait)
+    return
+  end
+
+  r.running = true
+  local target = r.path
+  vim.fn.jobstart({ grove_nvim_path, 'chat', 'usage', target, '--plan', '--json' }, {
+    stdout_buffered = true,
+    on_stdout = function(_, data)
+      local ok, report = pcall(vim.json.decode, table.concat(data or {}, "\n"))
+      if ok and type(report) == "table" then
+        M.state.usage[target] = report
+        notify_update()
+      end
+    end,
+    on_exit = function()
+      r.running = false
+      r.last = vim.loop.now()
+      if r.dirty then
+        r.dirty = false
+        M.refresh_usage(r.path)
+      end
+    end,
+  })
+end
+
 --- Remove any running state directives from a buffer and save.
 local function clean_running_directives(bufnr)
   local lines = vim.api.nvim_buf_get_lines(bufnr, 0, -1, false)
@@ -228,6 +323,7 @@ local function reload_job_buffer(job)
             vim.api.nvim_buf_call(bufnr, function()
               vim.cmd('silent! write')
             end)
+            M.refresh_usage(buf_name)
           end
         end, 100)
         break
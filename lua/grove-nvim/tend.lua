@@ -5,35 +5,323 @@ local M = {}
 local utils = require("grove-nvim.utils")
 local config = require("grove-nvim.config")
 
---- Runs the tend test for the scenario name under the cursor.
-function M.run_test_under_cursor()
-	-- Use <cWORD> to correctly capture names with hyphens.
+--- Runs a scenario through the configured command template. When the
+--- grove-nvim binary is available the run goes through `tend exec`, which
+--- records it in the workspace history for `tend rerun`.
+---@param scenario_name string
+function M.run_scenario(scenario_name)
+	-- Retrieve the command template from the configuration.
+	local command_template = config.options.test_runner.command_template
+	local final_command = string.format(command_template, scenario_name)
+
+	local bin = utils.get_grove_nvim_binary()
+	if bin then
+		final_command = table.concat({
+			vim.fn.shellescape(bin), "tend", "exec",
+			"--template", vim.fn.shellescape(command_template),
+			vim.fn.shellescape(scenario_name),
+		}, " ")
+	end
+
+	vim.notify("Grove: Running test: " .. final_command, vim.log.levels.INFO)
+
+	-- Execute in a floating output window. `tend run --debug-session` is not
+	-- interactive; it prints setup information and exits. This utility will
+	-- display that output and wait for a keypress before closing.
+	utils.run_in_float_term_output(final_command)
+end
+
+--- Replays recorded runs with `grove-nvim tend rerun`.
+---@param mode "last"|"failed"
+function M.rerun(mode)
+	local bin = utils.get_grove_nvim_binary()
+	if not bin then
+		vim.notify("Grove: grove-nvim binary not found.", vim.log.levels.ERROR)
+		return
+	end
+	utils.run_in_float_term_output(table.concat({ vim.fn.shellescape(bin), "tend", "rerun", "--" .. mode }, " "))
+end
+
+--- Asks `grove-nvim tend locate` for the scenarios enclosing the cursor and
+--- passes them to callback. Passes nil when the buffer is not a saved Go
+--- file or nothing encloses the cursor, so callers can fall back to the word
+--- under the cursor.
+---@param callback fun(scenarios: table[]|nil)
+local function locate_under_cursor(callback)
+	local bufnr = vim.api.nvim_get_current_buf()
+	local file = vim.api.nvim_buf_get_name(bufnr)
+	-- locate parses the file on disk; unsaved edits would shift positions.
+	if vim.bo[bufnr].filetype ~= "go" or file == "" or vim.bo[bufnr].modified then
+		callback(nil)
+		return
+	end
+	local bin = utils.get_grove_nvim_binary()
+	if not bin then
+		callback(nil)
+		return
+	end
+
+	local cursor = vim.api.nvim_win_get_cursor(0)
+	local cmd = {
+		bin, "tend", "locate",
+		"--file", file,
+		"--line", tostring(cursor[1]),
+		"--col", tostring(cursor[2] + 1),
+	}
+	utils.run_command(cmd, function(stdout, _, exit_code)
+		if exit_code ~= 0 then
+			callback(nil)
+			return
+		end
+		local ok, result = pcall(vim.json.decode, stdout)
+		if not ok or type(result) ~= "table" or not result.scenarios or #result.scenarios == 0 then
+			callback(nil)
+			return
+		end
+		callback(result.scenarios)
+	end)
+end
+
+--- Resolves the scenario under the cursor and passes its name to callback.
+--- The cursor may be anywhere in a scenario constructor or in a step helper
+--- it uses; otherwise the word under the cursor is taken as the name.
+---@param callback fun(name: string)
+function M.with_scenario_under_cursor(callback)
+	-- Use <cWORD> to correctly capture names with hyphens. Read it now: the
+	-- cursor may have moved by the time locate answers.
 	local scenario_name = vim.fn.expand("<cWORD>")
 
-	if scenario_name == "" then
-		vim.notify("Grove: No scenario name under cursor.", vim.log.levels.WARN)
+	locate_under_cursor(function(scenarios)
+		if scenarios then
+			if #scenarios == 1 then
+				callback(scenarios[1].name)
+				return
+			end
+			-- A shared step helper belongs to several scenarios.
+			vim.ui.select(scenarios, {
+				prompt = "Run scenario:",
+				format_item = function(s)
+					return s.name
+				end,
+			}, function(choice)
+				if choice then
+					callback(choice.name)
+				end
+			end)
+			return
+		end
+
+		if scenario_name == "" then
+			vim.notify("Grove: No scenario name under cursor.", vim.log.levels.WARN)
+			return
+		end
+
+		-- Strip surrounding quotes and trailing punctuation (e.g., "name", or 'name',)
+		-- This handles Go string literals like Name: "scenario-name",
+		scenario_name = scenario_name:gsub('^["\']', ''):gsub('["\']%s*,?%s*$', '')
+
+		if scenario_name == "" then
+			vim.notify("Grove: No scenario name under cursor.", vim.log.levels.WARN)
+			return
+		end
+
+		callback(scenario_name)
+	end)
+end
+
+--- Runs the tend test for the scenario under the cursor.
+function M.run_test_under_cursor()
+	M.with_scenario_under_cursor(M.run_scenario)
+end
+
+local result_ns = vim.api.nvim_create_namespace("grove_tend_results")
+
+--- Clears pass signs left by earlier structured runs.
+function M.clear_results()
+	for _, buf in ipairs(vim.api.nvim_list_bufs()) do
+		if vim.api.nvim_buf_is_loaded(buf) then
+			vim.api.nvim_buf_clear_namespace(buf, result_ns, 0, -1)
+		end
+	end
+end
+
+--- Marks a passing scenario's definition with a sign.
+local function mark_passed(result)
+	if not result.file or not result.line then
 		return
 	end
+	local buf = vim.fn.bufnr(result.file)
+	if buf == -1 or not vim.api.nvim_buf_is_loaded(buf) then
+		return
+	end
+	vim.api.nvim_buf_set_extmark(buf, result_ns, result.line - 1, 0, {
+		sign_text = "✓",
+		sign_hl_group = "DiagnosticOk",
+		virt_text = { { "passed" .. (result.duration and (" in " .. result.duration) or ""), "DiagnosticOk" } },
+		virt_text_pos = "eol",
+	})
+end
 
-	-- Strip surrounding quotes and trailing punctuation (e.g., "name", or 'name',)
-	-- This handles Go string literals like Name: "scenario-name",
-	scenario_name = scenario_name:gsub('^["\']', ''):gsub('["\']%s*,?%s*$', '')
+--- Puts a failed run's steps into the quickfix list, failing steps as errors.
+local function show_failures(result)
+	local items = {}
+	for _, step in ipairs(result.steps or {}) do
+		if not step.success then
+			table.insert(items, {
+				filename = step.file or result.file,
+				lnum = step.line or result.line or 1,
+				col = step.col or 1,
+				type = "E",
+				text = step.name .. ": " .. (step.error or "failed"),
+			})
+		end
+	end
+	if #items == 0 then
+		table.insert(items, {
+			filename = result.file,
+			lnum = result.line or 1,
+			col = result.col or 1,
+			type = "E",
+			text = result.error or "scenario failed",
+		})
+	end
+	vim.fn.setqflist({}, " ", { title = "tend: " .. result.scenario, items = items })
+	vim.cmd("copen")
+end
 
-	if scenario_name == "" then
-		vim.notify("Grove: No scenario name under cursor.", vim.log.levels.WARN)
+--- Runs a scenario through `grove-nvim tend run --json` in the background.
+--- Failures land in the quickfix list; a pass leaves a sign on the
+--- scenario definition.
+---@param scenario_name string
+function M.run_structured(scenario_name)
+	local bin = utils.get_grove_nvim_binary()
+	if not bin then
 		return
 	end
+	vim.notify("Grove: Running " .. scenario_name .. "...", vim.log.levels.INFO)
+	utils.run_command({ bin, "tend", "run", scenario_name, "--json" }, function(stdout, stderr, _)
+		vim.schedule(function()
+			local ok, result = pcall(vim.json.decode, stdout)
+			if not ok or type(result) ~= "table" then
+				vim.notify("Grove: tend run failed: " .. stderr, vim.log.levels.ERROR)
+				return
+			end
+			M.clear_results()
+			if result.success then
+				mark_passed(result)
+				vim.notify("Grove: " .. scenario_name .. " passed", vim.log.levels.INFO)
+			else
+				show_failures(result)
+				vim.notify("Grove: " .. scenario_name .. " failed", vim.log.levels.ERROR)
+			end
+		end)
+	end)
+end
 
-	-- Retrieve the command template from the configuration.
-	local command_template = config.options.test_runner.command_template
-	local final_command = string.format(command_template, scenario_name)
+--- Opens a picker over the package's scenarios (`grove-nvim tend list`).
+--- Typing `#tag` narrows by tag; <CR> runs the scenario and <C-e> opens its
+--- definition.
+---@param opts? { dir?: string, tags?: string[] }
+function M.pick_scenario(opts)
+	opts = opts or {}
+	local bin = utils.get_grove_nvim_binary()
+	if not bin then
+		return
+	end
 
-	vim.notify("Grove: Running test: " .. final_command, vim.log.levels.INFO)
+	local args = { bin, "tend", "list", "--json" }
+	if opts.dir then
+		table.insert(args, opts.dir)
+	end
+	for _, tag in ipairs(opts.tags or {}) do
+		vim.list_extend(args, { "--tag", tag })
+	end
 
-	-- Execute in a floating output window. `tend run --debug-session` is not
-	-- interactive; it prints setup information and exits. This utility will
-	-- display that output and wait for a keypress before closing.
-	utils.run_in_float_term_output(final_command)
+	utils.run_command(args, function(stdout, stderr, exit_code)
+		vim.schedule(function()
+			if exit_code ~= 0 then
+				vim.notify("Grove: tend list failed: " .. stderr, vim.log.levels.ERROR)
+				return
+			end
+			local ok, scenarios = pcall(vim.json.decode, stdout)
+			if not ok or type(scenarios) ~= "table" or #scenarios == 0 then
+				vim.notify("Grove: No tend scenarios found.", vim.log.levels.INFO)
+				return
+			end
+			M._show_scenario_picker(scenarios)
+		end)
+	end)
+end
+
+local function open_definition(scenario)
+	vim.cmd.edit(vim.fn.fnameescape(scenario.file))
+	vim.api.nvim_win_set_cursor(0, { scenario.line, math.max(scenario.col - 1, 0) })
+end
+
+function M._show_scenario_picker(scenarios)
+	local has_snacks, snacks = pcall(require, "snacks")
+	if not (has_snacks and snacks.picker) then
+		vim.ui.select(scenarios, {
+			prompt = "Run scenario:",
+			format_item = function(s)
+				return s.name .. "  " .. table.concat(s.tags, ",")
+			end,
+		}, function(choice)
+			if choice then
+				M.run_scenario(choice.name)
+			end
+		end)
+		return
+	end
+
+	local items = {}
+	for _, s in ipairs(scenarios) do
+		local tags = {}
+		for _, tag in ipairs(s.tags) do
+			table.insert(tags, "#" .. tag)
+		end
+		local text = s.name .. "  " .. table.concat(tags, " ")
+		if not s.registered then
+			text = text .. "  (unregistered)"
+		end
+		if s.description and s.description ~= "" then
+			text = text .. "  " .. s.description
+		end
+		table.insert(items, {
+			text = text,
+			file = s.file,
+			pos = { s.line, math.max(s.col - 1, 0) },
+			scenario = s,
+		})
+	end
+
+	snacks.picker({
+		title = "Tend scenarios (<CR> run, <C-e> open)",
+		items = items,
+		format = "text",
+		layout = utils.centered_dropdown(120, math.min(#items + 4, 30)),
+		confirm = function(picker, item)
+			picker:close()
+			if item then
+				M.run_scenario(item.scenario.name)
+			end
+		end,
+		actions = {
+			open_definition = function(picker, item)
+				picker:close()
+				if item then
+					open_definition(item.scenario)
+				end
+			end,
+		},
+		win = {
+			input = {
+				keys = {
+					["<C-e>"] = { "open_definition", mode = { "n", "i" } },
+				},
+			},
+		},
+	})
 end
 
 return M
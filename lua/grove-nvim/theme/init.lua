@@ -201,6 +201,43 @@ function M.apply(payload)
   return true
 end
 
+--- Run `grove-nvim internal theme --watch`, which re-resolves the theme when
+--- the grove config files change, and feed its payloads through the same
+--- GroveThemeChanged path as the daemon's theme_changed events.
+function M.watch_config()
+  if M.state.watch_job then
+    return
+  end
+  local bin = require("grove-nvim.utils").get_grove_nvim_binary()
+  if not bin then
+    return
+  end
+
+  local partial = ""
+  M.state.watch_job = vim.fn.jobstart({ bin, "internal", "theme", "--watch" }, {
+    stdout_buffered = false,
+    on_stdout = function(_, data)
+      if not data then
+        return
+      end
+      data[1] = partial .. data[1]
+      partial = table.remove(data)
+      for _, line in ipairs(data) do
+        local ok, payload = pcall(vim.json.decode, line)
+        if ok and type(payload) == "table" and payload.name then
+          require("grove-nvim.status_provider").state.theme = payload
+          vim.schedule(function()
+            vim.api.nvim_exec_autocmds("User", { pattern = "GroveThemeChanged", modeline = false })
+          end)
+        end
+      end
+    end,
+    on_exit = function()
+      M.state.watch_job = nil
+    end,
+  })
+end
+
 --- Engine setup: initial synchronous apply plus live-update subscriptions.
 --- No-op unless config.ui.theme.enable is set.
 function M.setup()
@@ -264,6 +301,10 @@ function M.setup()
     end,
   })
 
+  if opts().watch_config and opts().live_updates ~= false and not M.pinned() then
+    M.watch_config()
+  end
+
   -- Re-pick the dark/light slot when 'background' changes.
   vim.api.nvim_create_autocmd("OptionSet", {
     group = aug,
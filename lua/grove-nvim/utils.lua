@@ -67,6 +67,30 @@ function M.debounce(ms, fn)
   end
 end
 
+-- Converts the JSON diagnostics grove-nvim prints (1-based lines and
+-- columns, exclusive end column) into vim.diagnostic items. Also returns the
+-- number of errors.
+function M.to_vim_diagnostics(diags)
+  local severities = { error = vim.diagnostic.severity.ERROR, warning = vim.diagnostic.severity.WARN }
+  local items, errors = {}, 0
+  for _, d in ipairs(diags) do
+    if d.severity == 'error' then
+      errors = errors + 1
+    end
+    table.insert(items, {
+      lnum = d.line - 1,
+      col = d.column - 1,
+      end_lnum = d.endLine - 1,
+      end_col = d.endColumn - 1,
+      severity = severities[d.severity] or vim.diagnostic.severity.INFO,
+      source = 'grove',
+      code = d.code,
+      message = d.message,
+    })
+  end
+  return items, errors
+end
+
 -- Helper function to create centered dropdown config
 function M.centered_dropdown(width, height)
   return {
@@ -85,8 +109,9 @@ function M.centered_dropdown(width, height)
   }
 end
 
--- Helper to run a command and capture output
-function M.run_command(cmd_args, callback)
+-- Helper to run a command and capture output.
+-- When stdin is given it is written to the process and the pipe is closed.
+function M.run_command(cmd_args, callback, stdin)
   local stdout_data = {}
   local stderr_data = {}
 
@@ -112,6 +137,12 @@ function M.run_command(cmd_args, callback)
 
   if job_id <= 0 then
     callback("", "Failed to start command", -1)
+    return job_id
+  end
+
+  if stdin then
+    vim.fn.chansend(job_id, stdin)
+    vim.fn.chanclose(job_id, "stdin")
   end
 
   return job_id
@@ -34,38 +34,24 @@ local function update(bufnr)
 		return
 	end
 
-	local cx_path = vim.fn.exepath("cx")
-	if cx_path == "" then
+	local grove_nvim = utils.get_grove_nvim_binary()
+	if not grove_nvim then
 		return
 	end
 
-	-- Get buffer contents and write to a temporary file
-	-- This ensures we analyze the current buffer state, not what's on disk
+	-- Pipe the buffer contents over stdin so we analyze the current buffer
+	-- state, not what's on disk. --rules-file only anchors relative patterns.
 	local lines = api.nvim_buf_get_lines(bufnr, 0, -1, false)
-	local temp_file = vim.fn.tempname()
-	local f = io.open(temp_file, "w")
-	if not f then
-		vim.notify("Grove: Failed to create temp file", vim.log.levels.ERROR)
-		return
-	end
-	f:write(table.concat(lines, "\n"))
-	f:close()
-
-	-- Use shell to redirect stderr since cx logs to stderr
-	local cmd =
-		string.format("%s stats --per-line %s 2>/dev/null", vim.fn.shellescape(cx_path), vim.fn.shellescape(temp_file))
-
-	utils.run_command({ "sh", "-c", cmd }, function(stdout, stderr, exit_code)
-		-- Clean up temp file
-		vim.fn.delete(temp_file)
+	local cmd = { grove_nvim, "rules", "stats", "--per-line", "--rules-file", buf_path }
 
+	utils.run_command(cmd, function(stdout, stderr, exit_code)
 		-- Check if buffer is still valid before processing results
 		if not api.nvim_buf_is_valid(bufnr) then
 			return
 		end
 
 		if exit_code ~= 0 then
-			vim.notify("Grove: cx stats failed with exit code " .. exit_code, vim.log.levels.DEBUG)
+			vim.notify("Grove: rules stats failed with exit code " .. exit_code, vim.log.levels.DEBUG)
 			if api.nvim_buf_is_valid(bufnr) then
 				api.nvim_buf_clear_namespace(bufnr, ns_id, 0, -1)
 			end
@@ -73,7 +59,7 @@ local function update(bufnr)
 		end
 
 		if stdout == "" then
-			vim.notify("Grove: cx stats returned empty output", vim.log.levels.DEBUG)
+			vim.notify("Grove: rules stats returned empty output", vim.log.levels.DEBUG)
 			if api.nvim_buf_is_valid(bufnr) then
 				api.nvim_buf_clear_namespace(bufnr, ns_id, 0, -1)
 			end
@@ -91,7 +77,7 @@ local function update(bufnr)
 
 		-- Handle vim.NIL (JSON null) and empty results
 		if not stats or stats == vim.NIL then
-			vim.notify("Grove: cx stats returned null (no stats available)", vim.log.levels.DEBUG)
+			vim.notify("Grove: rules stats returned null (no stats available)", vim.log.levels.DEBUG)
 			if api.nvim_buf_is_valid(bufnr) then
 				api.nvim_buf_clear_namespace(bufnr, ns_id, 0, -1)
 			end
@@ -154,8 +140,12 @@ local function update(bufnr)
 					local virt_text = {}
 
 					if stat then
-						-- First check if this has Git info - if so, show it
-						if stat.gitInfo then
+						-- Rules grove-nvim does not evaluate (git repositories, @cmd:,
+						-- ruleset imports) have no counts; mark them as unknown.
+						if stat.unsupported then
+							table.insert(virt_text, { " ? " .. (stat.skipReason or "not counted"), "GroveVirtualTextSkipped" })
+						-- Check if this has Git info - if so, show it
+						elseif stat.gitInfo then
 							-- Check for git resolution errors first
 							if stat.skipReason and stat.skipReason:match("^invalid git ref:") then
 								-- Extract the version that couldn't be resolved
@@ -387,7 +377,7 @@ local function update(bufnr)
 				end
 			end
 		end)
-	end)
+	end, table.concat(lines, "\n"))
 end
 
 -- Copies the buffer content with virtual text aligned to the right
@@ -19,7 +19,83 @@ vim.api.nvim_create_user_command("GroveChatRun", function(args)
 	require("grove-nvim").chat_run(args)
 end, {
 	nargs = "*",
-	desc = "Run Grove chat on the current note. Args: [silent] [vertical|horizontal|fullscreen]",
+	desc = "Run Grove chat on the current note. Args: [silent] [force] [novalidate] [vertical|horizontal|fullscreen]",
+})
+
+vim.api.nvim_create_user_command("GroveChatFork", function(args)
+	require("grove-nvim").chat_fork(args)
+end, {
+	nargs = "*",
+	desc = "Fork the current chat into a new job, truncated after a turn. Args: [turn] [name]",
+})
+
+vim.api.nvim_create_user_command("GroveChatRegenerate", function(args)
+	require("grove-nvim").chat_regenerate(args)
+end, {
+	nargs = "?",
+	desc = "Replace the last LLM response and resubmit the chat. Args: [model]",
+})
+
+vim.api.nvim_create_user_command("GroveChatExport", function(args)
+	require("grove-nvim").chat_export(args)
+end, {
+	nargs = "*",
+	complete = function(_, cmd_line)
+		if #vim.split(cmd_line, "%s+") <= 2 then
+			return { "html", "json", "md" }
+		end
+		return vim.fn.getcompletion("", "file")
+	end,
+	desc = "Export the current chat as a transcript. Args: [html|json|md] [path]",
+})
+
+vim.api.nvim_create_user_command("GroveChatCompact", function(args)
+	require("grove-nvim").chat_compact(args)
+end, {
+	nargs = "*",
+	desc = "Replace older chat turns with a summary. Args: [keep-last] [dry]",
+})
+
+vim.api.nvim_create_user_command("GroveChatExtract", function(args)
+	require("grove-nvim").chat_extract(args)
+end, {
+	nargs = "?",
+	desc = "Preview the code blocks of a response as a patch and write them. Args: [turn]",
+})
+
+vim.api.nvim_create_user_command("GroveSearch", function(args)
+	local opts = {}
+	local words = {}
+	for word in string.gmatch(args.args, "%S+") do
+		local role = word:match("^role:(%a+)$")
+		if role then
+			opts.role = role
+		else
+			table.insert(words, word)
+		end
+	end
+	if args.bang then
+		opts.workspace = "."
+	end
+	require("grove-nvim.search").search(table.concat(words, " "), opts)
+end, {
+	nargs = "+",
+	bang = true,
+	desc = "Search chats and notes across plans and notebooks. Args: <query> [role:user|role:llm]; ! for the current workspace only",
+})
+
+vim.api.nvim_create_user_command("GroveJobValidate", function()
+	if vim.bo.modified then
+		vim.cmd("silent write")
+	end
+	require("grove-nvim").job_validate(nil, function(errors)
+		if errors == 0 then
+			vim.notify("Grove: No errors in the job frontmatter.", vim.log.levels.INFO)
+		end
+	end)
+end, {
+	nargs = 0,
+	desc = "Check the job frontmatter of the current buffer and show diagnostics.",
 })
 
 vim.api.nvim_create_user_command("GroveToggleChatUI", function()
@@ -114,6 +190,28 @@ end, {
 	desc = "Open Notebook Browser TUI in a side panel (nb tui).",
 })
 
+vim.api.nvim_create_user_command("GroveNBNew", function(opts)
+	require("grove-nvim.nb").new({ title = opts.args, range = opts.range, line1 = opts.line1, line2 = opts.line2 })
+end, {
+	nargs = "?",
+	range = true,
+	desc = "Create a notebook note, from the selected lines with a range. Args: [title]",
+})
+
+vim.api.nvim_create_user_command("GroveNBFind", function(opts)
+	require("grove-nvim.nb").find(opts.args)
+end, {
+	nargs = "+",
+	desc = "Find notebook notes and open one. Args: <query>",
+})
+
+vim.api.nvim_create_user_command("GroveNBLink", function(opts)
+	require("grove-nvim.nb").link(opts.args)
+end, {
+	nargs = "+",
+	desc = "Find a notebook note and insert a reference to it at the cursor. Args: <query>",
+})
+
 vim.api.nvim_create_user_command("GroveHooksSessions", function()
 	require("grove-nvim.grove").open_hooks_sessions_browse()
 end, {
@@ -152,6 +250,14 @@ end, {
 	desc = "Copy rules file content with virtual text to clipboard",
 })
 
+vim.api.nvim_create_user_command("GroveRulesExplain", function(opts)
+	require("grove-nvim.rules").explain(opts.args)
+end, {
+	nargs = "?",
+	complete = "file",
+	desc = "Explain which rules include or exclude a file (default: current file)",
+})
+
 vim.api.nvim_create_user_command("GroveRules", function()
 	require("grove-nvim.cx").rules()
 end, {
@@ -222,6 +328,9 @@ vim.keymap.set("v", "<leader>fr", "<cmd>GroveTextRun<CR>", { desc = "Grove Ask &
 
 -- Tend
 vim.keymap.set("n", "<leader>ftr", "<cmd>GroveRunTest<CR>", { desc = "Grove: Run Tend test" })
+vim.keymap.set("n", "<leader>fts", "<cmd>GroveTendScenarios<CR>", { desc = "Grove: Pick Tend scenario" })
+vim.keymap.set("n", "<leader>ftl", "<cmd>GroveTendRerun last<CR>", { desc = "Grove: Re-run last Tend test" })
+vim.keymap.set("n", "<leader>ftf", "<cmd>GroveTendRerun failed<CR>", { desc = "Grove: Re-run failed Tend tests" })
 
 -- Marks Commands
 vim.api.nvim_create_user_command("GroveMarkFile", function()
@@ -266,6 +375,40 @@ end, {
 	desc = "Run tend test for scenario under cursor.",
 })
 
+vim.api.nvim_create_user_command("GroveTendRun", function(opts)
+	local tend = require("grove-nvim.tend")
+	if opts.args ~= "" then
+		tend.run_structured(opts.args)
+	else
+		tend.with_scenario_under_cursor(tend.run_structured)
+	end
+end, {
+	nargs = "?",
+	desc = "Run a tend scenario in the background; failures go to the quickfix list.",
+})
+
+vim.api.nvim_create_user_command("GroveTendRerun", function(opts)
+	local mode = opts.args ~= "" and opts.args or "last"
+	if mode ~= "last" and mode ~= "failed" then
+		vim.notify("Grove: GroveTendRerun takes 'last' or 'failed'.", vim.log.levels.ERROR)
+		return
+	end
+	require("grove-nvim.tend").rerun(mode)
+end, {
+	nargs = "?",
+	complete = function()
+		return { "last", "failed" }
+	end,
+	desc = "Re-run the last tend scenario, or every scenario whose last run failed.",
+})
+
+vim.api.nvim_create_user_command("GroveTendScenarios", function(opts)
+	require("grove-nvim.tend").pick_scenario({ tags = opts.fargs })
+end, {
+	nargs = "*",
+	desc = "Pick a tend scenario to run, optionally filtered to the given tags.",
+})
+
 -- Keybindings for Marks
 vim.keymap.set("n", "<leader>ja", "<cmd>GroveMarkFile<CR>", { desc = "Grove Mark: Add file" })
 vim.keymap.set("n", "<leader>js", "<cmd>GroveMarksMenu<CR>", { desc = "Grove Mark: Show menu" })